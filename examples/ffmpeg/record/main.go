@@ -1,7 +1,31 @@
 package main
 
-import "github.com/m4n5ter/lindows/pkg/ffmpeg"
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/m4n5ter/lindows/pkg/ffmpeg"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
 
 func main() {
-	ffmpeg.RecordScreen("5", "output.webm")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := ffmpeg.Run(ctx, ffmpeg.Options{
+		InputFormat:  "gdigrab",
+		Input:        "desktop",
+		FrameRate:    30,
+		Duration:     5 * time.Second,
+		OutputFormat: "webm",
+		Output:       "output.webm",
+		OnProgress: func(p ffmpeg.Progress) {
+			yalog.Info("recording", "frame", p.Frame, "fps", p.FPS, "bitrate_kbps", p.BitrateKbps, "drop_frames", p.DropFrames, "speed", p.Speed)
+		},
+	})
+	if err != nil {
+		yalog.Fatal("failed to record screen", "error", err)
+	}
 }
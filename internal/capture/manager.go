@@ -9,6 +9,7 @@ import (
 type Manager struct {
 	logger  *yalog.Logger
 	desktop desktop.Manager
+	config  *config.Capture
 	audio   *StreamManager
 	video   *StreamManager
 }
@@ -17,19 +18,30 @@ func New(desktop desktop.Manager, cfg *config.Capture) *Manager {
 	return &Manager{
 		logger:  yalog.Default().With("module", "capture"),
 		desktop: desktop,
-		audio:   newStreamManager(cfg.AudioCodec, "audio"),
-		video:   newStreamManager(cfg.VideoCodec, "video"),
+		config:  cfg,
+		audio:   newStreamManager(cfg.AudioCodec, "audio", nil, cfg.Linger),
+		video:   newStreamManager(cfg.VideoCodec, "video", videoPipeline(cfg, desktop.Cursor() == nil, desktop.Region()), cfg.Linger),
 	}
 }
 
+// Start 在配置了预热时立即启动视频管道，否则等到第一个订阅者
 func (manager *Manager) Start() {
-	manager.logger.Info("Capture manager started")
+	if manager.config.WarmStart {
+		manager.video.Start()
+	}
+
+	manager.logger.Info("Capture manager started", "warm_start", manager.config.WarmStart, "linger", manager.config.Linger)
+}
+
+func (manager *Manager) Shutdown() {
+	manager.video.Stop()
+	manager.audio.Stop()
 }
 
-func (manager *Manager) Audio() StreamManager {
-	return *manager.audio
+func (manager *Manager) Audio() *StreamManager {
+	return manager.audio
 }
 
-func (manager *Manager) Video() StreamManager {
-	return *manager.video
+func (manager *Manager) Video() *StreamManager {
+	return manager.video
 }
@@ -0,0 +1,121 @@
+package capture
+
+import (
+	"fmt"
+	"image"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/pkg/ffmpeg"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+// pipeline 根据本地 RTP 地址生成 ffmpeg 参数
+type pipeline func(rtpURL string) ffmpeg.Options
+
+// videoPipeline 用 gdigrab 捕获桌面，并按配置的编解码器编码为 RTP
+//
+// drawCursor 为 false 时视频中不包含光标，光标由客户端根据光标通道在本地绘制。
+// region 不为空时只捕获该区域，坐标与 gdigrab 相同，以虚拟桌面的物理像素表示。
+func videoPipeline(cfg *config.Capture, drawCursor bool, region image.Rectangle) pipeline {
+	fps := int(cfg.VideoMaxFPS)
+	if fps <= 0 {
+		fps = 30
+	}
+	gop := strconv.Itoa(fps * 2)
+	bitrate := fmt.Sprintf("%dk", cfg.VideoBitrate)
+
+	keyframes := keyframeExpr(cfg.KeyframeInterval)
+
+	var filter []string
+	if cfg.StaticDetection {
+		if f := staticFilter(fps, cfg.StaticFPS); f != "" {
+			filter = []string{"-vf", f, "-fps_mode", "vfr"}
+		}
+	}
+
+	var encoder []string
+	switch cfg.VideoCodec.Name {
+	case "vp9":
+		encoder = []string{"-c:v", "libvpx-vp9", "-deadline", "realtime", "-cpu-used", "8", "-row-mt", "1"}
+	case "h264":
+		if cfg.VideoHwEnc == config.HwEncNVENC {
+			encoder = []string{"-c:v", "h264_nvenc", "-preset", "p1", "-tune", "ll", "-zerolatency", "1"}
+		} else {
+			if cfg.VideoHwEnc == config.HwEncVAAPI {
+				yalog.Warn("VAAPI is not available on Windows, using CPU encoder")
+			}
+			encoder = []string{"-c:v", "libx264", "-preset", "ultrafast", "-tune", "zerolatency", "-profile:v", "baseline"}
+		}
+		encoder = append(encoder, "-pix_fmt", "yuv420p", "-bf", "0")
+	case "av1":
+		encoder = []string{"-c:v", "libaom-av1", "-usage", "realtime", "-cpu-used", "8"}
+	default:
+		encoder = []string{"-c:v", "libvpx", "-deadline", "realtime", "-cpu-used", "8", "-error-resilient", "1", "-auto-alt-ref", "0"}
+	}
+
+	drawMouse := "0"
+	if drawCursor {
+		drawMouse = "1"
+	}
+
+	payloadType := strconv.Itoa(int(cfg.VideoCodec.PayloadType))
+	ssrc := strconv.FormatUint(uint64(rand.Uint32()), 10)
+
+	inputArgs := []string{"-draw_mouse", drawMouse}
+	if !region.Empty() {
+		inputArgs = append(inputArgs,
+			"-offset_x", strconv.Itoa(region.Min.X),
+			"-offset_y", strconv.Itoa(region.Min.Y),
+			"-video_size", fmt.Sprintf("%dx%d", region.Dx(), region.Dy()),
+		)
+	}
+
+	return func(rtpURL string) ffmpeg.Options {
+		outputArgs := append([]string{}, filter...)
+		outputArgs = append(outputArgs, encoder...)
+		outputArgs = append(outputArgs,
+			"-g", gop,
+			"-force_key_frames", keyframes,
+			"-b:v", bitrate,
+			"-payload_type", payloadType,
+			"-ssrc", ssrc,
+		)
+
+		return ffmpeg.Options{
+			InputFormat:  "gdigrab",
+			Input:        cfg.Display,
+			FrameRate:    fps,
+			InputArgs:    inputArgs,
+			OutputArgs:   outputArgs,
+			OutputFormat: "rtp",
+			Output:       rtpURL + "?pkt_size=1200",
+		}
+	}
+}
+
+// staticFilter 返回丢弃静止帧的 mpdecimate 滤镜。
+//
+// mpdecimate 按 8x8 块比较相邻帧，没有块发生明显变化的帧会被丢弃，画面一变化就立即恢复到原帧率；
+// max 限制连续丢弃的帧数，所以画面静止时仍然以 staticFPS 输出。
+// staticFPS 高于 fps 的一半时连一帧都不能丢，而 max=0 表示不限制连续丢帧，所以同样返回空字符串。
+func staticFilter(fps, staticFPS int) string {
+	if staticFPS <= 0 || staticFPS >= fps {
+		return ""
+	}
+
+	drop := fps/staticFPS - 1
+	if drop < 1 {
+		return ""
+	}
+
+	return fmt.Sprintf("mpdecimate=max=%d", drop)
+}
+
+// keyframeExpr 返回 -force_key_frames 的表达式，按时间强制关键帧，
+// 画面静止、帧率很低时客户端也能定期拿到完整的画面
+func keyframeExpr(interval time.Duration) string {
+	return fmt.Sprintf("expr:gte(t,n_forced*%s)", strconv.FormatFloat(interval.Seconds(), 'f', -1, 64))
+}
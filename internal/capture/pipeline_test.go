@@ -0,0 +1,121 @@
+package capture
+
+import (
+	"image"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/types/codec"
+)
+
+func TestStaticFilter(t *testing.T) {
+	tests := []struct {
+		fps, staticFPS int
+		want           string
+	}{
+		{fps: 30, staticFPS: 1, want: "mpdecimate=max=29"},
+		{fps: 30, staticFPS: 5, want: "mpdecimate=max=5"},
+		{fps: 30, staticFPS: 7, want: "mpdecimate=max=3"},
+		{fps: 30, staticFPS: 15, want: "mpdecimate=max=1"},
+		{fps: 60, staticFPS: 1, want: "mpdecimate=max=59"},
+
+		// 不需要或无法丢帧
+		{fps: 30, staticFPS: 0, want: ""},
+		{fps: 30, staticFPS: -1, want: ""},
+		{fps: 30, staticFPS: 30, want: ""},
+		{fps: 30, staticFPS: 60, want: ""},
+		{fps: 1, staticFPS: 1, want: ""},
+
+		// 高于 fps 的一半时 max 会是 0，而 0 表示不限制连续丢帧
+		{fps: 30, staticFPS: 16, want: ""},
+		{fps: 30, staticFPS: 29, want: ""},
+	}
+
+	for _, test := range tests {
+		if got := staticFilter(test.fps, test.staticFPS); got != test.want {
+			t.Errorf("staticFilter(%d, %d) = %q, want %q", test.fps, test.staticFPS, got, test.want)
+		}
+	}
+}
+
+func TestKeyframeExpr(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		want     string
+	}{
+		{interval: 2 * time.Second, want: "expr:gte(t,n_forced*2)"},
+		{interval: 1500 * time.Millisecond, want: "expr:gte(t,n_forced*1.5)"},
+		{interval: 250 * time.Millisecond, want: "expr:gte(t,n_forced*0.25)"},
+		{interval: time.Minute, want: "expr:gte(t,n_forced*60)"},
+	}
+
+	for _, test := range tests {
+		if got := keyframeExpr(test.interval); got != test.want {
+			t.Errorf("keyframeExpr(%s) = %q, want %q", test.interval, got, test.want)
+		}
+	}
+}
+
+func TestVideoPipelineArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.Capture
+		want   []string
+		absent []string
+	}{
+		{
+			name: "static detection",
+			config: config.Capture{
+				VideoCodec: codec.VP8(), VideoMaxFPS: 30,
+				StaticDetection: true, StaticFPS: 1, KeyframeInterval: 2 * time.Second,
+			},
+			want: []string{"-vf mpdecimate=max=29 -fps_mode vfr -c:v libvpx", "-g 60 -force_key_frames expr:gte(t,n_forced*2)"},
+		},
+		{
+			name: "default fps",
+			config: config.Capture{
+				VideoCodec:      codec.H264(),
+				StaticDetection: true, StaticFPS: 2, KeyframeInterval: 1500 * time.Millisecond,
+			},
+			want: []string{"-vf mpdecimate=max=14 -fps_mode vfr -c:v libx264", "-g 60 -force_key_frames expr:gte(t,n_forced*1.5)"},
+		},
+		{
+			name: "static detection disabled",
+			config: config.Capture{
+				VideoCodec: codec.VP8(), VideoMaxFPS: 30,
+				StaticFPS: 1, KeyframeInterval: 2 * time.Second,
+			},
+			want:   []string{"-force_key_frames expr:gte(t,n_forced*2)"},
+			absent: []string{"-vf", "-fps_mode"},
+		},
+		{
+			name: "static fps not below fps",
+			config: config.Capture{
+				VideoCodec: codec.VP8(), VideoMaxFPS: 10,
+				StaticDetection: true, StaticFPS: 10, KeyframeInterval: 2 * time.Second,
+			},
+			want:   []string{"-g 20 -force_key_frames expr:gte(t,n_forced*2)"},
+			absent: []string{"-vf", "-fps_mode"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := videoPipeline(&test.config, false, image.Rectangle{})("rtp://127.0.0.1:5004")
+			args := strings.Join(options.OutputArgs, " ")
+
+			for _, want := range test.want {
+				if !strings.Contains(args, want) {
+					t.Errorf("output args %q do not contain %q", args, want)
+				}
+			}
+			for _, absent := range test.absent {
+				if strings.Contains(args, absent) {
+					t.Errorf("output args %q contain %q", args, absent)
+				}
+			}
+		})
+	}
+}
@@ -1,34 +1,252 @@
 package capture
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
 	"github.com/m4n5ter/lindows/internal/types/codec"
+	"github.com/m4n5ter/lindows/pkg/ffmpeg"
 	"github.com/m4n5ter/lindows/pkg/yalog"
 	"github.com/pion/rtp"
 )
 
+const (
+	subscriberBufferSize = 256
+	readBufferSize       = 300 << 10 // 300 KB
+	restartDelay         = time.Second
+)
+
+// StreamManager 把 ffmpeg 管道的 RTP 包分发给订阅者。
+//
+// 管道按订阅者引用计数：第一个订阅者到来时启动，最后一个订阅者离开 linger 之后停止；
+// 调用 Start 预热后管道会一直运行，直到 Stop。
 type StreamManager struct {
-	logger     *yalog.Logger
-	codec      codec.RTPCodec
-	rtpChannel chan rtp.Packet
+	logger   *yalog.Logger
+	codec    codec.RTPCodec
+	pipeline pipeline
+	linger   time.Duration
+
+	// runPipeline 运行一次管道直到其退出或 ctx 被取消，测试中会被替换
+	runPipeline func(ctx context.Context) error
+
+	mu          sync.Mutex
+	subscribers map[chan rtp.Packet]struct{}
+	warm        bool
+	lingerTimer *time.Timer
+	cancel      context.CancelFunc
+	done        chan struct{}
 }
 
-func newStreamManager(codec codec.RTPCodec, audioVideoID string) *StreamManager {
+func newStreamManager(codec codec.RTPCodec, audioVideoID string, pipeline pipeline, linger time.Duration) *StreamManager {
 	logger := yalog.Default().With(
 		"module", "capture",
 		"submodule", "stream",
 		"audio_video_id", audioVideoID,
 	)
 
-	return &StreamManager{
-		logger: logger,
-		codec:  codec,
+	manager := &StreamManager{
+		logger:      logger,
+		codec:       codec,
+		pipeline:    pipeline,
+		linger:      linger,
+		subscribers: make(map[chan rtp.Packet]struct{}),
 	}
+	manager.runPipeline = manager.runOnce
+
+	return manager
 }
 
-func (manager StreamManager) Codec() codec.RTPCodec {
+func (manager *StreamManager) Codec() codec.RTPCodec {
 	return manager.codec
 }
 
-func (manager StreamManager) GetRTPChannel() chan rtp.Packet {
-	return manager.rtpChannel
+// Subscribe 订阅 RTP 包，返回的函数用于取消订阅。
+//
+// 订阅者处理太慢时新的包会被丢弃，而不是阻塞其它订阅者。
+// 管道没有运行时会立即启动，新订阅者会在下一个关键帧之后才能解码画面。
+func (manager *StreamManager) Subscribe() (<-chan rtp.Packet, func()) {
+	ch := make(chan rtp.Packet, subscriberBufferSize)
+
+	manager.mu.Lock()
+	manager.subscribers[ch] = struct{}{}
+	if manager.lingerTimer != nil {
+		manager.lingerTimer.Stop()
+		manager.lingerTimer = nil
+	}
+	manager.startLocked()
+	manager.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			manager.mu.Lock()
+			delete(manager.subscribers, ch)
+			if len(manager.subscribers) == 0 && !manager.warm && manager.cancel != nil {
+				manager.logger.Debug("No subscribers left, stopping pipeline after linger", "linger", manager.linger)
+				manager.lingerTimer = time.AfterFunc(manager.linger, manager.stopIfIdle)
+			}
+			manager.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Subscribers 返回当前的订阅者数量
+func (manager *StreamManager) Subscribers() int {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	return len(manager.subscribers)
+}
+
+// Running 判断管道是否正在运行
+func (manager *StreamManager) Running() bool {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	return manager.cancel != nil
+}
+
+func (manager *StreamManager) broadcast(packet rtp.Packet) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	for ch := range manager.subscribers {
+		select {
+		case ch <- packet:
+		default:
+		}
+	}
+}
+
+// Start 预热 ffmpeg 管道，没有订阅者时也保持运行
+func (manager *StreamManager) Start() {
+	if manager.pipeline == nil {
+		manager.logger.Warn("No capture pipeline for this stream")
+		return
+	}
+
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	manager.warm = true
+	manager.startLocked()
+}
+
+// startLocked 启动管道，管道意外退出时会自动重启，调用时必须持有 mu
+func (manager *StreamManager) startLocked() {
+	if manager.pipeline == nil || manager.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	manager.cancel = cancel
+	manager.done = make(chan struct{})
+
+	go manager.run(ctx, manager.done)
+	manager.logger.Info("Capture pipeline started")
+}
+
+// Stop 停止 ffmpeg 管道并等待其退出，不管是否还有订阅者
+func (manager *StreamManager) Stop() {
+	manager.mu.Lock()
+	manager.warm = false
+	if manager.lingerTimer != nil {
+		manager.lingerTimer.Stop()
+		manager.lingerTimer = nil
+	}
+	manager.mu.Unlock()
+
+	manager.stop(func() bool { return true })
+}
+
+// stopIfIdle 在 linger 结束后停止管道，期间有新的订阅者或者被预热时不停止
+func (manager *StreamManager) stopIfIdle() {
+	manager.stop(func() bool {
+		return len(manager.subscribers) == 0 && !manager.warm
+	})
+}
+
+// stop 在 mu 中检查 cond，满足时停止管道并等待其退出
+func (manager *StreamManager) stop(cond func() bool) {
+	manager.mu.Lock()
+	if !cond() || manager.cancel == nil {
+		manager.mu.Unlock()
+		return
+	}
+
+	cancel, done := manager.cancel, manager.done
+	manager.cancel, manager.done = nil, nil
+	manager.lingerTimer = nil
+	manager.mu.Unlock()
+
+	cancel()
+	<-done
+	manager.logger.Info("Capture pipeline stopped")
+}
+
+func (manager *StreamManager) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for {
+		if err := manager.runPipeline(ctx); err != nil {
+			manager.logger.Error("Capture pipeline failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(restartDelay):
+			manager.logger.Info("Restarting capture pipeline")
+		}
+	}
+}
+
+func (manager *StreamManager) runOnce(ctx context.Context) error {
+	// ffmpeg 把 RTP 包发送到这个本地 UDP 端口
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return fmt.Errorf("failed to listen udp: %v", err)
+	}
+	defer listener.Close()
+
+	if err := listener.SetReadBuffer(readBufferSize); err != nil {
+		manager.logger.Warn("Failed to set read buffer", "error", err)
+	}
+
+	rtpURL := fmt.Sprintf("rtp://%s", listener.LocalAddr())
+	options := manager.pipeline(rtpURL)
+	options.Logger = manager.logger.With("module", "ffmpeg")
+
+	job := ffmpeg.NewJob(options)
+	if err := job.Start(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		<-job.Done()
+		listener.Close()
+	}()
+
+	buf := make([]byte, 1600) // UDP MTU
+	for {
+		n, _, err := listener.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+
+		// rtp.Packet 的 Payload 引用了原始数据，所以每个包都需要独立的内存
+		packet := rtp.Packet{}
+		if err := packet.Unmarshal(append([]byte(nil), buf[:n]...)); err != nil {
+			manager.logger.Debug("Failed to unmarshal rtp packet", "error", err)
+			continue
+		}
+
+		manager.broadcast(packet)
+	}
+
+	return job.Wait()
 }
@@ -0,0 +1,133 @@
+package capture
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/types/codec"
+	"github.com/m4n5ter/lindows/pkg/ffmpeg"
+)
+
+// fakeRunner 代替 ffmpeg 运行管道，记录启动和停止的次数
+type fakeRunner struct {
+	starts atomic.Int32
+	stops  atomic.Int32
+}
+
+func (runner *fakeRunner) run(ctx context.Context) error {
+	runner.starts.Add(1)
+	<-ctx.Done()
+	runner.stops.Add(1)
+	return nil
+}
+
+func newTestStream(linger time.Duration) (*StreamManager, *fakeRunner) {
+	runner := &fakeRunner{}
+
+	manager := newStreamManager(codec.VP8(), "test", func(string) ffmpeg.Options { return ffmpeg.Options{} }, linger)
+	manager.runPipeline = runner.run
+
+	return manager, runner
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// assertRunning 检查管道的运行状态以及启动、停止的次数
+func assertRunning(t *testing.T, manager *StreamManager, runner *fakeRunner, running bool, starts, stops int32) {
+	t.Helper()
+
+	if got := manager.Running(); got != running {
+		t.Errorf("running = %v, want %v", got, running)
+	}
+	if got := runner.starts.Load(); got != starts {
+		t.Errorf("starts = %d, want %d", got, starts)
+	}
+	if got := runner.stops.Load(); got != stops {
+		t.Errorf("stops = %d, want %d", got, stops)
+	}
+}
+
+func TestStreamSubscribe(t *testing.T) {
+	const linger = 50 * time.Millisecond
+	manager, runner := newTestStream(linger)
+
+	if manager.Running() {
+		t.Fatal("pipeline is running before the first subscriber")
+	}
+
+	_, unsubscribe1 := manager.Subscribe()
+	waitFor(t, "pipeline start", func() bool { return runner.starts.Load() == 1 })
+
+	_, unsubscribe2 := manager.Subscribe()
+	if got := manager.Subscribers(); got != 2 {
+		t.Errorf("subscribers = %d, want 2", got)
+	}
+
+	// 还剩一个订阅者时不会停止
+	unsubscribe1()
+	time.Sleep(3 * linger)
+	assertRunning(t, manager, runner, true, 1, 0)
+
+	// 最后一个订阅者离开后，linger 期间仍然运行
+	unsubscribe2()
+	unsubscribe2()
+	assertRunning(t, manager, runner, true, 1, 0)
+
+	waitFor(t, "pipeline stop", func() bool { return !manager.Running() && runner.stops.Load() == 1 })
+	assertRunning(t, manager, runner, false, 1, 1)
+}
+
+func TestStreamResubscribeDuringLinger(t *testing.T) {
+	const linger = 100 * time.Millisecond
+	manager, runner := newTestStream(linger)
+
+	_, unsubscribe := manager.Subscribe()
+	waitFor(t, "pipeline start", func() bool { return runner.starts.Load() == 1 })
+	unsubscribe()
+
+	// linger 期间的新订阅者取消停止，继续使用同一个管道
+	_, unsubscribe = manager.Subscribe()
+	time.Sleep(3 * linger)
+	assertRunning(t, manager, runner, true, 1, 0)
+
+	unsubscribe()
+	waitFor(t, "pipeline stop", func() bool { return runner.stops.Load() == 1 })
+	assertRunning(t, manager, runner, false, 1, 1)
+}
+
+func TestStreamWarmStart(t *testing.T) {
+	const linger = 10 * time.Millisecond
+	manager, runner := newTestStream(linger)
+
+	manager.Start()
+	waitFor(t, "pipeline start", func() bool { return runner.starts.Load() == 1 })
+
+	// 预热的管道在订阅者都离开后也不会停止
+	_, unsubscribe := manager.Subscribe()
+	unsubscribe()
+	time.Sleep(10 * linger)
+	assertRunning(t, manager, runner, true, 1, 0)
+
+	// Stop 等待管道退出
+	manager.Stop()
+	assertRunning(t, manager, runner, false, 1, 1)
+
+	// Stop 之后恢复按订阅者引用计数
+	_, unsubscribe = manager.Subscribe()
+	waitFor(t, "pipeline restart", func() bool { return runner.starts.Load() == 2 })
+	unsubscribe()
+	waitFor(t, "pipeline stop", func() bool { return runner.stops.Load() == 2 })
+	assertRunning(t, manager, runner, false, 2, 2)
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"strings"
+	"time"
 
 	"github.com/m4n5ter/lindows/internal/types/codec"
 	"github.com/m4n5ter/lindows/pkg/yalog"
@@ -26,6 +27,15 @@ type Capture struct {
 	VideoBitrate uint
 	VideoMaxFPS  int16
 
+	// 静止画面检测
+	StaticDetection  bool
+	StaticFPS        int
+	KeyframeInterval time.Duration
+
+	// 采集生命周期
+	Linger    time.Duration
+	WarmStart bool
+
 	// Audio
 	AudioDevice  string
 	AudioCodec   codec.RTPCodec
@@ -59,6 +69,31 @@ func (Capture) Init(cmd *cobra.Command) error {
 		return err
 	}
 
+	cmd.PersistentFlags().Bool("static_detection", true, "检测静止画面, 画面不变时降低编码帧率, 变化时立即恢复")
+	if err := viper.BindPFlag("static_detection", cmd.PersistentFlags().Lookup("static_detection")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Int("static_fps", 1, "画面静止时的最低帧率")
+	if err := viper.BindPFlag("static_fps", cmd.PersistentFlags().Lookup("static_fps")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Duration("keyframe_interval", 2*time.Second, "强制关键帧的间隔, 画面静止时也会按此间隔刷新")
+	if err := viper.BindPFlag("keyframe_interval", cmd.PersistentFlags().Lookup("keyframe_interval")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Duration("capture_linger", 30*time.Second, "最后一个观众离开后继续采集的时间, 在此期间重新连接无需等待编码器启动")
+	if err := viper.BindPFlag("capture_linger", cmd.PersistentFlags().Lookup("capture_linger")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Bool("capture_warm_start", false, "启动时立即开始采集并一直保持, 而不是在第一个观众连接时才启动")
+	if err := viper.BindPFlag("capture_warm_start", cmd.PersistentFlags().Lookup("capture_warm_start")); err != nil {
+		return err
+	}
+
 	cmd.PersistentFlags().String("video", "", "用于流的视频编解码器参数")
 	if err := viper.BindPFlag("video", cmd.PersistentFlags().Lookup("video")); err != nil {
 		return err
@@ -114,6 +149,22 @@ func (s *Capture) Set() {
 	s.VideoBitrate = uint(viper.GetInt("video_bitrate"))
 	s.VideoMaxFPS = int16(viper.GetInt("max_fps"))
 
+	s.StaticDetection = viper.GetBool("static_detection")
+	s.StaticFPS = viper.GetInt("static_fps")
+	if s.StaticFPS < 1 {
+		s.StaticFPS = 1
+	}
+	s.KeyframeInterval = viper.GetDuration("keyframe_interval")
+	if s.KeyframeInterval <= 0 {
+		s.KeyframeInterval = 2 * time.Second
+	}
+
+	s.Linger = viper.GetDuration("capture_linger")
+	if s.Linger < 0 {
+		s.Linger = 0
+	}
+	s.WarmStart = viper.GetBool("capture_warm_start")
+
 	// Audio
 	s.AudioDevice = viper.GetString("device")
 
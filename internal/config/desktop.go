@@ -1,10 +1,15 @@
 package config
 
 import (
+	"fmt"
+	"image"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/pkg/yalog"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -15,11 +20,79 @@ type Desktop struct {
 	ScreenWidth  int
 	ScreenHeight int
 	ScreenRate   int16
+
+	Cursor bool
+
+	// Region 是共享的桌面区域，以虚拟桌面的物理像素表示，为空时共享整个虚拟桌面
+	Region image.Rectangle
+
+	// Keyboard 是客户端没有指定时翻译按键的方式
+	Keyboard input.KeyboardMode
+
+	// InputRate 是每个会话每秒最多注入的鼠标移动次数，0 表示不限制
+	InputRate int
+
+	// Clipboard 表示与拥有 clipboard 权限的会话同步剪切板
+	Clipboard bool
+	// ClipboardMaxSize 是同步的剪切板文本的最大字节数
+	ClipboardMaxSize int
+	// ClipboardMaxImageSize 是同步的剪切板图像编码为 PNG 后的最大字节数
+	ClipboardMaxImageSize int
+	// ClipboardMaxRichSize 是同步的 HTML 和 RTF 各自的最大字节数，更大的富文本只同步纯文本
+	ClipboardMaxRichSize int
+
+	// MacroDir 是保存命名宏的目录，为空时只保存在内存中
+	MacroDir string
 }
 
 func (Desktop) Init(cmd *cobra.Command) error {
 	cmd.PersistentFlags().String("screen", "1280x720@30", "默认屏幕分辨率和刷新率")
-	err := viper.BindPFlag("screen", cmd.PersistentFlags().Lookup("screen"))
+	if err := viper.BindPFlag("screen", cmd.PersistentFlags().Lookup("screen")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Bool("cursor", true, "通过数据通道发送光标形状和位置, 由客户端绘制光标, 视频中不再包含光标")
+	if err := viper.BindPFlag("cursor", cmd.PersistentFlags().Lookup("cursor")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().String("region", "", "共享的桌面区域 x,y,width,height, 以物理像素为单位, 为空时共享整个虚拟桌面")
+	if err := viper.BindPFlag("region", cmd.PersistentFlags().Lookup("region")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().String("keyboard", "positional", "按键的翻译方式: positional 按物理位置注入扫描码, symbolic 按客户端键盘布局产生的字符输入")
+	if err := viper.BindPFlag("keyboard", cmd.PersistentFlags().Lookup("keyboard")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Int("input_rate", 240, "每个会话每秒最多注入的鼠标移动次数, 多余的移动合并为最新的位置, 0 表示不限制")
+	if err := viper.BindPFlag("input_rate", cmd.PersistentFlags().Lookup("input_rate")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Bool("clipboard", true, "与拥有 clipboard 权限的会话同步剪切板")
+	if err := viper.BindPFlag("clipboard", cmd.PersistentFlags().Lookup("clipboard")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Int("clipboard_max_size", 60*1024, "同步的剪切板文本的最大字节数, 更大的内容不会同步")
+	if err := viper.BindPFlag("clipboard_max_size", cmd.PersistentFlags().Lookup("clipboard_max_size")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Int("clipboard_max_image_size", 8*1024*1024, "同步的剪切板图像编码为 PNG 后的最大字节数, 图像分块传输")
+	if err := viper.BindPFlag("clipboard_max_image_size", cmd.PersistentFlags().Lookup("clipboard_max_image_size")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Int("clipboard_max_rich_size", 4*1024*1024, "同步的剪切板 HTML 和 RTF 各自的最大字节数, 更大的富文本只同步纯文本")
+	if err := viper.BindPFlag("clipboard_max_rich_size", cmd.PersistentFlags().Lookup("clipboard_max_rich_size")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().String("macro_dir", "", "保存命名宏的目录, 为空时宏只保存在内存中, 重启后丢失")
+	err := viper.BindPFlag("macro_dir", cmd.PersistentFlags().Lookup("macro_dir"))
 	return err
 }
 
@@ -27,6 +100,47 @@ func (s *Desktop) Set() {
 	// Display 从环境变量中获取
 	s.Display = os.Getenv("DISPLAY")
 
+	s.Cursor = viper.GetBool("cursor")
+
+	region, err := parseRegion(viper.GetString("region"))
+	if err != nil {
+		yalog.Error("无效的共享区域，将共享整个虚拟桌面", "region", viper.GetString("region"), "error", err)
+	}
+	s.Region = region
+
+	keyboard, err := input.ParseKeyboardMode(viper.GetString("keyboard"))
+	if err != nil {
+		yalog.Error("无效的按键翻译方式，将按物理位置注入", "keyboard", viper.GetString("keyboard"), "error", err)
+	}
+	s.Keyboard = keyboard
+
+	s.InputRate = viper.GetInt("input_rate")
+	if s.InputRate < 0 {
+		yalog.Error("无效的鼠标移动频率，将不限制频率", "input_rate", s.InputRate)
+		s.InputRate = 0
+	}
+
+	s.Clipboard = viper.GetBool("clipboard")
+	s.ClipboardMaxSize = viper.GetInt("clipboard_max_size")
+	if s.ClipboardMaxSize <= 0 {
+		yalog.Error("无效的剪切板大小限制，将使用默认值", "clipboard_max_size", s.ClipboardMaxSize)
+		s.ClipboardMaxSize = 60 * 1024
+	}
+
+	s.ClipboardMaxImageSize = viper.GetInt("clipboard_max_image_size")
+	if s.ClipboardMaxImageSize <= 0 {
+		yalog.Error("无效的剪切板图像大小限制，将使用默认值", "clipboard_max_image_size", s.ClipboardMaxImageSize)
+		s.ClipboardMaxImageSize = 8 * 1024 * 1024
+	}
+
+	s.ClipboardMaxRichSize = viper.GetInt("clipboard_max_rich_size")
+	if s.ClipboardMaxRichSize <= 0 {
+		yalog.Error("无效的剪切板富文本大小限制，将使用默认值", "clipboard_max_rich_size", s.ClipboardMaxRichSize)
+		s.ClipboardMaxRichSize = 4 * 1024 * 1024
+	}
+
+	s.MacroDir = viper.GetString("macro_dir")
+
 	s.ScreenWidth = 1280
 	s.ScreenHeight = 720
 	s.ScreenRate = 30
@@ -46,3 +160,30 @@ func (s *Desktop) Set() {
 		}
 	}
 }
+
+// parseRegion 解析 x,y,width,height 格式的区域，x 和 y 在多显示器时可以是负数
+func parseRegion(value string) (image.Rectangle, error) {
+	if value == "" {
+		return image.Rectangle{}, nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, fmt.Errorf("want x,y,width,height")
+	}
+
+	var numbers [4]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return image.Rectangle{}, err
+		}
+		numbers[i] = n
+	}
+
+	if numbers[2] <= 0 || numbers[3] <= 0 {
+		return image.Rectangle{}, fmt.Errorf("width and height must be positive")
+	}
+
+	return image.Rect(numbers[0], numbers[1], numbers[0]+numbers[2], numbers[1]+numbers[3]), nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"time"
+
+	"github.com/m4n5ter/lindows/pkg/yalog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type HLS struct {
+	Enabled         bool
+	SegmentDuration time.Duration
+	ListSize        int
+	IdleTimeout     time.Duration
+}
+
+func (HLS) Init(cmd *cobra.Command) error {
+	cmd.PersistentFlags().Bool("hls", false, "启用 HLS 回退输出, 供无法建立 WebRTC 连接的客户端只读观看")
+	if err := viper.BindPFlag("hls", cmd.PersistentFlags().Lookup("hls")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Duration("hls_segment_duration", time.Second, "HLS 分片时长, 越短延迟越低")
+	if err := viper.BindPFlag("hls_segment_duration", cmd.PersistentFlags().Lookup("hls_segment_duration")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Int("hls_list_size", 6, "HLS 播放列表中保留的分片数量")
+	if err := viper.BindPFlag("hls_list_size", cmd.PersistentFlags().Lookup("hls_list_size")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Duration("hls_idle_timeout", 30*time.Second, "没有观众请求多久后停止 HLS 编码")
+	err := viper.BindPFlag("hls_idle_timeout", cmd.PersistentFlags().Lookup("hls_idle_timeout"))
+	return err
+}
+
+func (s *HLS) Set() {
+	s.Enabled = viper.GetBool("hls")
+	s.SegmentDuration = viper.GetDuration("hls_segment_duration")
+	s.ListSize = viper.GetInt("hls_list_size")
+	s.IdleTimeout = viper.GetDuration("hls_idle_timeout")
+
+	if s.SegmentDuration < 200*time.Millisecond {
+		s.SegmentDuration = 200 * time.Millisecond
+	}
+	if s.ListSize < 3 {
+		s.ListSize = 3
+	}
+	// 为 0 或负数时编码器刚启动就会被当作空闲停止
+	if s.IdleTimeout <= 0 {
+		yalog.Error("无效的 HLS 空闲超时，将使用默认值", "hls_idle_timeout", s.IdleTimeout)
+		s.IdleTimeout = 30 * time.Second
+	}
+}
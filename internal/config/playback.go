@@ -0,0 +1,34 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type Playback struct {
+	Enabled bool
+	Device  string
+	Muted   bool
+}
+
+func (Playback) Init(cmd *cobra.Command) error {
+	cmd.PersistentFlags().Bool("playback", false, "在主机上播放控制者的麦克风音频, 用于双向通话")
+	if err := viper.BindPFlag("playback", cmd.PersistentFlags().Lookup("playback")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().String("playback_device", "", "播放麦克风音频的输出设备名称, 支持部分匹配, 为空时使用系统默认设备")
+	if err := viper.BindPFlag("playback_device", cmd.PersistentFlags().Lookup("playback_device")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Bool("playback_muted", false, "启动时在主机端静音麦克风音频")
+	err := viper.BindPFlag("playback_muted", cmd.PersistentFlags().Lookup("playback_muted"))
+	return err
+}
+
+func (s *Playback) Set() {
+	s.Enabled = viper.GetBool("playback")
+	s.Device = viper.GetString("playback_device")
+	s.Muted = viper.GetBool("playback_muted")
+}
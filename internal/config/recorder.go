@@ -0,0 +1,63 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type Recorder struct {
+	Enabled bool
+	Dir     string
+
+	// 分段
+	MaxFileSize     int64
+	MaxFileDuration time.Duration
+
+	// 保留策略
+	MaxAge   time.Duration
+	MaxTotal int64
+}
+
+func (Recorder) Init(cmd *cobra.Command) error {
+	cmd.PersistentFlags().Bool("record", false, "会话开始时自动录制采集的视频流")
+	if err := viper.BindPFlag("record", cmd.PersistentFlags().Lookup("record")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().String("record_dir", "recordings", "录像保存目录")
+	if err := viper.BindPFlag("record_dir", cmd.PersistentFlags().Lookup("record_dir")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Int("record_max_file_size", 512, "单个录像文件的最大大小, 单位 MB, 超过后切换到新文件, 0 表示不限制")
+	if err := viper.BindPFlag("record_max_file_size", cmd.PersistentFlags().Lookup("record_max_file_size")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Duration("record_max_file_duration", time.Hour, "单个录像文件的最大时长, 超过后切换到新文件, 0 表示不限制")
+	if err := viper.BindPFlag("record_max_file_duration", cmd.PersistentFlags().Lookup("record_max_file_duration")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Duration("record_max_age", 30*24*time.Hour, "录像的最长保留时间, 0 表示不限制")
+	if err := viper.BindPFlag("record_max_age", cmd.PersistentFlags().Lookup("record_max_age")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().Int("record_max_total", 10240, "录像目录的最大总大小, 单位 MB, 超过后删除最旧的会话录像, 0 表示不限制")
+	err := viper.BindPFlag("record_max_total", cmd.PersistentFlags().Lookup("record_max_total"))
+	return err
+}
+
+func (s *Recorder) Set() {
+	s.Enabled = viper.GetBool("record")
+	s.Dir = viper.GetString("record_dir")
+
+	s.MaxFileSize = viper.GetInt64("record_max_file_size") << 20
+	s.MaxFileDuration = viper.GetDuration("record_max_file_duration")
+
+	s.MaxAge = viper.GetDuration("record_max_age")
+	s.MaxTotal = viper.GetInt64("record_max_total") << 20
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type Server struct {
+	Bind  string
+	Token string
+}
+
+func (Server) Init(cmd *cobra.Command) error {
+	cmd.PersistentFlags().String("bind", "127.0.0.1:11111", "HTTP 服务监听地址")
+	if err := viper.BindPFlag("bind", cmd.PersistentFlags().Lookup("bind")); err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().String("token", "", "HTTP 接口的访问令牌, 为空时拒绝所有需要认证的请求")
+	err := viper.BindPFlag("token", cmd.PersistentFlags().Lookup("token"))
+	return err
+}
+
+func (s *Server) Set() {
+	s.Bind = viper.GetString("bind")
+	s.Token = viper.GetString("token")
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type Session struct {
+	// Permissions 是新会话默认拥有的权限名称，见 session.Permission
+	Permissions []string
+}
+
+func (Session) Init(cmd *cobra.Command) error {
+	cmd.PersistentFlags().StringSlice("permissions", []string{}, "新会话默认拥有的权限, 可选 system (发送 Ctrl+Alt+Del、锁定、注销等系统命令), macro (录制和回放输入宏), clipboard (与主机同步剪切板), 可以通过 PUT /sessions/{id}/permissions 单独调整")
+	err := viper.BindPFlag("permissions", cmd.PersistentFlags().Lookup("permissions"))
+
+	return err
+}
+
+func (s *Session) Set() {
+	s.Permissions = viper.GetStringSlice("permissions")
+}
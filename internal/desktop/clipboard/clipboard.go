@@ -1,119 +1,227 @@
+// Package clipboard 读写主机的剪切板
+//
+// Clipboard 中的文本总是以 \n 换行，写入 Windows 剪切板时转换为 \r\n；
+// 图像总是以 PNG 交换，与 Windows 剪切板的 CF_DIB/CF_DIBV5 互相转换；
+// HTML 是复制时的完整文档和片段在其中的范围，与 Windows 剪切板的 "HTML Format" 互相转换时加上或去掉 CF_HTML 的描述头。
 package clipboard
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
-	"runtime"
-	"time"
-	"unsafe"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
 
-	"github.com/m4n5ter/lindows/winapi"
+var (
+	// ErrEmpty 表示剪切板中没有需要的格式
+	ErrEmpty       = errors.New("clipboard has no data in the requested format")
+	ErrUnsupported = errors.New("clipboard is not supported on this platform")
 )
 
-// WriteTextToClipboard 把文本写入到剪切板
-func WriteTextToClipboard(content string) error {
-	runtime.LockOSThread()
-	defer runtime.UnlockOSThread()
+// Content 是同步的剪切板内容，Text 和 Image 只有一个有值
+type Content struct {
+	Text string
+	// HTML 和 RTF 是与 Text 一起复制的富文本，例如从 Excel 复制的表格，不认识它们的程序使用 Text。
+	// HTML 是包含 <head> 中样式的完整文档，FragmentStart 和 FragmentEnd 是复制的片段在其中的字节偏移
+	HTML          string
+	FragmentStart int
+	FragmentEnd   int
+	RTF           string
+	// Image 是 PNG 编码的图像
+	Image []byte
+}
+
+// Empty 判断内容是否为空
+func (content Content) Empty() bool {
+	return content.Text == "" && content.HTML == "" && content.RTF == "" && len(content.Image) == 0
+}
+
+// Size 返回内容的字节数
+func (content Content) Size() int {
+	return len(content.Text) + len(content.HTML) + len(content.RTF) + len(content.Image)
+}
+
+func (content Content) Equal(other Content) bool {
+	return content.Text == other.Text && content.HTML == other.HTML && content.RTF == other.RTF &&
+		content.FragmentStart == other.FragmentStart && content.FragmentEnd == other.FragmentEnd &&
+		bytes.Equal(content.Image, other.Image)
+}
+
+// Fragment 返回 HTML 中复制的片段
+func (content Content) Fragment() string {
+	start, end := fragmentBounds(content.HTML, content.FragmentStart, content.FragmentEnd)
+	return content.HTML[start:end]
+}
 
-	var hMemory winapi.HGLOBAL
-	var lpMemory winapi.LPVOID // 或者 *uint32，根据系统位数选择
+// Rich 判断内容是否带有富文本
+func (content Content) Rich() bool {
+	return content.HTML != "" || content.RTF != ""
+}
 
-	var err error
-	contentSize := int32(len(content)) + 1
-	err = waitOpenClipboard()
-	if err != nil {
-		return errors.New("OpenClipboard :剪切板打开失败")
+// normalize 统一文本的换行，并修正 HTML 片段的范围，没有 HTML 时范围为 0
+func (content Content) normalize() Content {
+	content.Text = NormalizeNewlines(content.Text)
+	if content.HTML == "" {
+		content.FragmentStart, content.FragmentEnd = 0, 0
+	} else {
+		content.FragmentStart, content.FragmentEnd = fragmentBounds(content.HTML, content.FragmentStart, content.FragmentEnd)
 	}
-	defer winapi.CloseClipboard()
+	return content
+}
 
-	// 清空剪切板
-	if !winapi.EmptyClipboard() {
-		return errors.New("EmptyClipboard :剪切板清空失败")
+// Clipboard 是主机的剪切板
+type Clipboard interface {
+	// ReadText 读取剪切板中的文本，没有文本时返回 ErrEmpty
+	ReadText() (string, error)
+	// WriteText 用 text 替换剪切板中的所有内容
+	WriteText(text string) error
+	// ReadImage 读取剪切板中的图像并编码为 PNG，没有图像时返回 ErrEmpty
+	ReadImage() ([]byte, error)
+	// WriteImage 用 PNG 编码的图像替换剪切板中的所有内容
+	WriteImage(png []byte) error
+	// Read 读取剪切板中可以同步的内容：文本和与它一起复制的 HTML、RTF，没有文本时读取图像。
+	// 没有这些格式时返回 ErrEmpty
+	Read() (Content, error)
+	// Write 用 content 替换剪切板中的所有内容，文本、HTML 和 RTF 在同一次打开中写入
+	Write(content Content) error
+	// Sequence 返回剪切板的序号，内容每次变化时都会改变，不需要打开剪切板
+	Sequence() uint32
+}
+
+// Unsupported 是不支持剪切板的平台上的 Clipboard
+type Unsupported struct{}
+
+func (Unsupported) ReadText() (string, error)  { return "", ErrUnsupported }
+func (Unsupported) WriteText(string) error     { return ErrUnsupported }
+func (Unsupported) ReadImage() ([]byte, error) { return nil, ErrUnsupported }
+func (Unsupported) WriteImage([]byte) error    { return ErrUnsupported }
+func (Unsupported) Read() (Content, error)     { return Content{}, ErrUnsupported }
+func (Unsupported) Write(Content) error        { return ErrUnsupported }
+func (Unsupported) Sequence() uint32           { return 0 }
+
+// Memory 是保存在内存中的 Clipboard，用于测试
+type Memory struct {
+	mu       sync.Mutex
+	content  Content
+	sequence uint32
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (memory *Memory) ReadText() (string, error) {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+
+	if memory.content.Text == "" {
+		return "", ErrEmpty
 	}
+	return memory.content.Text, nil
+}
+
+func (memory *Memory) WriteText(text string) error {
+	return memory.write(Content{Text: NormalizeNewlines(text)})
+}
 
-	// 申请内存
-	hMemory, err = winapi.GlobalAlloc(winapi.GmemMoveable, contentSize)
-	if err != nil {
-		return errors.New("GlobalAlloc :获取内存失败")
+func (memory *Memory) ReadImage() ([]byte, error) {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+
+	if len(memory.content.Image) == 0 {
+		return nil, ErrEmpty
 	}
-	defer winapi.GlobalFree(hMemory)
-	// 锁内存
-	lpMemory, err = winapi.GlobalLock(hMemory)
-	if err != nil {
-		return errors.New("GlobalLock :内存锁定失败")
+	return memory.content.Image, nil
+}
+
+func (memory *Memory) WriteImage(png []byte) error {
+	if err := checkPNG(png); err != nil {
+		return err
 	}
-	defer winapi.GlobalUnlock(hMemory)
-	// 这里获取的内存地址
-	cMemoryAddr := uintptr(lpMemory)
-
-	// 这里是要传输的数据
-	data := []byte(content)
-
-	// 将数据复制到 C 语言申请的内存中
-	// 遍历一个地址一个值
-	for i := 0; i < len(data); i++ {
-		b := data[i]
-		*(*byte)(unsafe.Pointer(cMemoryAddr + uintptr(i))) = b
+	return memory.write(Content{Image: png})
+}
+
+func (memory *Memory) Read() (Content, error) {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+
+	if memory.content.Empty() {
+		return Content{}, ErrEmpty
 	}
+	return memory.content, nil
+}
 
-	_, err = winapi.SetClipboardData(winapi.CFText, winapi.HANDLE(hMemory))
-	if err != nil {
-		return errors.New("SetClipboardData :剪切板设置失败")
+func (memory *Memory) Write(content Content) error {
+	if len(content.Image) > 0 {
+		return memory.WriteImage(content.Image)
 	}
+	return memory.write(content.normalize())
+}
+
+func (memory *Memory) write(content Content) error {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+
+	memory.content = content
+	memory.sequence++
 	return nil
 }
 
-// ReaderTextToClipboard 从剪切板读取文本数据
-func ReaderTextToClipboard() (string, error) {
-	runtime.LockOSThread()
-	defer runtime.UnlockOSThread()
+func (memory *Memory) Sequence() uint32 {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
 
-	var lpMemory winapi.LPVOID // 或者 *uint32，根据系统位数选择
-	var err error
-	// 打开剪切板
-	err = waitOpenClipboard()
-	if err != nil {
-		return "", errors.New("OpenClipboard :剪切板打开失败")
-	}
-	defer winapi.CloseClipboard()
-	// 获取剪切板的数据
-	r1, err := winapi.GetClipboardData(winapi.CFText)
-	if err != nil {
-		return "", errors.New("GetClipboardData:剪切板数据获取失败")
+	return memory.sequence
+}
+
+// NormalizeNewlines 把 \r\n 和单独的 \r 转换为 \n
+func NormalizeNewlines(text string) string {
+	if !strings.Contains(text, "\r") {
+		return text
 	}
-	// 锁住 剪切板的内存
-	lpMemory, err = winapi.GlobalLock(winapi.HGLOBAL(r1))
-	if err != nil {
-		return "", errors.New("GlobalLock:内存锁定失败")
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+}
+
+// toCRLF 把所有换行转换为 Windows 剪切板使用的 \r\n
+func toCRLF(text string) string {
+	return strings.ReplaceAll(NormalizeNewlines(text), "\n", "\r\n")
+}
+
+// encodeUnicodeText 把文本编码为 CF_UNICODETEXT 的内容：以 \r\n 换行、以 0 结尾的 UTF-16LE。
+// 文本中的 0 会截断剪切板的内容，所以被去掉
+func encodeUnicodeText(text string) []byte {
+	units := utf16.Encode([]rune(toCRLF(strings.ReplaceAll(text, "\x00", ""))))
+
+	data := make([]byte, 2*len(units)+2)
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(data[2*i:], unit)
 	}
-	defer winapi.GlobalUnlock(winapi.HGLOBAL(r1))
+	return data
+}
 
-	// 将内存块的内容复制到字节切片中
-	data := make([]byte, 0)
-	for {
-		b := *(*byte)(unsafe.Pointer(uintptr(lpMemory) + uintptr(len(data))))
-		if b == 0 {
+// decodeUnicodeText 解码 CF_UNICODETEXT 的内容。data 的长度是内存块的大小，
+// 文本在第一个 0 处结束，没有 0 时到内存块末尾为止，末尾不成对的字节被忽略
+func decodeUnicodeText(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		unit := binary.LittleEndian.Uint16(data[i:])
+		if unit == 0 {
 			break
 		}
-		data = append(data, b)
+		units = append(units, unit)
 	}
 
-	// 根据数据的编码解析为字符串
-	str := string(data)
-	return str, nil
-}
-
-// waitOpenClipboard 等待打开剪切板
-func waitOpenClipboard() error {
-	started := time.Now()
-	limit := started.Add(time.Second)
-	var r bool
-	var err error
-	for time.Now().Before(limit) {
-		r = winapi.OpenClipboard(winapi.HWND(uintptr(0)))
-		if r {
-			return nil
+	return NormalizeNewlines(string(utf16.Decode(units)))
+}
+
+// cString 返回 data 中第一个 0 之前的部分，没有 0 时返回整个 data
+func cString(data []byte) []byte {
+	for i, b := range data {
+		if b == 0 {
+			return data[:i]
 		}
-		time.Sleep(time.Millisecond)
 	}
-	return err
+	return data
 }
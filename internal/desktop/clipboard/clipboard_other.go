@@ -0,0 +1,8 @@
+//go:build !windows
+
+package clipboard
+
+// New 返回主机的剪切板，这个平台不支持剪切板
+func New() Clipboard {
+	return Unsupported{}
+}
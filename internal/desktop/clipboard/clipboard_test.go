@@ -0,0 +1,93 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestNormalizeNewlines(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"", ""},
+		{"a\nb", "a\nb"},
+		{"a\r\nb\r\n", "a\nb\n"},
+		{"a\rb", "a\nb"},
+		{"a\r\r\nb", "a\n\nb"},
+	} {
+		if got := NormalizeNewlines(tt.in); got != tt.want {
+			t.Errorf("NormalizeNewlines(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnicodeTextRoundTrip(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"hello",
+		"你好，世界\n第二行",
+		"emoji 😀 需要代理对",
+		"mixed\r\nline\rendings\n",
+	} {
+		data := encodeUnicodeText(text)
+		if len(data)%2 != 0 || !bytes.HasSuffix(data, []byte{0, 0}) {
+			t.Errorf("encodeUnicodeText(%q) = %x, want NUL terminated UTF-16", text, data)
+		}
+		if got, want := decodeUnicodeText(data), NormalizeNewlines(text); got != want {
+			t.Errorf("decodeUnicodeText(encodeUnicodeText(%q)) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestEncodeUnicodeText(t *testing.T) {
+	got := encodeUnicodeText("中\na\x00")
+	want := []byte{0x2d, 0x4e, '\r', 0, '\n', 0, 'a', 0, 0, 0}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeUnicodeText = %x, want %x", got, want)
+	}
+}
+
+func TestDecodeUnicodeTextBounded(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		// GlobalSize 可能大于文本，结束符之后的内容被忽略
+		{"padding after NUL", []byte{'a', 0, 0, 0, 'b', 0, 'c', 0}, "a"},
+		// 没有结束符时读到内存块末尾为止
+		{"no NUL", []byte{'a', 0, 'b', 0}, "ab"},
+		{"odd size", []byte{'a', 0, 'b'}, "a"},
+		{"empty", nil, ""},
+		{"lone surrogate", []byte{0x3d, 0xd8, 'a', 0}, "�a"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeUnicodeText(tt.data); got != tt.want {
+				t.Errorf("decodeUnicodeText(%x) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCString(t *testing.T) {
+	if got := cString([]byte("abc\x00def")); string(got) != "abc" {
+		t.Errorf("cString = %q, want abc", got)
+	}
+	if got := cString([]byte("abc")); string(got) != "abc" {
+		t.Errorf("cString without NUL = %q, want abc", got)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	var clipboard Clipboard = NewMemory()
+
+	if _, err := clipboard.ReadText(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("ReadText on empty clipboard = %v, want ErrEmpty", err)
+	}
+
+	if err := clipboard.WriteText("第一行\r\n第二行"); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := clipboard.ReadText(); err != nil || text != "第一行\n第二行" {
+		t.Errorf("ReadText() = %q, %v", text, err)
+	}
+}
@@ -0,0 +1,313 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/m4n5ter/lindows/winapi"
+)
+
+// 其他程序占用剪切板时重试打开的时长
+const openTimeout = time.Second
+
+var errOpen = errors.New("clipboard is held by another program")
+
+// formats 是按名称注册的剪切板格式
+var formats = struct {
+	sync.Mutex
+	ids map[string]uint32
+}{ids: make(map[string]uint32)}
+
+// registered 返回名为 name 的剪切板格式
+func registered(name string) (uint32, error) {
+	formats.Lock()
+	defer formats.Unlock()
+
+	if id, ok := formats.ids[name]; ok {
+		return id, nil
+	}
+
+	id, err := winapi.RegisterClipboardFormat(name)
+	if err != nil {
+		return 0, fmt.Errorf("RegisterClipboardFormat(%s): %w", name, err)
+	}
+	formats.ids[name] = id
+	return id, nil
+}
+
+// 按名称注册的格式
+const (
+	// formatPNG 是浏览器、Office 等程序使用的 PNG 格式，可以保留透明度
+	formatPNG = "PNG"
+	// formatHTML 是 CF_HTML，UTF-8 编码，见 EncodeCFHTML
+	formatHTML = "HTML Format"
+	// formatRTF 是以 0 结尾的 RTF 文档
+	formatRTF = "Rich Text Format"
+)
+
+type windows struct{}
+
+// New 返回主机的剪切板
+func New() Clipboard {
+	return windows{}
+}
+
+func (windows) ReadText() (string, error) {
+	var text string
+
+	err := open(func() (err error) {
+		text, err = readText()
+		return err
+	})
+
+	return text, err
+}
+
+func (windows) WriteText(text string) error {
+	return open(func() error {
+		if !winapi.EmptyClipboard() {
+			return errors.New("EmptyClipboard failed")
+		}
+
+		return write(winapi.CFUnicodeText, encodeUnicodeText(text))
+	})
+}
+
+func (windows) ReadImage() ([]byte, error) {
+	var data []byte
+
+	err := open(func() (err error) {
+		data, err = readImage()
+		return err
+	})
+
+	return data, err
+}
+
+func (windows) WriteImage(png []byte) error {
+	dib, err := DIBFromPNG(png)
+	if err != nil {
+		return err
+	}
+
+	return open(func() error {
+		if !winapi.EmptyClipboard() {
+			return errors.New("EmptyClipboard failed")
+		}
+		return writeImage(png, dib)
+	})
+}
+
+func (windows) Read() (Content, error) {
+	var content Content
+
+	err := open(func() error {
+		text, err := readText()
+		if errors.Is(err, ErrEmpty) {
+			content.Image, err = readImage()
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		content.Text = text
+
+		// 富文本读取失败时仍然同步纯文本
+		if data, err := readFormat(formatHTML); err == nil {
+			if document, start, end, err := DecodeCFHTML(data); err == nil {
+				content.HTML, content.FragmentStart, content.FragmentEnd = document, start, end
+			}
+		}
+		if data, err := readFormat(formatRTF); err == nil {
+			content.RTF = string(cString(data))
+		}
+		return nil
+	})
+
+	return content, err
+}
+
+func (windows) Write(content Content) error {
+	var dib []byte
+	if len(content.Image) > 0 {
+		var err error
+		if dib, err = DIBFromPNG(content.Image); err != nil {
+			return err
+		}
+	}
+	content = content.normalize()
+
+	return open(func() error {
+		if !winapi.EmptyClipboard() {
+			return errors.New("EmptyClipboard failed")
+		}
+		if dib != nil {
+			return writeImage(content.Image, dib)
+		}
+
+		if err := write(winapi.CFUnicodeText, encodeUnicodeText(content.Text)); err != nil {
+			return err
+		}
+		if content.HTML != "" {
+			if err := writeFormat(formatHTML, EncodeCFHTML(content.HTML, content.FragmentStart, content.FragmentEnd)); err != nil {
+				return err
+			}
+		}
+		if content.RTF != "" {
+			if err := writeFormat(formatRTF, append([]byte(content.RTF), 0)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (windows) Sequence() uint32 {
+	return winapi.GetClipboardSequenceNumber()
+}
+
+// readText 读取剪切板中的文本，剪切板必须已经打开
+func readText() (string, error) {
+	// 只有 CF_TEXT 时系统会自动转换出 CF_UNICODETEXT，这里的回退只用于系统没有转换的情况
+	if winapi.IsClipboardFormatAvailable(winapi.CFUnicodeText) {
+		data, err := read(winapi.CFUnicodeText)
+		if err != nil {
+			return "", err
+		}
+		return decodeUnicodeText(data), nil
+	}
+
+	if winapi.IsClipboardFormatAvailable(winapi.CFText) {
+		data, err := read(winapi.CFText)
+		if err != nil {
+			return "", err
+		}
+		units, err := winapi.MultiByteToWideChar(winapi.CpACP, cString(data))
+		if err != nil {
+			return "", fmt.Errorf("MultiByteToWideChar: %w", err)
+		}
+		return NormalizeNewlines(string(utf16.Decode(units))), nil
+	}
+
+	return "", ErrEmpty
+}
+
+// readImage 读取剪切板中的图像并编码为 PNG，剪切板必须已经打开
+func readImage() ([]byte, error) {
+	if data, err := readFormat(formatPNG); err == nil {
+		// 内存块的大小可能大于 PNG，多余的字节不影响解码
+		if checkPNG(data) == nil {
+			return data, nil
+		}
+	} else if !errors.Is(err, ErrEmpty) {
+		return nil, err
+	}
+
+	// 系统会在 CF_DIB、CF_DIBV5 和 CF_BITMAP 之间自动转换，优先读取保留了 alpha 的 CF_DIBV5
+	for _, format := range []uint32{winapi.CFDIBV5, winapi.CFDIB} {
+		if !winapi.IsClipboardFormatAvailable(format) {
+			continue
+		}
+		data, err := read(format)
+		if err != nil {
+			return nil, err
+		}
+		return PNGFromDIB(data)
+	}
+
+	return nil, ErrEmpty
+}
+
+// writeImage 写入图像，剪切板必须已经打开并清空。大多数程序读取 CF_DIB，支持透明度的程序读取 PNG
+func writeImage(png, dib []byte) error {
+	if err := write(winapi.CFDIB, dib); err != nil {
+		return err
+	}
+	return writeFormat(formatPNG, png)
+}
+
+// readFormat 读取按名称注册的格式，剪切板中没有这个格式时返回 ErrEmpty
+func readFormat(name string) ([]byte, error) {
+	format, err := registered(name)
+	if err != nil {
+		return nil, err
+	}
+	if !winapi.IsClipboardFormatAvailable(format) {
+		return nil, ErrEmpty
+	}
+	return read(format)
+}
+
+// writeFormat 写入按名称注册的格式
+func writeFormat(name string, data []byte) error {
+	format, err := registered(name)
+	if err != nil {
+		return err
+	}
+	return write(format, data)
+}
+
+// open 打开剪切板，执行 fn 后关闭。剪切板属于调用的线程，所以整个过程锁定在同一个线程上
+func open(fn func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	deadline := time.Now().Add(openTimeout)
+	for !winapi.OpenClipboard(0) {
+		if time.Now().After(deadline) {
+			return errOpen
+		}
+		time.Sleep(time.Millisecond)
+	}
+	defer winapi.CloseClipboard()
+
+	return fn()
+}
+
+// read 复制剪切板中 format 格式的内容，长度以 GlobalSize 为准，不依赖内容中的结束符
+func read(format uint32) ([]byte, error) {
+	handle, err := winapi.GetClipboardData(format)
+	if handle == 0 {
+		return nil, fmt.Errorf("GetClipboardData: %w", err)
+	}
+
+	size, err := winapi.GlobalSize(winapi.HGLOBAL(handle))
+	if err != nil {
+		return nil, fmt.Errorf("GlobalSize: %w", err)
+	}
+
+	memory, err := winapi.GlobalLock(winapi.HGLOBAL(handle))
+	if err != nil {
+		return nil, fmt.Errorf("GlobalLock: %w", err)
+	}
+	defer winapi.GlobalUnlock(winapi.HGLOBAL(handle))
+
+	return append([]byte(nil), unsafe.Slice((*byte)(memory), size)...), nil
+}
+
+// write 把 data 复制到新分配的内存中交给剪切板，成功后内存归系统所有，不能再释放
+func write(format uint32, data []byte) error {
+	handle, err := winapi.GlobalAlloc(winapi.GmemMoveable, int32(len(data)))
+	if err != nil {
+		return fmt.Errorf("GlobalAlloc: %w", err)
+	}
+
+	memory, err := winapi.GlobalLock(handle)
+	if err != nil {
+		winapi.GlobalFree(handle)
+		return fmt.Errorf("GlobalLock: %w", err)
+	}
+	copy(unsafe.Slice((*byte)(memory), len(data)), data)
+	winapi.GlobalUnlock(handle)
+
+	if _, err := winapi.SetClipboardData(format, winapi.HANDLE(handle)); err != nil {
+		winapi.GlobalFree(handle)
+		return fmt.Errorf("SetClipboardData: %w", err)
+	}
+	return nil
+}
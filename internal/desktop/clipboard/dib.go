@@ -0,0 +1,271 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/bits"
+)
+
+// MaxImagePixels 是解码的剪切板图像的最大像素数，避免异常的尺寸耗尽内存
+const MaxImagePixels = 1 << 26
+
+var ErrInvalidDIB = errors.New("invalid DIB")
+
+// BITMAPINFOHEADER 的大小和压缩方式
+const (
+	bitmapInfoHeaderSize = 40
+	bitmapV4HeaderSize   = 108
+	bitmapV5HeaderSize   = 124
+
+	biRGB            = 0
+	biBitfields      = 3
+	biAlphaBitfields = 6
+)
+
+// DecodeDIB 解码 CF_DIB 或 CF_DIBV5 的内容：BITMAPINFOHEADER、BITMAPV4HEADER 或 BITMAPV5HEADER，
+// 之后是颜色掩码、调色板和像素。支持 1、4、8 位调色板和 16、24、32 位像素，不支持 RLE 和 JPEG/PNG 压缩。
+//
+// 32 位 BI_RGB 的第四个字节按规范是保留的，但很多程序在其中存放 alpha，所以只要有一个像素的这个字节不为 0
+// 就当作 alpha，否则图像是不透明的。
+func DecodeDIB(data []byte) (*image.NRGBA, error) {
+	if len(data) < bitmapInfoHeaderSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidDIB, len(data))
+	}
+
+	headerSize := int(binary.LittleEndian.Uint32(data[0:]))
+	width := int(int32(binary.LittleEndian.Uint32(data[4:])))
+	height := int(int32(binary.LittleEndian.Uint32(data[8:])))
+	bitCount := int(binary.LittleEndian.Uint16(data[14:]))
+	compression := binary.LittleEndian.Uint32(data[16:])
+	colorsUsed := int(binary.LittleEndian.Uint32(data[32:]))
+
+	if headerSize < bitmapInfoHeaderSize || headerSize > len(data) {
+		return nil, fmt.Errorf("%w: header size %d", ErrInvalidDIB, headerSize)
+	}
+
+	// 高度为负数表示第一行在最上面
+	topDown := height < 0
+	if topDown {
+		height = -height
+	}
+	if width <= 0 || height <= 0 || width > MaxImagePixels/height {
+		return nil, fmt.Errorf("%w: size %dx%d", ErrInvalidDIB, width, height)
+	}
+
+	offset := headerSize
+	var masks [4]uint32
+	switch compression {
+	case biRGB:
+		switch bitCount {
+		case 16:
+			masks = [4]uint32{0x7c00, 0x03e0, 0x001f, 0}
+		case 24, 32:
+			masks = [4]uint32{0xff0000, 0x00ff00, 0x0000ff, 0}
+		}
+	case biBitfields, biAlphaBitfields:
+		if bitCount != 16 && bitCount != 32 {
+			return nil, fmt.Errorf("%w: bitfields with %d bits", ErrInvalidDIB, bitCount)
+		}
+		count := 3
+		if compression == biAlphaBitfields {
+			count = 4
+		}
+		// BITMAPINFOHEADER 的掩码在头之后，V4 和 V5 的掩码在头中
+		source := data[bitmapInfoHeaderSize:]
+		if headerSize == bitmapInfoHeaderSize {
+			offset += 4 * count
+		} else {
+			count = 4
+		}
+		if len(source) < 4*count {
+			return nil, fmt.Errorf("%w: missing color masks", ErrInvalidDIB)
+		}
+		for i := range count {
+			masks[i] = binary.LittleEndian.Uint32(source[4*i:])
+		}
+	default:
+		return nil, fmt.Errorf("%w: compression %d", ErrInvalidDIB, compression)
+	}
+
+	var palette []color.NRGBA
+	switch bitCount {
+	case 1, 4, 8:
+		if colorsUsed == 0 || colorsUsed > 1<<bitCount {
+			colorsUsed = 1 << bitCount
+		}
+		if len(data) < offset+4*colorsUsed {
+			return nil, fmt.Errorf("%w: missing palette", ErrInvalidDIB)
+		}
+		palette = make([]color.NRGBA, colorsUsed)
+		for i := range palette {
+			entry := data[offset+4*i:]
+			palette[i] = color.NRGBA{R: entry[2], G: entry[1], B: entry[0], A: 0xff}
+		}
+		offset += 4 * colorsUsed
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("%w: %d bits per pixel", ErrInvalidDIB, bitCount)
+	}
+
+	stride := (width*bitCount + 31) / 32 * 4
+	if len(data)-offset < stride*height {
+		return nil, fmt.Errorf("%w: want %d bytes of pixels, got %d", ErrInvalidDIB, stride*height, len(data)-offset)
+	}
+	pixels := data[offset:]
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		row := pixels[y*stride:]
+		dst := img.Pix[y*img.Stride:]
+		if !topDown {
+			dst = img.Pix[(height-1-y)*img.Stride:]
+		}
+
+		for x := range width {
+			var c color.NRGBA
+			switch bitCount {
+			case 1, 4, 8:
+				bit := x * bitCount
+				index := int(row[bit/8]>>(8-bitCount-bit%8)) & (1<<bitCount - 1)
+				if index < len(palette) {
+					c = palette[index]
+				} else {
+					c = color.NRGBA{A: 0xff}
+				}
+			case 16:
+				c = maskColor(uint32(binary.LittleEndian.Uint16(row[2*x:])), masks)
+			case 24:
+				c = color.NRGBA{R: row[3*x+2], G: row[3*x+1], B: row[3*x], A: 0xff}
+			case 32:
+				c = maskColor(binary.LittleEndian.Uint32(row[4*x:]), masks)
+				if compression == biRGB {
+					c.A = row[4*x+3]
+				}
+			}
+			copy(dst[4*x:], []byte{c.R, c.G, c.B, c.A})
+		}
+	}
+
+	if bitCount == 32 && compression == biRGB && !hasAlpha(img) {
+		setOpaque(img)
+	}
+
+	return img, nil
+}
+
+// maskColor 用掩码取出颜色分量并扩展到 8 位，没有 alpha 掩码时不透明
+func maskColor(pixel uint32, masks [4]uint32) color.NRGBA {
+	c := color.NRGBA{
+		R: component(pixel, masks[0]),
+		G: component(pixel, masks[1]),
+		B: component(pixel, masks[2]),
+		A: 0xff,
+	}
+	if masks[3] != 0 {
+		c.A = component(pixel, masks[3])
+	}
+	return c
+}
+
+func component(pixel, mask uint32) uint8 {
+	if mask == 0 {
+		return 0
+	}
+
+	shift := bits.TrailingZeros32(mask)
+	width := bits.OnesCount32(mask)
+	value := (pixel & mask) >> shift
+	if width >= 8 {
+		return uint8(value >> (width - 8))
+	}
+	// 把较少的位扩展到 0~255，例如 5 位的 31 对应 255
+	return uint8(value * 255 / (1<<width - 1))
+}
+
+func hasAlpha(img *image.NRGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func setOpaque(img *image.NRGBA) {
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+}
+
+// EncodeDIB 把图像编码为 CF_DIB 的内容：BITMAPINFOHEADER 和自下而上的 32 位 BI_RGB 像素，
+// 第四个字节存放非预乘的 alpha，读取 CF_DIB 的程序大多会忽略它
+func EncodeDIB(img image.Image) []byte {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	stride := 4 * width
+
+	data := make([]byte, bitmapInfoHeaderSize+stride*height)
+	binary.LittleEndian.PutUint32(data[0:], bitmapInfoHeaderSize)
+	binary.LittleEndian.PutUint32(data[4:], uint32(width))
+	binary.LittleEndian.PutUint32(data[8:], uint32(height))
+	binary.LittleEndian.PutUint16(data[12:], 1)
+	binary.LittleEndian.PutUint16(data[14:], 32)
+	binary.LittleEndian.PutUint32(data[16:], biRGB)
+	binary.LittleEndian.PutUint32(data[20:], uint32(stride*height))
+
+	pixels := data[bitmapInfoHeaderSize:]
+	for y := range height {
+		row := pixels[(height-1-y)*stride:]
+		for x := range width {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			copy(row[4*x:], []byte{c.B, c.G, c.R, c.A})
+		}
+	}
+	return data
+}
+
+var ErrInvalidPNG = errors.New("invalid PNG")
+
+// PNGFromDIB 把 CF_DIB 或 CF_DIBV5 的内容转换为 PNG
+func PNGFromDIB(dib []byte) ([]byte, error) {
+	img, err := DecodeDIB(dib)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DIBFromPNG 把 PNG 转换为 CF_DIB 的内容，解码前先检查尺寸
+func DIBFromPNG(data []byte) ([]byte, error) {
+	if err := checkPNG(data); err != nil {
+		return nil, err
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPNG, err)
+	}
+	return EncodeDIB(img), nil
+}
+
+// checkPNG 只读取 PNG 的头，检查格式和尺寸
+func checkPNG(data []byte) error {
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPNG, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > MaxImagePixels/config.Height {
+		return fmt.Errorf("%w: size %dx%d", ErrInvalidPNG, config.Width, config.Height)
+	}
+	return nil
+}
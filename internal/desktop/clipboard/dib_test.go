@@ -0,0 +1,233 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// dibHeader 构造 BITMAPINFOHEADER 或指定大小的 V4/V5 头，extra 附加在头之后
+func dibHeader(size, width, height, bitCount int, compression uint32, masks [4]uint32, extra ...byte) []byte {
+	header := make([]byte, size)
+	binary.LittleEndian.PutUint32(header[0:], uint32(size))
+	binary.LittleEndian.PutUint32(header[4:], uint32(int32(width)))
+	binary.LittleEndian.PutUint32(header[8:], uint32(int32(height)))
+	binary.LittleEndian.PutUint16(header[12:], 1)
+	binary.LittleEndian.PutUint16(header[14:], uint16(bitCount))
+	binary.LittleEndian.PutUint32(header[16:], compression)
+	if size > bitmapInfoHeaderSize {
+		for i, mask := range masks {
+			binary.LittleEndian.PutUint32(header[40+4*i:], mask)
+		}
+	}
+	return append(header, extra...)
+}
+
+func assertPixels(t *testing.T, img *image.NRGBA, want [][]color.NRGBA) {
+	t.Helper()
+
+	if img.Bounds() != image.Rect(0, 0, len(want[0]), len(want)) {
+		t.Fatalf("bounds = %v, want %dx%d", img.Bounds(), len(want[0]), len(want))
+	}
+	for y, row := range want {
+		for x, c := range row {
+			if got := img.NRGBAAt(x, y); got != c {
+				t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, c)
+			}
+		}
+	}
+}
+
+var (
+	red   = color.NRGBA{R: 0xff, A: 0xff}
+	green = color.NRGBA{G: 0xff, A: 0xff}
+	blue  = color.NRGBA{B: 0xff, A: 0xff}
+	white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	black = color.NRGBA{A: 0xff}
+)
+
+func TestDIBRoundTrip(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(10, 20, 13, 22))
+	src.SetNRGBA(10, 20, red)
+	src.SetNRGBA(11, 20, color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0x80})
+	src.SetNRGBA(12, 21, blue)
+
+	data := EncodeDIB(src)
+	if len(data) != bitmapInfoHeaderSize+4*3*2 {
+		t.Errorf("EncodeDIB length = %d", len(data))
+	}
+
+	img, err := DecodeDIB(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, img, [][]color.NRGBA{
+		{red, {R: 0x10, G: 0x20, B: 0x30, A: 0x80}, {}},
+		{{}, {}, blue},
+	})
+}
+
+func TestDecodeDIB24BottomUp(t *testing.T) {
+	// 3 个像素 9 字节，每行补齐到 12 字节；自下而上，第一行数据是最下面一行
+	pixels := []byte{
+		0xff, 0, 0, 0, 0xff, 0, 0, 0, 0xff, 0, 0, 0,
+		0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	img, err := DecodeDIB(dibHeader(bitmapInfoHeaderSize, 3, 2, 24, biRGB, [4]uint32{}, pixels...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, img, [][]color.NRGBA{
+		{white, black, black},
+		{blue, green, red},
+	})
+}
+
+func TestDecodeDIB32Alpha(t *testing.T) {
+	// 所有保留字节都是 0 时图像不透明
+	img, err := DecodeDIB(dibHeader(bitmapInfoHeaderSize, 2, 1, 32, biRGB, [4]uint32{}, 0, 0, 0xff, 0, 0xff, 0, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, img, [][]color.NRGBA{{red, blue}})
+
+	// 有一个不为 0 时作为 alpha
+	img, err = DecodeDIB(dibHeader(bitmapInfoHeaderSize, 2, 1, 32, biRGB, [4]uint32{}, 0, 0, 0xff, 0x80, 0xff, 0, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, img, [][]color.NRGBA{{{R: 0xff, A: 0x80}, {B: 0xff}}})
+}
+
+func TestDecodeDIBV5Bitfields(t *testing.T) {
+	// 自上而下，RGBA 字节顺序的掩码
+	masks := [4]uint32{0x000000ff, 0x0000ff00, 0x00ff0000, 0xff000000}
+	pixels := []byte{
+		0xff, 0, 0, 0xff, 0, 0xff, 0, 0x40,
+		0, 0, 0xff, 0xff, 0, 0, 0, 0,
+	}
+	img, err := DecodeDIB(dibHeader(bitmapV5HeaderSize, 2, -2, 32, biBitfields, masks, pixels...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, img, [][]color.NRGBA{
+		{red, {G: 0xff, A: 0x40}},
+		{blue, {}},
+	})
+}
+
+func TestDecodeDIB16Bitfields(t *testing.T) {
+	// BITMAPINFOHEADER 的 565 掩码在头之后
+	masks := []byte{0x00, 0xf8, 0, 0, 0xe0, 0x07, 0, 0, 0x1f, 0, 0, 0}
+	pixels := []byte{0x00, 0xf8, 0xe0, 0x07, 0x1f, 0x00, 0xff, 0xff}
+	img, err := DecodeDIB(dibHeader(bitmapInfoHeaderSize, 4, 1, 16, biBitfields, [4]uint32{}, append(masks, pixels...)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, img, [][]color.NRGBA{{red, green, blue, white}})
+
+	// 没有掩码时是 555
+	img, err = DecodeDIB(dibHeader(bitmapInfoHeaderSize, 2, 1, 16, biRGB, [4]uint32{}, 0x00, 0x7c, 0x1f, 0x00))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, img, [][]color.NRGBA{{red, blue}})
+}
+
+func TestDecodeDIBPalette(t *testing.T) {
+	palette := []byte{0, 0, 0, 0, 0xff, 0xff, 0xff, 0}
+
+	// 1 位：10 个像素 0b10100000 0b01000000，每行补齐到 4 字节
+	img, err := DecodeDIB(dibHeader(bitmapInfoHeaderSize, 10, 1, 1, biRGB, [4]uint32{}, append(palette, 0xa0, 0x40, 0, 0)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, img, [][]color.NRGBA{{white, black, white, black, black, black, black, black, black, white}})
+
+	// 8 位，调色板只有 2 种颜色，超出调色板的索引为黑色
+	header := dibHeader(bitmapInfoHeaderSize, 3, 1, 8, biRGB, [4]uint32{})
+	binary.LittleEndian.PutUint32(header[32:], 2)
+	img, err = DecodeDIB(append(append(header, palette...), 1, 0, 7, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, img, [][]color.NRGBA{{white, black, black}})
+}
+
+func TestDecodeDIBInvalid(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"short":            make([]byte, 10),
+		"bad header size":  dibHeader(bitmapInfoHeaderSize, 1, 1, 32, biRGB, [4]uint32{})[:39],
+		"zero width":       dibHeader(bitmapInfoHeaderSize, 0, 1, 32, biRGB, [4]uint32{}),
+		"huge":             dibHeader(bitmapInfoHeaderSize, 1<<16, 1<<16, 32, biRGB, [4]uint32{}),
+		"rle":              dibHeader(bitmapInfoHeaderSize, 1, 1, 8, 1, [4]uint32{}, make([]byte, 1024)...),
+		"bits":             dibHeader(bitmapInfoHeaderSize, 1, 1, 2, biRGB, [4]uint32{}, make([]byte, 64)...),
+		"missing pixels":   dibHeader(bitmapInfoHeaderSize, 4, 4, 32, biRGB, [4]uint32{}, make([]byte, 60)...),
+		"missing masks":    dibHeader(bitmapInfoHeaderSize, 1, 1, 32, biBitfields, [4]uint32{}, 0, 0),
+		"24 bit bitfields": dibHeader(bitmapInfoHeaderSize, 1, 1, 24, biBitfields, [4]uint32{}, make([]byte, 64)...),
+	} {
+		if _, err := DecodeDIB(data); !errors.Is(err, ErrInvalidDIB) {
+			t.Errorf("%s: DecodeDIB = %v, want ErrInvalidDIB", name, err)
+		}
+	}
+}
+
+func TestPNGConversion(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	src.SetNRGBA(0, 0, red)
+	src.SetNRGBA(1, 1, color.NRGBA{G: 0xff, A: 0x40})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	dib, err := DIBFromPNG(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := DecodeDIB(dib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, img, [][]color.NRGBA{{red, {}}, {{}, {G: 0xff, A: 0x40}}})
+
+	data, err := PNGFromDIB(dib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixels(t, decoded.(*image.NRGBA), [][]color.NRGBA{{red, {}}, {{}, {G: 0xff, A: 0x40}}})
+
+	if _, err := DIBFromPNG([]byte("not a png")); !errors.Is(err, ErrInvalidPNG) {
+		t.Errorf("DIBFromPNG(garbage) = %v, want ErrInvalidPNG", err)
+	}
+	if _, err := PNGFromDIB([]byte("not a dib")); !errors.Is(err, ErrInvalidDIB) {
+		t.Errorf("PNGFromDIB(garbage) = %v, want ErrInvalidDIB", err)
+	}
+}
+
+func TestDIBFromPNGRejectsHugeImages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+
+	// 把 IHDR 中的宽高改为 65536x65536，重新计算 CRC，解码像素之前就应该被拒绝
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 1<<16)
+	binary.BigEndian.PutUint32(data[20:], 1<<16)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	if _, err := DIBFromPNG(data); !errors.Is(err, ErrInvalidPNG) {
+		t.Errorf("DIBFromPNG(huge) = %v, want ErrInvalidPNG", err)
+	}
+}
@@ -0,0 +1,115 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidHTML = errors.New("invalid HTML Format")
+
+// CF_HTML 中标记片段范围的注释
+const (
+	startFragment = "<!--StartFragment-->"
+	endFragment   = "<!--EndFragment-->"
+)
+
+// cfHTMLHeader 是 CF_HTML 的描述头，偏移量固定为 10 位，这样头的长度不随数值变化
+const cfHTMLHeader = "Version:0.9\r\nStartHTML:%010d\r\nEndHTML:%010d\r\nStartFragment:%010d\r\nEndFragment:%010d\r\n"
+
+// EncodeCFHTML 把 HTML 文档编码为 "HTML Format" 的内容：描述头之后是完整的文档，
+// 头中的偏移量是 UTF-8 字节数，start 和 end 是复制的片段在 document 中的范围，无效时按 fragmentBounds 查找。
+// document 只是一个片段、没有 <html> 时包上 <html> 和 <body>，并用片段标记标出它
+func EncodeCFHTML(document string, start, end int) []byte {
+	start, end = fragmentBounds(document, start, end)
+	if !strings.Contains(strings.ToLower(document), "<html") {
+		prefix := "<html>\r\n<body>\r\n" + startFragment
+		suffix := endFragment + "\r\n</body>\r\n</html>"
+
+		document = prefix + document[start:end] + suffix
+		start, end = len(prefix), len(document)-len(suffix)
+	}
+
+	headerSize := len(fmt.Sprintf(cfHTMLHeader, 0, 0, 0, 0))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, cfHTMLHeader, headerSize, headerSize+len(document), headerSize+start, headerSize+end)
+	buf.WriteString(document)
+	return buf.Bytes()
+}
+
+// DecodeCFHTML 从 "HTML Format" 的内容中取出 StartHTML 到 EndHTML 之间的完整文档，
+// 以及复制的片段在文档中的范围，<head> 中的样式等只有完整的文档才有。
+//
+// StartHTML 和 EndHTML 缺失或越界时文档从第一个 < 开始；片段优先使用描述头中的 StartFragment 和
+// EndFragment，偏移量缺失或不在文档中时查找片段标记，仍然找不到时整个文档都是片段。
+func DecodeCFHTML(data []byte) (document string, start, end int, err error) {
+	data = cString(data)
+
+	header := make(map[string]int)
+	for rest := data; len(rest) > 0 && rest[0] != '<'; {
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = nil
+		}
+
+		key, value, ok := strings.Cut(strings.TrimRight(string(line), "\r"), ":")
+		if !ok {
+			continue
+		}
+		// SourceURL 等字段不是数字，这里不需要
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			header[key] = n
+		}
+	}
+	if _, ok := header["StartHTML"]; !ok {
+		if _, ok := header["StartFragment"]; !ok {
+			return "", 0, 0, fmt.Errorf("%w: missing header", ErrInvalidHTML)
+		}
+	}
+
+	valid := func(start, end int) bool {
+		return 0 <= start && start <= end && end <= len(data)
+	}
+
+	// 有的程序写入 -1 或错误的偏移量，退回到第一个 <
+	startHTML, okStart := header["StartHTML"]
+	endHTML, okEnd := header["EndHTML"]
+	if !okStart || !okEnd || !valid(startHTML, endHTML) {
+		startHTML = bytes.IndexByte(data, '<')
+		if startHTML < 0 {
+			return "", 0, 0, fmt.Errorf("%w: no HTML", ErrInvalidHTML)
+		}
+		endHTML = len(data)
+	}
+	document = string(data[startHTML:endHTML])
+
+	start, end = -1, -1
+	startFrag, okStart := header["StartFragment"]
+	endFrag, okEnd := header["EndFragment"]
+	if okStart && okEnd {
+		start, end = startFrag-startHTML, endFrag-startHTML
+	}
+	start, end = fragmentBounds(document, start, end)
+	return document, start, end, nil
+}
+
+// fragmentBounds 返回复制的片段在 document 中的范围：start 和 end 在文档之内时直接使用，
+// 否则查找片段标记，没有标记时整个文档都是片段
+func fragmentBounds(document string, start, end int) (int, int) {
+	if 0 <= start && start < end && end <= len(document) {
+		return start, end
+	}
+
+	if i := strings.Index(document, startFragment); i >= 0 {
+		start := i + len(startFragment)
+		if j := strings.Index(document[start:], endFragment); j >= 0 {
+			return start, start + j
+		}
+	}
+	return 0, len(document)
+}
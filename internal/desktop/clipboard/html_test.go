@@ -0,0 +1,126 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// parseHeader 按 cfHTMLHeader 的格式读取偏移量
+func parseHeader(t *testing.T, data []byte) (startHTML, endHTML, startFragment, endFragment int) {
+	t.Helper()
+
+	if _, err := fmt.Sscanf(string(data), cfHTMLHeader, &startHTML, &endHTML, &startFragment, &endFragment); err != nil {
+		t.Fatalf("parse header: %v\n%s", err, data)
+	}
+	return startHTML, endHTML, startFragment, endFragment
+}
+
+func TestEncodeCFHTML(t *testing.T) {
+	fragment := "<table><tr><td>单元格</td></tr></table>"
+	data := EncodeCFHTML(fragment, 0, len(fragment))
+
+	// 按头中的偏移量取出的内容必须与片段完全一致，偏移量是 UTF-8 字节数
+	startHTML, endHTML, startFragment, endFragment := parseHeader(t, data)
+	if got := string(data[startFragment:endFragment]); got != fragment {
+		t.Errorf("fragment by offsets = %q, want %q", got, fragment)
+	}
+	if endHTML != len(data) || !strings.HasPrefix(string(data[startHTML:]), "<html>") {
+		t.Errorf("StartHTML %d, EndHTML %d, length %d", startHTML, endHTML, len(data))
+	}
+
+	document, start, end, err := DecodeCFHTML(data)
+	if err != nil || document[start:end] != fragment {
+		t.Errorf("DecodeCFHTML(EncodeCFHTML) = %q [%d:%d], %v", document, start, end, err)
+	}
+
+	// 完整的文档原样保留，没有给出范围时按片段标记取片段
+	full := "<html><head><style>td{color:red}</style></head><body><!--StartFragment--><b>x</b><!--EndFragment--></body></html>"
+	data = EncodeCFHTML(full, -1, -1)
+	startHTML, endHTML, startFragment, endFragment = parseHeader(t, data)
+	if string(data[startHTML:endHTML]) != full || string(data[startFragment:endFragment]) != "<b>x</b>" {
+		t.Errorf("EncodeCFHTML(full) = %q", data)
+	}
+}
+
+func TestDecodeCFHTML(t *testing.T) {
+	// 带 SourceURL 的内容，文档后面还有 GlobalSize 带来的 0
+	document := "<html>\r\n<body>\r\n<!--StartFragment--><td>1</td><!--EndFragment-->\r\n</body>\r\n</html>"
+	header := "Version:1.0\r\nStartHTML:%010d\r\nEndHTML:%010d\r\nStartFragment:%010d\r\nEndFragment:%010d\r\nSourceURL:file:///C:/book.xlsx\r\n"
+	size := len(fmt.Sprintf(header, 0, 0, 0, 0))
+	build := func(startFragment, endFragment int) []byte {
+		return []byte(fmt.Sprintf(header, size, size+len(document), startFragment, endFragment) + document + "\x00\x00")
+	}
+
+	start := size + strings.Index(document, "<td>")
+	end := start + len("<td>1</td>")
+
+	for _, tt := range []struct {
+		name     string
+		data     []byte
+		document string
+		fragment string
+	}{
+		{"offsets", build(start, end), document, "<td>1</td>"},
+		{"negative offsets", build(-1, -1), document, "<td>1</td>"},
+		{"offsets out of range", build(start, 9999999), document, "<td>1</td>"},
+		{"no markers", []byte("Version:0.9\r\nStartHTML:-1\r\nEndHTML:-1\r\nStartFragment:-1\r\nEndFragment:-1\r\n<p>x</p>"), "<p>x</p>", "<p>x</p>"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, start, end, err := DecodeCFHTML(tt.data)
+			if err != nil || got != tt.document || got[start:end] != tt.fragment {
+				t.Errorf("DecodeCFHTML = %q [%d:%d], %v, want %q with fragment %q", got, start, end, err, tt.document, tt.fragment)
+			}
+		})
+	}
+
+	for _, data := range []string{"", "<p>no header</p>", "Version:0.9\r\nStartHTML:0\r\n"} {
+		if _, _, _, err := DecodeCFHTML([]byte(data)); !errors.Is(err, ErrInvalidHTML) {
+			t.Errorf("DecodeCFHTML(%q) = %v, want ErrInvalidHTML", data, err)
+		}
+	}
+}
+
+// TestExcelCFHTML 使用从 Excel 复制两行单元格得到的 "HTML Format"：片段从 <table> 里面开始，
+// 单元格的样式在 <head> 的 <style> 中，只同步片段会丢掉加粗和背景色
+func TestExcelCFHTML(t *testing.T) {
+	data, err := os.ReadFile("testdata/excel.cfhtml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document, start, end, err := DecodeCFHTML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(document, "<html") || !strings.HasSuffix(document, "</html>\r\n") {
+		t.Errorf("document = %q...%q", document[:min(len(document), 20)], document[max(0, len(document)-20):])
+	}
+	if !strings.Contains(document, ".xl65\r\n\t{font-weight:700;") {
+		t.Error("document lost the cell styles in <head>")
+	}
+
+	fragment := document[start:end]
+	if !strings.HasPrefix(fragment, "\r\n <col width=72") || !strings.HasSuffix(fragment, " </tr>\r\n") || !strings.Contains(fragment, ">苹果</td>") {
+		t.Errorf("fragment = %q", fragment)
+	}
+
+	// 同步到另一端后重建的 CF_HTML 与 Excel 写入的一致
+	content := Content{Text: "名称\t数量\n苹果\t3\n", HTML: document, FragmentStart: start, FragmentEnd: end}.normalize()
+	if content.Fragment() != fragment {
+		t.Errorf("normalized fragment = %q", content.Fragment())
+	}
+
+	encoded := EncodeCFHTML(content.HTML, content.FragmentStart, content.FragmentEnd)
+	startHTML, endHTML, startFragment, endFragment := parseHeader(t, encoded)
+	if string(encoded[startHTML:endHTML]) != document || string(encoded[startFragment:endFragment]) != fragment {
+		t.Errorf("re-encoded CF_HTML does not match:\n%s", encoded)
+	}
+
+	original := string(cString(data))
+	if got := string(encoded[startHTML:]); got != original[strings.Index(original, "<html"):] {
+		t.Error("re-encoded document differs from the one Excel wrote")
+	}
+}
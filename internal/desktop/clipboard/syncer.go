@@ -0,0 +1,225 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+var ErrTooLarge = errors.New("clipboard content is too large")
+
+// Limits 是同步的内容的最大字节数，为 0 时不限制
+type Limits struct {
+	Text  int
+	Image int
+	// Rich 限制 HTML 和 RTF 各自的大小，超过时只同步纯文本
+	Rich int
+}
+
+// Change 是剪切板内容的一次变化
+type Change struct {
+	Content
+	// Origin 是通过 Apply 写入这份内容的会话，为空表示主机上的程序修改了剪切板
+	Origin string
+}
+
+// Syncer 轮询主机剪切板的序号，内容变化时通知监听者，并把会话发来的内容写入主机剪切板。
+//
+// 剪切板中同时有文本和图像时只同步文本，例如从表格复制单元格时系统也会附带一张图片；
+// 与文本一起复制的 HTML 和 RTF 作为富文本同步，超过 Limits.Rich 的富文本被丢掉，只留下纯文本。
+// 写入的内容不会作为变化再通知给写入它的会话，与当前内容相同的内容也不会重复写入和通知，
+// 这样客户端收到内容后写入本地剪切板再发回来时不会形成循环。
+type Syncer struct {
+	logger    *yalog.Logger
+	clipboard Clipboard
+	interval  time.Duration
+	limits    Limits
+
+	mu        sync.Mutex
+	sequence  uint32
+	current   Change
+	ok        bool
+	listeners []func(Change)
+
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+func NewSyncer(clipboard Clipboard, interval time.Duration, limits Limits) *Syncer {
+	return &Syncer{
+		logger:    yalog.Default().With("module", "clipboard"),
+		clipboard: clipboard,
+		interval:  interval,
+		limits:    limits,
+	}
+}
+
+// Limits 返回同步的内容的最大字节数
+func (syncer *Syncer) Limits() Limits {
+	return syncer.limits
+}
+
+// OnChange 注册内容变化的监听者
+func (syncer *Syncer) OnChange(listener func(Change)) {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	syncer.listeners = append(syncer.listeners, listener)
+}
+
+func (syncer *Syncer) Start() {
+	syncer.shutdown = make(chan struct{})
+	syncer.done = make(chan struct{})
+
+	// 启动时的内容作为当前内容，但不通知
+	syncer.mu.Lock()
+	syncer.sequence = syncer.clipboard.Sequence()
+	if content, err := syncer.read(); err == nil {
+		syncer.current, syncer.ok = Change{Content: content}, true
+	}
+	syncer.mu.Unlock()
+
+	go func() {
+		defer close(syncer.done)
+
+		ticker := time.NewTicker(syncer.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-syncer.shutdown:
+				return
+			case <-ticker.C:
+				syncer.poll()
+			}
+		}
+	}()
+}
+
+func (syncer *Syncer) Shutdown() {
+	if syncer.shutdown == nil {
+		return
+	}
+
+	close(syncer.shutdown)
+	<-syncer.done
+}
+
+// Content 返回主机剪切板当前的内容，剪切板中没有可以同步的内容时 ok 为 false
+func (syncer *Syncer) Content() (content Content, ok bool) {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	return syncer.current.Content, syncer.ok
+}
+
+// Apply 把会话 origin 发来的内容写入主机剪切板，并以 origin 作为 Change.Origin 通知监听者
+func (syncer *Syncer) Apply(origin string, content Content) error {
+	content = syncer.trim(content.normalize())
+	if err := syncer.allowed(content); err != nil {
+		return err
+	}
+	if content.Empty() {
+		return nil
+	}
+
+	syncer.mu.Lock()
+	if syncer.ok && syncer.current.Equal(content) {
+		syncer.mu.Unlock()
+		return nil
+	}
+
+	if err := syncer.clipboard.Write(content); err != nil {
+		syncer.mu.Unlock()
+		return err
+	}
+	// 记下写入后的序号，轮询时就不会把自己写入的内容当作主机的变化
+	syncer.sequence = syncer.clipboard.Sequence()
+	change := Change{Content: content, Origin: origin}
+	syncer.current, syncer.ok = change, true
+	listeners := syncer.listeners
+	syncer.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(change)
+	}
+	return nil
+}
+
+func (syncer *Syncer) allowed(content Content) error {
+	if syncer.limits.Text > 0 && len(content.Text) > syncer.limits.Text {
+		return fmt.Errorf("%w: %d bytes of text, limit %d", ErrTooLarge, len(content.Text), syncer.limits.Text)
+	}
+	if syncer.limits.Image > 0 && len(content.Image) > syncer.limits.Image {
+		return fmt.Errorf("%w: %d bytes of image, limit %d", ErrTooLarge, len(content.Image), syncer.limits.Image)
+	}
+	return nil
+}
+
+// trim 去掉超过大小限制的富文本
+func (syncer *Syncer) trim(content Content) Content {
+	if syncer.limits.Rich <= 0 {
+		return content
+	}
+	if len(content.HTML) > syncer.limits.Rich {
+		content.HTML, content.FragmentStart, content.FragmentEnd = "", 0, 0
+	}
+	if len(content.RTF) > syncer.limits.Rich {
+		content.RTF = ""
+	}
+	return content
+}
+
+// read 读取剪切板中可以同步的内容
+func (syncer *Syncer) read() (Content, error) {
+	content, err := syncer.clipboard.Read()
+	if err != nil {
+		return Content{}, err
+	}
+
+	content = syncer.trim(content)
+	if err := syncer.allowed(content); err != nil {
+		return Content{}, err
+	}
+	return content, nil
+}
+
+func (syncer *Syncer) poll() {
+	sequence := syncer.clipboard.Sequence()
+
+	syncer.mu.Lock()
+	if sequence == syncer.sequence {
+		syncer.mu.Unlock()
+		return
+	}
+	syncer.sequence = sequence
+	syncer.mu.Unlock()
+
+	// 在锁外读取，打开剪切板可能要等待其他程序
+	content, err := syncer.read()
+	if errors.Is(err, ErrEmpty) {
+		// 复制了文件等其他格式，客户端保留原来的内容
+		return
+	}
+	if err != nil {
+		syncer.logger.Debug("Clipboard content not synced", "error", err)
+		return
+	}
+
+	syncer.mu.Lock()
+	if syncer.ok && syncer.current.Equal(content) {
+		syncer.mu.Unlock()
+		return
+	}
+	change := Change{Content: content}
+	syncer.current, syncer.ok = change, true
+	listeners := syncer.listeners
+	syncer.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(change)
+	}
+}
@@ -0,0 +1,170 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncer(t *testing.T) {
+	memory := NewMemory()
+	if err := memory.WriteText("initial"); err != nil {
+		t.Fatal(err)
+	}
+
+	syncer := NewSyncer(memory, time.Hour, Limits{Text: 16})
+	var changes []Change
+	syncer.OnChange(func(change Change) { changes = append(changes, change) })
+
+	syncer.Start()
+	defer syncer.Shutdown()
+
+	// 启动时的内容不通知
+	if content, ok := syncer.Content(); !ok || content.Text != "initial" {
+		t.Errorf("Content() = %+v, %t, want initial", content, ok)
+	}
+
+	// 主机上的程序修改剪切板
+	_ = memory.WriteText("host\r\ntext")
+	syncer.poll()
+	syncer.poll()
+
+	// 会话写入的文本以会话作为来源通知，轮询时不会再当作主机的变化
+	if err := syncer.Apply("a", Content{Text: "from a"}); err != nil {
+		t.Fatal(err)
+	}
+	syncer.poll()
+	if text, _ := memory.ReadText(); text != "from a" {
+		t.Errorf("host clipboard = %q, want from a", text)
+	}
+
+	// 客户端把收到的文本写回来时不重复写入
+	sequence := memory.Sequence()
+	if err := syncer.Apply("b", Content{Text: "from a"}); err != nil {
+		t.Fatal(err)
+	}
+	if memory.Sequence() != sequence {
+		t.Error("Apply of the current text wrote the clipboard again")
+	}
+
+	// 主机写入相同的文本时不通知
+	_ = memory.WriteText("from a")
+	syncer.poll()
+
+	want := []Change{
+		{Content: Content{Text: "host\ntext"}},
+		{Content: Content{Text: "from a"}, Origin: "a"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
+
+func TestSyncerMaxSize(t *testing.T) {
+	memory := NewMemory()
+	syncer := NewSyncer(memory, time.Hour, Limits{Text: 4})
+	var changes []Change
+	syncer.OnChange(func(change Change) { changes = append(changes, change) })
+
+	if err := syncer.Apply("a", Content{Text: "12345"}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Apply of large text = %v, want ErrTooLarge", err)
+	}
+	if _, err := memory.ReadText(); !errors.Is(err, ErrEmpty) {
+		t.Error("large text was written to the clipboard")
+	}
+
+	_ = memory.WriteText("12345")
+	syncer.poll()
+	if _, ok := syncer.Content(); ok {
+		t.Error("large host text became the current text")
+	}
+
+	_ = memory.WriteText("1234")
+	syncer.poll()
+	if want := []Change{{Content: Content{Text: "1234"}}}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
+
+func TestSyncerImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	picture := buf.Bytes()
+
+	memory := NewMemory()
+	syncer := NewSyncer(memory, time.Hour, Limits{Text: 100, Image: len(picture)})
+	var changes []Change
+	syncer.OnChange(func(change Change) { changes = append(changes, change) })
+
+	_ = memory.WriteImage(picture)
+	syncer.poll()
+
+	if err := syncer.Apply("a", Content{Text: "caption"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncer.Apply("b", Content{Image: picture}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := memory.ReadImage(); err != nil || !bytes.Equal(got, picture) {
+		t.Errorf("host image = %v, %v", got, err)
+	}
+	if err := syncer.Apply("b", Content{Image: append(picture, 0)}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Apply of large image = %v, want ErrTooLarge", err)
+	}
+	if err := syncer.Apply("c", Content{Image: []byte("garbage")}); !errors.Is(err, ErrInvalidPNG) {
+		t.Errorf("Apply of invalid image = %v, want ErrInvalidPNG", err)
+	}
+
+	want := []Change{
+		{Content: Content{Image: picture}},
+		{Content: Content{Text: "caption"}, Origin: "a"},
+		{Content: Content{Image: picture}, Origin: "b"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
+
+func TestSyncerRich(t *testing.T) {
+	memory := NewMemory()
+	syncer := NewSyncer(memory, time.Hour, Limits{Text: 100, Rich: 128})
+	var changes []Change
+	syncer.OnChange(func(change Change) { changes = append(changes, change) })
+
+	// 客户端发来的完整文档原样保留，没有给出片段的范围时按片段标记确定，富文本与纯文本一起写入
+	document := "<html><body><!--StartFragment--><td>a</td><td>b</td><!--EndFragment--></body></html>"
+	start := strings.Index(document, "<td>")
+	end := strings.Index(document, "<!--EndFragment-->")
+	table := Content{Text: "a\tb", HTML: document, RTF: `{\rtf1 a\tab b}`}
+	if err := syncer.Apply("a", table); err != nil {
+		t.Fatal(err)
+	}
+	got, err := memory.Read()
+	if want := (Content{Text: "a\tb", HTML: document, FragmentStart: start, FragmentEnd: end, RTF: `{\rtf1 a\tab b}`}); err != nil || !got.Equal(want) {
+		t.Errorf("host clipboard = %+v, %v, want %+v", got, err, want)
+	}
+
+	// 只有富文本不同也是一次变化
+	_ = memory.Write(Content{Text: "a\tb", HTML: "<td>a</td>", FragmentStart: 0, FragmentEnd: 10})
+	syncer.poll()
+
+	// 超过限制的富文本被丢掉，纯文本照常同步
+	_ = memory.Write(Content{Text: "large", HTML: "<p>" + strings.Repeat("x", 128) + "</p>", RTF: `{\rtf1 large}`})
+	syncer.poll()
+
+	want := []Change{
+		{Content: Content{Text: "a\tb", HTML: document, FragmentStart: start, FragmentEnd: end, RTF: `{\rtf1 a\tab b}`}, Origin: "a"},
+		{Content: Content{Text: "a\tb", HTML: "<td>a</td>", FragmentStart: 0, FragmentEnd: 10}},
+		{Content: Content{Text: "large", RTF: `{\rtf1 large}`}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
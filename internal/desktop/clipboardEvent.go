@@ -2,10 +2,17 @@ package desktop
 
 import "github.com/m4n5ter/lindows/internal/desktop/clipboard"
 
+// ClipboardSync 返回剪切板的同步，没有启用同步时返回 nil
+func (manager *Manager) ClipboardSync() *clipboard.Syncer {
+	return manager.clipboardSync
+}
+
+// WriteTextToClipboard 用文本替换主机剪切板的内容
 func (manager *Manager) WriteTextToClipboard(content string) error {
-	return clipboard.WriteTextToClipboard(content)
+	return manager.clipboard.WriteText(content)
 }
 
+// ReaderTextToClipboard 读取主机剪切板中的文本
 func (manager *Manager) ReaderTextToClipboard() (string, error) {
-	return clipboard.ReaderTextToClipboard()
+	return manager.clipboard.ReadText()
 }
@@ -0,0 +1,114 @@
+// Package command 执行浏览器无法转发的系统命令，例如 Ctrl+Alt+Del 和 Win+L
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+)
+
+var (
+	ErrUnknown     = errors.New("unknown system command")
+	ErrUnsupported = errors.New("system command is not supported on this platform")
+)
+
+// Command 是客户端按名称请求的系统命令
+type Command string
+
+const (
+	// SecureAttention 发送安全注意序列，相当于 Ctrl+Alt+Del
+	SecureAttention Command = "sas"
+	// Lock 锁定工作站，相当于 Win+L
+	Lock Command = "lock"
+	// TaskSwitcher 打开不需要按住 Alt 的任务切换器，相当于 Ctrl+Alt+Tab
+	TaskSwitcher Command = "task_switcher"
+	// ShowDesktop 显示桌面，相当于 Win+D
+	ShowDesktop Command = "show_desktop"
+	// TaskManager 打开任务管理器，相当于 Ctrl+Shift+Esc
+	TaskManager Command = "task_manager"
+	// Logoff 注销当前用户
+	Logoff Command = "logoff"
+)
+
+// Parse 检查 name 是否是已知的命令
+func Parse(name string) (Command, error) {
+	switch command := Command(name); command {
+	case SecureAttention, Lock, TaskSwitcher, ShowDesktop, TaskManager, Logoff:
+		return command, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnknown, name)
+	}
+}
+
+// System 执行无法用按键模拟的命令，系统会拦截注入的 Ctrl+Alt+Del 和 Win+L
+type System interface {
+	SecureAttention() error
+	Lock() error
+	Logoff() error
+}
+
+// Unsupported 是没有系统命令能力时使用的 System，所有操作都返回 ErrUnsupported
+type Unsupported struct{}
+
+func (Unsupported) SecureAttention() error { return ErrUnsupported }
+func (Unsupported) Lock() error            { return ErrUnsupported }
+func (Unsupported) Logoff() error          { return ErrUnsupported }
+
+// shortcuts 是可以用按键组合实现的命令，按顺序按下后逆序释放
+var shortcuts = map[Command][]string{
+	TaskSwitcher: {"ControlLeft", "AltLeft", "Tab"},
+	ShowDesktop:  {"MetaLeft", "KeyD"},
+	TaskManager:  {"ControlLeft", "ShiftLeft", "Escape"},
+}
+
+// Runner 执行系统命令，快捷键通过 Injector 注入，其余交给 System
+type Runner struct {
+	system   System
+	injector input.Injector
+}
+
+func NewRunner(system System, injector input.Injector) *Runner {
+	return &Runner{
+		system:   system,
+		injector: injector,
+	}
+}
+
+// Run 执行 command
+func (runner *Runner) Run(command Command) error {
+	switch command {
+	case SecureAttention:
+		return runner.system.SecureAttention()
+	case Lock:
+		return runner.system.Lock()
+	case Logoff:
+		return runner.system.Logoff()
+	}
+
+	codes, ok := shortcuts[command]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknown, command)
+	}
+	return runner.press(codes)
+}
+
+// press 按顺序按下 codes 再逆序释放，按下失败时仍然释放已经按下的按键
+func (runner *Runner) press(codes []string) error {
+	var pressed []input.Key
+	var err error
+
+	for _, code := range codes {
+		key, _ := input.CodeKey(code)
+		if err = runner.injector.KeyDown(key); err != nil {
+			break
+		}
+		pressed = append(pressed, key)
+	}
+
+	errs := []error{err}
+	for i := len(pressed) - 1; i >= 0; i-- {
+		errs = append(errs, runner.injector.KeyUp(pressed[i]))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package command
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/input/inputtest"
+)
+
+type fakeSystem struct {
+	calls []string
+	err   error
+}
+
+func (system *fakeSystem) SecureAttention() error { return system.call("sas") }
+func (system *fakeSystem) Lock() error            { return system.call("lock") }
+func (system *fakeSystem) Logoff() error          { return system.call("logoff") }
+
+func (system *fakeSystem) call(name string) error {
+	system.calls = append(system.calls, name)
+	return system.err
+}
+
+func TestRunSystem(t *testing.T) {
+	system := &fakeSystem{}
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	runner := NewRunner(system, recorder)
+
+	for _, command := range []Command{SecureAttention, Lock, Logoff} {
+		if err := runner.Run(command); err != nil {
+			t.Fatalf("Run(%s): %v", command, err)
+		}
+	}
+
+	if len(system.calls) != 3 || system.calls[0] != "sas" || system.calls[1] != "lock" || system.calls[2] != "logoff" {
+		t.Errorf("system calls = %v", system.calls)
+	}
+	// 这些命令不能用按键模拟
+	recorder.Assert(t)
+}
+
+func TestRunShortcut(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	runner := NewRunner(&fakeSystem{}, recorder)
+
+	control := input.Key{Scancode: 0x1d}
+	shift := input.Key{Scancode: 0x2a}
+	escape := input.Key{Scancode: 0x01}
+
+	if err := runner.Run(TaskManager); err != nil {
+		t.Fatal(err)
+	}
+	recorder.Assert(t,
+		inputtest.KeyDown(control),
+		inputtest.KeyDown(shift),
+		inputtest.KeyDown(escape),
+		inputtest.KeyUp(escape),
+		inputtest.KeyUp(shift),
+		inputtest.KeyUp(control),
+	)
+
+	win := input.Key{Scancode: 0x5b, Extended: true}
+	d := input.Key{Scancode: 0x20}
+	if err := runner.Run(ShowDesktop); err != nil {
+		t.Fatal(err)
+	}
+	recorder.Assert(t, inputtest.KeyDown(win), inputtest.KeyDown(d), inputtest.KeyUp(d), inputtest.KeyUp(win))
+}
+
+func TestRunShortcutError(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	recorder.Err = errors.New("boom")
+	runner := NewRunner(&fakeSystem{}, recorder)
+
+	if err := runner.Run(TaskSwitcher); !errors.Is(err, recorder.Err) {
+		t.Errorf("Run(TaskSwitcher) = %v, want %v", err, recorder.Err)
+	}
+	// 第一个按键就失败了，不会继续按下其他按键
+	recorder.Assert(t, inputtest.KeyDown(input.Key{Scancode: 0x1d}))
+}
+
+func TestParse(t *testing.T) {
+	for _, name := range []string{"sas", "lock", "task_switcher", "show_desktop", "task_manager", "logoff"} {
+		if command, err := Parse(name); err != nil || string(command) != name {
+			t.Errorf("Parse(%q) = %q, %v", name, command, err)
+		}
+	}
+
+	if _, err := Parse("shutdown"); !errors.Is(err, ErrUnknown) {
+		t.Errorf("Parse(shutdown) = %v, want ErrUnknown", err)
+	}
+	if err := NewRunner(Unsupported{}, nil).Run("shutdown"); !errors.Is(err, ErrUnknown) {
+		t.Errorf("Run(shutdown) = %v, want ErrUnknown", err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package command
+
+// NewSystem 在 Windows 以外的平台上返回 Unsupported
+func NewSystem() System {
+	return Unsupported{}
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/m4n5ter/lindows/winapi"
+)
+
+// NewSystem 返回基于 SendSAS、LockWorkStation 和 ExitWindowsEx 的 System
+func NewSystem() System {
+	return windows{}
+}
+
+type windows struct{}
+
+// SecureAttention 调用 SendSAS，以服务运行时 AsUser 为 FALSE，否则为 TRUE
+func (windows) SecureAttention() error {
+	sessionID, err := winapi.ProcessIdToSessionId(uint32(os.Getpid()))
+	if err != nil {
+		return fmt.Errorf("ProcessIdToSessionId: %w", err)
+	}
+
+	if err := winapi.SendSAS(sessionID != 0); err != nil {
+		return fmt.Errorf("%w: SendSAS: %v", ErrUnsupported, err)
+	}
+	return nil
+}
+
+func (windows) Lock() error {
+	if err := winapi.LockWorkStation(); err != nil {
+		return fmt.Errorf("LockWorkStation: %w", err)
+	}
+	return nil
+}
+
+// Logoff 注销运行 lindows 的用户，以服务运行时没有交互式用户可以注销
+func (windows) Logoff() error {
+	if err := winapi.ExitWindowsEx(winapi.EWXLogoff, 0); err != nil {
+		return fmt.Errorf("ExitWindowsEx: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package desktop
+
+import (
+	"errors"
+
+	"github.com/m4n5ter/lindows/internal/desktop/command"
+	"github.com/m4n5ter/lindows/internal/session"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// RunCommand 执行会话请求的系统命令，会话必须拥有控制权和 system 权限
+func (manager *Manager) RunCommand(requester *session.Session, name string) error {
+	if !requester.IsController() || !requester.Can(session.PermissionSystem) {
+		manager.logger.Warn("System command denied", "session_id", requester.ID(), "command", name, "permissions", requester.Permissions())
+		return ErrPermissionDenied
+	}
+
+	cmd, err := command.Parse(name)
+	if err != nil {
+		return err
+	}
+
+	// 系统命令会影响所有人，都记录下来
+	manager.logger.Info("Running system command", "session_id", requester.ID(), "user", requester.User(), "command", cmd)
+	return manager.commands.Run(cmd)
+}
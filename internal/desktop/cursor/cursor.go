@@ -0,0 +1,332 @@
+// Package cursor 跟踪光标的形状和位置，让客户端在本地绘制光标
+package cursor
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+var ErrUnsupported = errors.New("cursor is not supported on this platform")
+
+// 缓存的光标形状数量上限，超过后清空缓存
+const maxCachedShapes = 64
+
+// Position 是光标在虚拟屏幕中的位置
+type Position struct {
+	X, Y          int // 相对虚拟屏幕左上角的像素坐标
+	Width, Height int // 虚拟屏幕的尺寸
+	Visible       bool
+}
+
+// Ratio 返回位置占屏幕的比例乘以 base，与客户端发送鼠标移动的格式一致
+func (position Position) Ratio(base int) (int, int) {
+	if position.Width <= 0 || position.Height <= 0 {
+		return 0, 0
+	}
+
+	return position.X * base / position.Width, position.Y * base / position.Height
+}
+
+// Crop 把位置换算成相对 region 的位置，region 与 X、Y 使用同一坐标系，光标在区域外时不可见
+func (position Position) Crop(region image.Rectangle) Position {
+	inside := image.Pt(position.X, position.Y).In(region)
+
+	return Position{
+		X:       position.X - region.Min.X,
+		Y:       position.Y - region.Min.Y,
+		Width:   region.Dx(),
+		Height:  region.Dy(),
+		Visible: position.Visible && inside,
+	}
+}
+
+// Shape 是编码为 PNG 的光标形状，Serial 在形状变化时递增，客户端可以按它缓存
+type Shape struct {
+	PNG     []byte
+	Hotspot image.Point
+	Serial  int
+}
+
+// Source 读取系统光标
+type Source interface {
+	// Current 返回当前光标的句柄和位置，句柄相同时形状相同
+	Current() (handle uintptr, position Position, err error)
+	// Shape 返回句柄对应的光标图像和热点
+	Shape(handle uintptr) (*image.NRGBA, image.Point, error)
+}
+
+// Watcher 定期轮询 Source，只在形状或位置变化时通知监听者
+type Watcher struct {
+	logger   *yalog.Logger
+	source   Source
+	interval time.Duration
+
+	mu       sync.Mutex
+	handle   uintptr
+	shape    Shape
+	position Position
+	cache    map[uintptr]Shape
+	serial   int
+
+	shapeListeners    []func(Shape)
+	positionListeners []func(Position)
+
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+func NewWatcher(source Source, interval time.Duration) *Watcher {
+	return &Watcher{
+		logger:   yalog.Default().With("module", "cursor"),
+		source:   source,
+		interval: interval,
+		cache:    make(map[uintptr]Shape),
+	}
+}
+
+// OnShape 注册形状变化的监听者
+func (watcher *Watcher) OnShape(listener func(Shape)) {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	watcher.shapeListeners = append(watcher.shapeListeners, listener)
+}
+
+// OnPosition 注册位置变化的监听者
+func (watcher *Watcher) OnPosition(listener func(Position)) {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	watcher.positionListeners = append(watcher.positionListeners, listener)
+}
+
+func (watcher *Watcher) Start() {
+	watcher.shutdown = make(chan struct{})
+	watcher.done = make(chan struct{})
+
+	go func() {
+		defer close(watcher.done)
+
+		ticker := time.NewTicker(watcher.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-watcher.shutdown:
+				return
+			case <-ticker.C:
+				watcher.poll()
+			}
+		}
+	}()
+}
+
+func (watcher *Watcher) Shutdown() {
+	if watcher.shutdown == nil {
+		return
+	}
+
+	close(watcher.shutdown)
+	<-watcher.done
+}
+
+// Shape 返回当前的光标形状，还没有读取到形状时 ok 为 false
+func (watcher *Watcher) Shape() (shape Shape, ok bool) {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	return watcher.shape, watcher.shape.PNG != nil
+}
+
+func (watcher *Watcher) Position() Position {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	return watcher.position
+}
+
+func (watcher *Watcher) poll() {
+	handle, position, err := watcher.source.Current()
+	if err != nil {
+		watcher.logger.Debug("Failed to get cursor", "error", err)
+		return
+	}
+
+	var shapeChanged bool
+	var shape Shape
+	if position.Visible && handle != 0 && handle != watcher.handle {
+		shape, shapeChanged = watcher.lookup(handle)
+	}
+
+	watcher.mu.Lock()
+	if shapeChanged {
+		watcher.handle = handle
+		shapeChanged = shape.Serial != watcher.shape.Serial
+		watcher.shape = shape
+	}
+	positionChanged := position != watcher.position
+	watcher.position = position
+	shapeListeners, positionListeners := watcher.shapeListeners, watcher.positionListeners
+	watcher.mu.Unlock()
+
+	if shapeChanged {
+		for _, listener := range shapeListeners {
+			listener(shape)
+		}
+	}
+	if positionChanged {
+		for _, listener := range positionListeners {
+			listener(position)
+		}
+	}
+}
+
+// lookup 返回句柄对应的形状，图像相同的不同句柄共用同一个 Serial
+func (watcher *Watcher) lookup(handle uintptr) (Shape, bool) {
+	if shape, ok := watcher.cache[handle]; ok {
+		return shape, true
+	}
+
+	img, hotspot, err := watcher.source.Shape(handle)
+	if err != nil {
+		watcher.logger.Debug("Failed to get cursor shape", "error", err)
+		return Shape{}, false
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		watcher.logger.Debug("Failed to encode cursor shape", "error", err)
+		return Shape{}, false
+	}
+
+	shape := Shape{PNG: buf.Bytes(), Hotspot: hotspot}
+	for _, cached := range watcher.cache {
+		if cached.Hotspot == hotspot && bytes.Equal(cached.PNG, shape.PNG) {
+			shape.Serial = cached.Serial
+			break
+		}
+	}
+	if shape.Serial == 0 {
+		watcher.serial++
+		shape.Serial = watcher.serial
+	}
+
+	if len(watcher.cache) >= maxCachedShapes {
+		clear(watcher.cache)
+	}
+	watcher.cache[handle] = shape
+
+	return shape, true
+}
+
+// FromColor 把 32 位 BGRA 的彩色光标转换为图像。
+//
+// 带 alpha 通道的光标直接使用 alpha；否则按 AND 掩码决定透明度，mask 同样是 32 位 BGRA，白色表示透明。
+func FromColor(bgra, mask []byte, width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	hasAlpha := false
+	for i := 3; i < len(bgra); i += 4 {
+		if bgra[i] != 0 {
+			hasAlpha = true
+			break
+		}
+	}
+
+	for i := 0; i < width*height*4 && i+3 < len(bgra); i += 4 {
+		a := bgra[i+3]
+		if !hasAlpha {
+			a = 0xFF
+			if i < len(mask) && mask[i] != 0 {
+				a = 0
+			}
+		}
+
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = bgra[i+2], bgra[i+1], bgra[i], a
+	}
+
+	return img
+}
+
+// FromMonochrome 把单色光标转换为图像。
+//
+// mask 是 32 位 BGRA，高度为 2*height：上半部分是 AND 掩码，下半部分是 XOR 掩码。
+// 反色像素无法用 PNG 表示，绘制为黑色。
+func FromMonochrome(mask []byte, width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	offset := width * height * 4
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := (y*width + x) * 4
+			if offset+i >= len(mask) {
+				return img
+			}
+
+			and, xor := mask[i] != 0, mask[offset+i] != 0
+			switch {
+			case !and && !xor:
+				img.SetNRGBA(x, y, color.NRGBA{A: 0xFF})
+			case !and && xor:
+				img.SetNRGBA(x, y, color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF})
+			case and && xor:
+				img.SetNRGBA(x, y, color.NRGBA{A: 0xFF})
+			}
+		}
+	}
+
+	return img
+}
+
+// Fake 是可以在测试中修改的 Source
+type Fake struct {
+	mu       sync.Mutex
+	handle   uintptr
+	position Position
+	shapes   map[uintptr]*image.NRGBA
+	Err      error
+}
+
+// Set 修改当前光标
+func (fake *Fake) Set(handle uintptr, position Position) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.handle, fake.position = handle, position
+}
+
+// SetShape 设置句柄对应的图像
+func (fake *Fake) SetShape(handle uintptr, img *image.NRGBA) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if fake.shapes == nil {
+		fake.shapes = make(map[uintptr]*image.NRGBA)
+	}
+	fake.shapes[handle] = img
+}
+
+func (fake *Fake) Current() (uintptr, Position, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return fake.handle, fake.position, fake.Err
+}
+
+func (fake *Fake) Shape(handle uintptr) (*image.NRGBA, image.Point, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	img, ok := fake.shapes[handle]
+	if !ok {
+		return nil, image.Point{}, errors.New("unknown cursor")
+	}
+	return img, image.Point{X: 1, Y: 2}, nil
+}
@@ -0,0 +1,151 @@
+package cursor
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestFromColor(t *testing.T) {
+	// 2x1：第一个像素是半透明红色，第二个完全透明
+	bgra := []byte{0, 0, 0xFF, 0x80, 0, 0, 0, 0}
+	img := FromColor(bgra, nil, 2, 1)
+
+	if got := img.NRGBAAt(0, 0); got != (color.NRGBA{R: 0xFF, A: 0x80}) {
+		t.Errorf("pixel 0 = %v", got)
+	}
+	if got := img.NRGBAAt(1, 0); got.A != 0 {
+		t.Errorf("pixel 1 = %v, want transparent", got)
+	}
+
+	// 没有 alpha 通道时由掩码决定透明度
+	bgra = []byte{0xFF, 0, 0, 0, 0, 0xFF, 0, 0}
+	mask := []byte{0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0}
+	img = FromColor(bgra, mask, 2, 1)
+
+	if got := img.NRGBAAt(0, 0); got != (color.NRGBA{B: 0xFF, A: 0xFF}) {
+		t.Errorf("masked pixel 0 = %v", got)
+	}
+	if got := img.NRGBAAt(1, 0); got.A != 0 {
+		t.Errorf("masked pixel 1 = %v, want transparent", got)
+	}
+}
+
+func TestFromMonochrome(t *testing.T) {
+	white := []byte{0xFF, 0xFF, 0xFF, 0}
+	black := []byte{0, 0, 0, 0}
+
+	// 4x1 的光标，AND 在上，XOR 在下
+	var mask []byte
+	for _, px := range [][]byte{black, black, white, white, black, white, black, white} {
+		mask = append(mask, px...)
+	}
+
+	img := FromMonochrome(mask, 4, 1)
+	want := []color.NRGBA{
+		{A: 0xFF},                            // AND=0 XOR=0：黑色
+		{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, // AND=0 XOR=1：白色
+		{},                                   // AND=1 XOR=0：透明
+		{A: 0xFF},                            // AND=1 XOR=1：反色，绘制为黑色
+	}
+	for x, w := range want {
+		if got := img.NRGBAAt(x, 0); got != w {
+			t.Errorf("pixel %d = %v, want %v", x, got, w)
+		}
+	}
+}
+
+func TestPositionRatio(t *testing.T) {
+	x, y := Position{X: 960, Y: 270, Width: 1920, Height: 1080}.Ratio(10000)
+	if x != 5000 || y != 2500 {
+		t.Errorf("Ratio = %d, %d, want 5000, 2500", x, y)
+	}
+
+	if x, y := (Position{X: 1}).Ratio(10000); x != 0 || y != 0 {
+		t.Errorf("Ratio of empty screen = %d, %d", x, y)
+	}
+}
+
+func TestPositionCrop(t *testing.T) {
+	region := image.Rect(1920, 0, 3200, 720)
+	position := Position{X: 2560, Y: 360, Width: 3840, Height: 1080, Visible: true}
+
+	want := Position{X: 640, Y: 360, Width: 1280, Height: 720, Visible: true}
+	if got := position.Crop(region); got != want {
+		t.Errorf("Crop = %+v, want %+v", got, want)
+	}
+
+	position.X = 100
+	if got := position.Crop(region); got.Visible {
+		t.Errorf("Crop outside region = %+v, want invisible", got)
+	}
+}
+
+func solid(c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestWatcher(t *testing.T) {
+	source := &Fake{}
+	source.SetShape(1, solid(color.NRGBA{R: 0xFF, A: 0xFF}))
+	source.SetShape(2, solid(color.NRGBA{G: 0xFF, A: 0xFF}))
+	// 句柄不同但图像相同
+	source.SetShape(3, solid(color.NRGBA{R: 0xFF, A: 0xFF}))
+
+	watcher := NewWatcher(source, 0)
+
+	var shapes []Shape
+	var positions []Position
+	watcher.OnShape(func(shape Shape) { shapes = append(shapes, shape) })
+	watcher.OnPosition(func(position Position) { positions = append(positions, position) })
+
+	position := Position{X: 10, Y: 20, Width: 100, Height: 100, Visible: true}
+	source.Set(1, position)
+	watcher.poll()
+	watcher.poll()
+
+	if len(shapes) != 1 || shapes[0].Serial != 1 || shapes[0].Hotspot != (image.Point{X: 1, Y: 2}) {
+		t.Fatalf("shapes = %+v, want one shape with serial 1", shapes)
+	}
+	img, err := png.Decode(bytes.NewReader(shapes[0].PNG))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r != 0xFFFF {
+		t.Errorf("decoded shape is not red")
+	}
+	if len(positions) != 1 {
+		t.Errorf("positions = %v, want one update", positions)
+	}
+
+	source.Set(2, position)
+	watcher.poll()
+	source.Set(3, position)
+	watcher.poll()
+	source.Set(1, position)
+	watcher.poll()
+
+	// 3 和 1 的图像相同，切换时不需要重新发送
+	if len(shapes) != 3 || shapes[1].Serial != 2 || shapes[2].Serial != 1 {
+		t.Errorf("got %d shapes, want serials 1, 2, 1", len(shapes))
+	}
+
+	// 隐藏光标时只更新位置，不读取形状
+	hidden := position
+	hidden.Visible = false
+	source.Set(0, hidden)
+	watcher.poll()
+
+	if len(positions) != 2 || positions[1].Visible {
+		t.Errorf("positions = %v, want a hidden update", positions)
+	}
+	if shape, ok := watcher.Shape(); !ok || shape.Serial != 1 {
+		t.Errorf("current shape = %+v, %v", shape, ok)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package cursor
+
+// NewSource 返回当前平台的光标实现
+func NewSource() Source {
+	return &Fake{Err: ErrUnsupported}
+}
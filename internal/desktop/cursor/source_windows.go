@@ -0,0 +1,105 @@
+package cursor
+
+import (
+	"fmt"
+	"image"
+	"runtime"
+	"unsafe"
+
+	"github.com/m4n5ter/lindows/winapi"
+)
+
+// NewSource 返回当前平台的光标实现
+func NewSource() Source {
+	return winSource{}
+}
+
+// winSource 通过 GetCursorInfo 和 GetIconInfo 读取系统光标
+type winSource struct{}
+
+func (winSource) Current() (uintptr, Position, error) {
+	info, err := winapi.GetCursorInfo()
+	if err != nil {
+		return 0, Position{}, fmt.Errorf("GetCursorInfo: %v", err)
+	}
+
+	// 坐标相对虚拟屏幕，与 gdigrab 捕获的区域一致
+	x := winapi.GetSystemMetrics(winapi.SMXVirtualScreen)
+	y := winapi.GetSystemMetrics(winapi.SMYVirtualScreen)
+
+	return uintptr(info.HCursor), Position{
+		X:       int(info.PtScreenPos.X - x),
+		Y:       int(info.PtScreenPos.Y - y),
+		Width:   int(winapi.GetSystemMetrics(winapi.SMCXVirtualScreen)),
+		Height:  int(winapi.GetSystemMetrics(winapi.SMCYVirtualScreen)),
+		Visible: info.Flags&winapi.CursorShowing != 0 && info.HCursor != 0,
+	}, nil
+}
+
+func (winSource) Shape(handle uintptr) (*image.NRGBA, image.Point, error) {
+	// GetDC 和 ReleaseDC 必须在同一线程调用
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	info, err := winapi.GetIconInfo(winapi.HICON(handle))
+	if err != nil {
+		return nil, image.Point{}, fmt.Errorf("GetIconInfo: %v", err)
+	}
+	defer winapi.DeleteObject(info.HbmMask)
+	if info.HbmColor != 0 {
+		defer winapi.DeleteObject(info.HbmColor)
+	}
+
+	hdc, err := winapi.GetDC(0)
+	if err != nil {
+		return nil, image.Point{}, fmt.Errorf("GetDC: %v", err)
+	}
+	defer winapi.ReleaseDC(0, hdc)
+
+	mask, width, height, err := readBitmap(hdc, info.HbmMask)
+	if err != nil {
+		return nil, image.Point{}, err
+	}
+
+	hotspot := image.Point{X: int(info.XHotspot), Y: int(info.YHotspot)}
+	if info.HbmColor == 0 {
+		return FromMonochrome(mask, width, height/2), hotspot, nil
+	}
+
+	bgra, width, height, err := readBitmap(hdc, info.HbmColor)
+	if err != nil {
+		return nil, image.Point{}, err
+	}
+	return FromColor(bgra, mask, width, height), hotspot, nil
+}
+
+// readBitmap 以自上而下的 32 位 BGRA 格式读取位图
+func readBitmap(hdc winapi.HDC, hbm winapi.HBITMAP) ([]byte, int, int, error) {
+	var bm winapi.Bitmap
+	if winapi.GetObjectW(hbm, uint32(unsafe.Sizeof(bm)), uintptr(unsafe.Pointer(&bm))) == 0 {
+		return nil, 0, 0, fmt.Errorf("GetObject failed")
+	}
+
+	width, height := bm.BmWidth, bm.BmHeight
+	if width <= 0 || height <= 0 {
+		return nil, 0, 0, fmt.Errorf("invalid cursor bitmap size %dx%d", width, height)
+	}
+
+	info := winapi.BITMAPINFO{
+		BmiHeader: winapi.BITMAPINFOHEADER{
+			BiSize:        uint32(unsafe.Sizeof(winapi.BITMAPINFOHEADER{})),
+			BiWidth:       width,
+			BiHeight:      -height, // 负数表示自上而下的位图
+			BiPlanes:      1,
+			BiBitCount:    32,
+			BiCompression: winapi.BIRGB,
+		},
+	}
+
+	buf := make([]byte, width*height*4)
+	if err := winapi.GetDIBits(hdc, hbm, 0, uint32(height), unsafe.Pointer(&buf[0]), &info, winapi.DIBRGBColors); err != nil {
+		return nil, 0, 0, fmt.Errorf("GetDIBits: %v", err)
+	}
+
+	return buf, int(width), int(height), nil
+}
@@ -0,0 +1,8 @@
+package desktop
+
+import "github.com/m4n5ter/lindows/internal/desktop/cursor"
+
+// Cursor 返回光标跟踪器，没有启用光标通道时为 nil
+func (manager *Manager) Cursor() *cursor.Watcher {
+	return manager.cursor
+}
@@ -0,0 +1,162 @@
+package input
+
+import (
+	"errors"
+	"image"
+	"sync/atomic"
+
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+var ErrUnhandled = errors.New("unhandled input event")
+
+// KeyMap 把旧版客户端 Event 枚举中的按键序号转换成 Key
+type KeyMap func(index uint8) (Key, bool)
+
+// Dispatcher 把一个会话在 key 和 mouse 通道的消息翻译成 Injector 调用，并记录该会话按住的按键
+type Dispatcher struct {
+	injector *Tracker
+	keys     KeyMap
+	keyboard KeyboardMode
+	region   image.Rectangle
+	scale    float64
+
+	vertical   wheelAccumulator
+	horizontal wheelAccumulator
+
+	// relative 为 true 时处于相对指针模式，绝对坐标的移动被忽略，避免指针在两种模式之间跳动
+	relative atomic.Bool
+
+	touches touches
+	stylus  pen
+
+	composition composition
+	keyCodes    keyCodes
+}
+
+func NewDispatcher(injector Injector, keys KeyMap) *Dispatcher {
+	return &Dispatcher{
+		injector: NewTracker(injector),
+		keys:     keys,
+	}
+}
+
+// SetRegion 设置视频画面对应的桌面区域和 DPI 缩放，见 Mapping
+func (dispatcher *Dispatcher) SetRegion(region image.Rectangle, scale float64) {
+	dispatcher.region = region
+	dispatcher.scale = scale
+}
+
+// SetKeyboardMode 设置 KeyCode 消息默认的翻译方式，客户端可以在消息中指定
+func (dispatcher *Dispatcher) SetKeyboardMode(mode KeyboardMode) {
+	dispatcher.keyboard = mode
+}
+
+// Relative 判断是否处于相对指针模式
+func (dispatcher *Dispatcher) Relative() bool {
+	return dispatcher.relative.Load()
+}
+
+// State 返回该会话当前按住的按键
+func (dispatcher *Dispatcher) State() State {
+	return dispatcher.injector.State()
+}
+
+// Release 释放该会话按住的所有按键和鼠标按钮，取消所有触点并抬起笔，然后退出相对指针模式并结束组字
+func (dispatcher *Dispatcher) Release() error {
+	dispatcher.relative.Store(false)
+	dispatcher.resetComposition()
+	dispatcher.resetKeyCodes()
+	return errors.Join(dispatcher.releasePointers(), dispatcher.injector.Release())
+}
+
+// Dispatch 注入一条客户端消息，不认识的事件返回 ErrUnhandled
+func (dispatcher *Dispatcher) Dispatch(msg message.Message) error {
+	if msg.Event < message.KeyCount {
+		key, ok := dispatcher.keys(msg.Event)
+		if !ok {
+			return ErrUnhandled
+		}
+
+		// p3 为 0 表示按下，1 表示释放
+		if msg.P3 == 0 {
+			return dispatcher.injector.KeyDown(key)
+		}
+		return dispatcher.injector.KeyUp(key)
+	}
+
+	switch msg.Event {
+	case message.MouseMove:
+		if dispatcher.Relative() {
+			return nil
+		}
+		// 坐标是占画面的比例乘以 message.Ratio，相当于画面尺寸为 Ratio x Ratio
+		point := dispatcher.mapping().Point(int(msg.P1), int(msg.P2), message.Ratio, message.Ratio)
+		return dispatcher.injector.MoveAbsolute(point.X, point.Y)
+	case message.MouseAbsolute:
+		if dispatcher.Relative() {
+			return nil
+		}
+		width, height := message.UnpackSize(msg.P3)
+		point := dispatcher.mapping().Point(int(msg.P1), int(msg.P2), width, height)
+		return dispatcher.injector.MoveAbsolute(point.X, point.Y)
+	case message.MouseLeftDown:
+		return dispatcher.injector.ButtonDown(ButtonLeft)
+	case message.MouseLeftUp:
+		return dispatcher.injector.ButtonUp(ButtonLeft)
+	case message.MouseRightDown:
+		return dispatcher.injector.ButtonDown(ButtonRight)
+	case message.MouseRightUp:
+		return dispatcher.injector.ButtonUp(ButtonRight)
+	case message.MouseMiddleDown:
+		return dispatcher.injector.ButtonDown(ButtonMiddle)
+	case message.MouseMiddleUp:
+		return dispatcher.injector.ButtonUp(ButtonMiddle)
+	case message.MouseXDown, message.MouseXUp:
+		// p1 为 1 表示 XBUTTON1，2 表示 XBUTTON2
+		button, ok := map[int32]Button{1: ButtonX1, 2: ButtonX2}[msg.P1]
+		if !ok {
+			return ErrUnhandled
+		}
+		if msg.Event == message.MouseXDown {
+			return dispatcher.injector.ButtonDown(button)
+		}
+		return dispatcher.injector.ButtonUp(button)
+	case message.MouseWheel:
+		// p1 和 p2 是 WheelEvent 的 deltaX 和 deltaY，以像素为单位
+		return dispatcher.wheel(msg.P1, msg.P2)
+	case message.MouseHWheel:
+		return dispatcher.wheel(msg.P1, 0)
+	case message.PointerLock:
+		dispatcher.relative.Store(msg.P1 != 0)
+		return nil
+	case message.MouseRelative:
+		// 相对移动不做缩放，游戏等程序需要原始的移动量；系统的指针加速仍然会生效
+		if msg.P1 == 0 && msg.P2 == 0 {
+			return nil
+		}
+		return dispatcher.injector.MoveRelative(int(msg.P1), int(msg.P2))
+	case message.Touch:
+		return dispatcher.touch(msg)
+	case message.Pen:
+		return dispatcher.pen(msg)
+	case message.KeyCode:
+		return dispatcher.keyCode(msg)
+	case message.TextInput:
+		return dispatcher.text(msg.P4, true)
+	case message.TextComposition:
+		return dispatcher.text(msg.P4, false)
+	case message.FocusLost:
+		return dispatcher.Release()
+	default:
+		return ErrUnhandled
+	}
+}
+
+func (dispatcher *Dispatcher) mapping() Mapping {
+	return Mapping{
+		Desktop: dispatcher.injector.Bounds(),
+		Region:  dispatcher.region,
+		Scale:   dispatcher.scale,
+	}
+}
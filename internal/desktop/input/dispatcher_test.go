@@ -0,0 +1,182 @@
+package input_test
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/input/inputtest"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+func keys(index uint8) (input.Key, bool) {
+	switch index {
+	case 0:
+		return input.Key{VK: 0x10}, true
+	case 1:
+		return input.Key{Scancode: 30}, true
+	default:
+		return input.Key{}, false
+	}
+}
+
+func TestDispatchMouse(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(-1920, 0, 1920, 1080))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	for _, msg := range []message.Message{
+		{Event: message.MouseMove, P1: 5000, P2: 5000},
+		{Event: message.MouseLeftDown},
+		{Event: message.MouseMove, P1: 0, P2: message.Ratio},
+		{Event: message.MouseLeftUp},
+		{Event: message.MouseRightDown},
+		{Event: message.MouseRightUp},
+		{Event: message.MouseMiddleDown},
+		{Event: message.MouseMiddleUp},
+		{Event: message.MouseWheel, P2: 100},
+		{Event: message.MouseWheel, P2: -100},
+		{Event: message.MouseWheel},
+	} {
+		if err := dispatcher.Dispatch(msg); err != nil {
+			t.Fatalf("Dispatch(%+v): %v", msg, err)
+		}
+	}
+
+	recorder.Assert(t,
+		inputtest.Move(0, 540),
+		inputtest.ButtonDown(input.ButtonLeft),
+		inputtest.Move(-1920, 1079),
+		inputtest.ButtonUp(input.ButtonLeft),
+		inputtest.ButtonDown(input.ButtonRight),
+		inputtest.ButtonUp(input.ButtonRight),
+		inputtest.ButtonDown(input.ButtonMiddle),
+		inputtest.ButtonUp(input.ButtonMiddle),
+		inputtest.Wheel(-input.WheelDelta),
+		inputtest.Wheel(input.WheelDelta),
+	)
+}
+
+func TestDispatchMoveClamped(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 50))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	_ = dispatcher.Dispatch(message.Message{Event: message.MouseMove, P1: -10, P2: 20000})
+	recorder.Assert(t, inputtest.Move(0, 49))
+}
+
+func TestDispatchKeys(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	shift := input.Key{VK: 0x10}
+	a := input.Key{Scancode: 30}
+
+	for _, msg := range []message.Message{
+		{Event: 0, P3: 0},
+		{Event: 1, P3: 0},
+		{Event: 1, P3: 1},
+		{Event: 0, P3: 1},
+	} {
+		if err := dispatcher.Dispatch(msg); err != nil {
+			t.Fatalf("Dispatch(%+v): %v", msg, err)
+		}
+	}
+
+	recorder.Assert(t,
+		inputtest.KeyDown(shift),
+		inputtest.KeyDown(a),
+		inputtest.KeyUp(a),
+		inputtest.KeyUp(shift),
+	)
+}
+
+func TestDispatchUnhandled(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	for _, event := range []uint8{2, message.Unidentified, message.CursorShape} {
+		if err := dispatcher.Dispatch(message.Message{Event: event}); !errors.Is(err, input.ErrUnhandled) {
+			t.Errorf("Dispatch(event %d) = %v, want ErrUnhandled", event, err)
+		}
+	}
+	recorder.Assert(t)
+}
+
+func TestDispatchError(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	recorder.Err = errors.New("boom")
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	if err := dispatcher.Dispatch(message.Message{Event: message.MouseLeftDown}); !errors.Is(err, recorder.Err) {
+		t.Errorf("Dispatch() = %v, want %v", err, recorder.Err)
+	}
+}
+
+func TestLegacyKeys(t *testing.T) {
+	for index, want := range map[uint8]input.Key{
+		0:   {VK: 0x10},       // VK_SHIFT
+		11:  {Scancode: 0x29}, // VK_SP1
+		23:  {Scancode: 0x01}, // VK_ESC
+		24:  {Scancode: 0x02}, // VK_1
+		197: {VK: 0xfe},       // VK_OEM_CLEAR
+	} {
+		if got, ok := input.LegacyKeys(index); !ok || got != want {
+			t.Errorf("LegacyKeys(%d) = %+v, %t, want %+v", index, got, ok, want)
+		}
+	}
+
+	// KEYEVENTF_KEYUP 和 KEYEVENTF_SCANCODE 在旧的按键表中占了位置，但不是按键
+	for _, index := range []uint8{9, 10, message.KeyCount} {
+		if got, ok := input.LegacyKeys(index); ok {
+			t.Errorf("LegacyKeys(%d) = %+v, want none", index, got)
+		}
+	}
+}
+
+func TestDispatchPointerLock(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 1000, 1000))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	for _, msg := range []message.Message{
+		{Event: message.MouseMove, P1: 5000, P2: 5000},
+		{Event: message.PointerLock, P1: 1},
+		{Event: message.MouseRelative, P1: 5, P2: -3},
+		// 相对模式下绝对坐标的移动被忽略
+		{Event: message.MouseMove, P1: 0, P2: 0},
+		{Event: message.MouseAbsolute, P1: 0, P2: 0, P3: message.PackSize(10, 10)},
+		{Event: message.MouseRelative},
+		{Event: message.MouseLeftDown},
+		{Event: message.MouseRelative, P1: -20, P2: 0},
+		{Event: message.MouseLeftUp},
+		{Event: message.PointerLock, P1: 0},
+		{Event: message.MouseMove, P1: 0, P2: 0},
+	} {
+		if err := dispatcher.Dispatch(msg); err != nil {
+			t.Fatalf("Dispatch(%+v): %v", msg, err)
+		}
+		if msg.Event == message.PointerLock && dispatcher.Relative() != (msg.P1 != 0) {
+			t.Fatalf("Relative() = %t after %+v", dispatcher.Relative(), msg)
+		}
+	}
+
+	recorder.Assert(t,
+		inputtest.Move(500, 500),
+		inputtest.MoveRelative(5, -3),
+		inputtest.ButtonDown(input.ButtonLeft),
+		inputtest.MoveRelative(-20, 0),
+		inputtest.ButtonUp(input.ButtonLeft),
+		inputtest.Move(0, 0),
+	)
+}
+
+func TestFocusLostLeavesPointerLock(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 1000, 1000))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	_ = dispatcher.Dispatch(message.Message{Event: message.PointerLock, P1: 1})
+	_ = dispatcher.Dispatch(message.Message{Event: message.FocusLost})
+	if dispatcher.Relative() {
+		t.Error("Relative() = true after focus lost")
+	}
+}
@@ -0,0 +1,405 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// uinput 和 evdev 的常量，见 linux/uinput.h 和 linux/input-event-codes.h
+const (
+	uiDevCreate  = 0x5501
+	uiDevDestroy = 0x5502
+	uiSetEvBit   = 0x40045564
+	uiSetKeyBit  = 0x40045565
+	uiSetRelBit  = 0x40045566
+	uiSetAbsBit  = 0x40045567
+
+	evSyn = 0x00
+	evKey = 0x01
+	evRel = 0x02
+	evAbs = 0x03
+
+	synReport = 0
+
+	relX           = 0x00
+	relY           = 0x01
+	relHWheel      = 0x06
+	relWheel       = 0x08
+	relWheelHiRes  = 0x0b
+	relHWheelHiRes = 0x0c
+
+	absX = 0x00
+	absY = 0x01
+
+	btnLeft   = 0x110
+	btnRight  = 0x111
+	btnMiddle = 0x112
+	btnSide   = 0x113
+	btnExtra  = 0x114
+
+	busVirtual = 0x06
+
+	// keyMax 是需要声明的最大键码，覆盖 evdev 的全部键盘按键
+	keyMax = 0x2ff
+)
+
+// absMax 是绝对坐标轴的最大值，由合成器缩放到实际屏幕
+const absMax = 65535
+
+// uinputUserDev 对应 struct uinput_user_dev
+type uinputUserDev struct {
+	Name         [80]byte
+	Bustype      uint16
+	Vendor       uint16
+	Product      uint16
+	Version      uint16
+	FFEffectsMax uint32
+	AbsMax       [64]int32
+	AbsMin       [64]int32
+	AbsFuzz      [64]int32
+	AbsFlat      [64]int32
+}
+
+// inputEvent 对应 struct input_event
+type inputEvent struct {
+	Time  syscall.Timeval
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// New 通过 /dev/uinput 创建虚拟的键盘鼠标和绝对坐标指针。
+//
+// 相对和绝对坐标分成两个设备，否则 libinput 会把它识别成触控板或数位板。
+func New() (Injector, error) {
+	pointer, err := openUinput("lindows pointer", func(fd uintptr) error {
+		for _, bit := range [][2]uintptr{
+			{uiSetEvBit, evKey}, {uiSetEvBit, evAbs},
+			{uiSetKeyBit, btnLeft}, {uiSetKeyBit, btnRight}, {uiSetKeyBit, btnMiddle},
+			{uiSetAbsBit, absX}, {uiSetAbsBit, absY},
+		} {
+			if err := ioctl(fd, bit[0], bit[1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := openUinput("lindows keyboard and mouse", func(fd uintptr) error {
+		for _, bit := range [][2]uintptr{
+			{uiSetEvBit, evKey}, {uiSetEvBit, evRel},
+			{uiSetRelBit, relX}, {uiSetRelBit, relY}, {uiSetRelBit, relWheel}, {uiSetRelBit, relHWheel},
+			{uiSetRelBit, relWheelHiRes}, {uiSetRelBit, relHWheelHiRes},
+		} {
+			if err := ioctl(fd, bit[0], bit[1]); err != nil {
+				return err
+			}
+		}
+		for code := uintptr(1); code <= keyMax; code++ {
+			if err := ioctl(fd, uiSetKeyBit, code); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		pointer.Close()
+		return nil, err
+	}
+
+	return &uinput{pointer: pointer, device: device}, nil
+}
+
+func openUinput(name string, setup func(fd uintptr) error) (*os.File, error) {
+	file, err := os.OpenFile("/dev/uinput", os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrUnsupported
+		}
+		return nil, fmt.Errorf("open uinput: %w", err)
+	}
+
+	if err := setup(file.Fd()); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("setup uinput: %w", err)
+	}
+
+	dev := uinputUserDev{
+		Bustype: busVirtual,
+		Vendor:  0x4c44,
+		Product: 0x0001,
+		Version: 1,
+	}
+	copy(dev.Name[:len(dev.Name)-1], name)
+	dev.AbsMax[absX] = absMax
+	dev.AbsMax[absY] = absMax
+
+	if _, err := file.Write(unsafe.Slice((*byte)(unsafe.Pointer(&dev)), unsafe.Sizeof(dev))); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("write uinput device: %w", err)
+	}
+
+	if err := ioctl(file.Fd(), uiDevCreate, 0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("create uinput device: %w", err)
+	}
+
+	return file, nil
+}
+
+func ioctl(fd, request, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// uinput 通过两个 uinput 设备注入事件，绝对坐标的范围固定为 0~65535
+type uinput struct {
+	pointer *os.File
+	device  *os.File
+
+	mu            sync.Mutex
+	wheel, hwheel int
+}
+
+func (injector *uinput) Bounds() image.Rectangle {
+	return image.Rect(0, 0, absMax+1, absMax+1)
+}
+
+func (injector *uinput) MoveAbsolute(x, y int) error {
+	return emit(injector.pointer,
+		inputEvent{Type: evAbs, Code: absX, Value: int32(min(max(x, 0), absMax))},
+		inputEvent{Type: evAbs, Code: absY, Value: int32(min(max(y, 0), absMax))},
+	)
+}
+
+func (injector *uinput) MoveRelative(dx, dy int) error {
+	return emit(injector.device,
+		inputEvent{Type: evRel, Code: relX, Value: int32(dx)},
+		inputEvent{Type: evRel, Code: relY, Value: int32(dy)},
+	)
+}
+
+func (injector *uinput) ButtonDown(button Button) error {
+	return injector.button(button, 1)
+}
+
+func (injector *uinput) ButtonUp(button Button) error {
+	return injector.button(button, 0)
+}
+
+func (injector *uinput) button(button Button, value int32) error {
+	code, ok := map[Button]uint16{
+		ButtonLeft:   btnLeft,
+		ButtonRight:  btnRight,
+		ButtonMiddle: btnMiddle,
+		ButtonX1:     btnSide,
+		ButtonX2:     btnExtra,
+	}[button]
+	if !ok {
+		return fmt.Errorf("unknown mouse button %d", button)
+	}
+
+	// 左中右键在绝对坐标设备上注入，保证点击落在最后一次绝对定位的位置
+	device := injector.pointer
+	if button == ButtonX1 || button == ButtonX2 {
+		device = injector.device
+	}
+	return emit(device, inputEvent{Type: evKey, Code: code, Value: value})
+}
+
+// Wheel 同时注入高精度滚动和累积满一格的普通滚动，高精度滚动的单位与 WheelDelta 相同
+func (injector *uinput) Wheel(delta int) error {
+	return injector.scroll(&injector.wheel, relWheel, relWheelHiRes, delta)
+}
+
+func (injector *uinput) HWheel(delta int) error {
+	return injector.scroll(&injector.hwheel, relHWheel, relHWheelHiRes, delta)
+}
+
+func (injector *uinput) scroll(accumulated *int, code, hiResCode uint16, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+
+	injector.mu.Lock()
+	*accumulated += delta
+	notches := *accumulated / WheelDelta
+	*accumulated -= notches * WheelDelta
+	injector.mu.Unlock()
+
+	events := []inputEvent{{Type: evRel, Code: hiResCode, Value: int32(delta)}}
+	if notches != 0 {
+		events = append(events, inputEvent{Type: evRel, Code: code, Value: int32(notches)})
+	}
+	return emit(injector.device, events...)
+}
+
+func (injector *uinput) KeyDown(key Key) error {
+	return injector.key(key, 1)
+}
+
+func (injector *uinput) KeyUp(key Key) error {
+	return injector.key(key, 0)
+}
+
+func (injector *uinput) key(key Key, value int32) error {
+	code, ok := evdevCode(key)
+	if !ok {
+		return fmt.Errorf("%w: key scancode %#x vk %#x", ErrUnsupported, key.Scancode, key.VK)
+	}
+	return emit(injector.device, inputEvent{Type: evKey, Code: code, Value: value})
+}
+
+// Text 需要按键盘布局反查按键，uinput 无法直接输入任意字符
+func (injector *uinput) Text(text string) error {
+	if text == "" {
+		return nil
+	}
+	return ErrUnsupported
+}
+
+// Touch 需要单独的多点触摸设备，暂不支持
+func (injector *uinput) Touch([]Contact) error { return ErrUnsupported }
+
+// Pen 需要单独的数位板设备，暂不支持
+func (injector *uinput) Pen(Pen) error { return ErrUnsupported }
+
+func (injector *uinput) Close() error {
+	for _, file := range []*os.File{injector.pointer, injector.device} {
+		_ = ioctl(file.Fd(), uiDevDestroy, 0)
+		file.Close()
+	}
+	return nil
+}
+
+// emit 写入事件并追加 SYN_REPORT
+func emit(file *os.File, events ...inputEvent) error {
+	events = append(events, inputEvent{Type: evSyn, Code: synReport})
+
+	size := int(unsafe.Sizeof(inputEvent{}))
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&events[0])), size*len(events))
+	if _, err := file.Write(buf); err != nil {
+		return fmt.Errorf("write uinput event: %w", err)
+	}
+	return nil
+}
+
+// evdevCode 把扫描码或虚拟键码转换成 evdev 键码
+func evdevCode(key Key) (uint16, bool) {
+	if key.Scancode != 0 {
+		if key.Extended {
+			code, ok := extendedScancodes[key.Scancode]
+			return code, ok
+		}
+		// set 1 扫描码在 0x58 (F12) 以内与 evdev 键码相同
+		if key.Scancode <= 0x58 {
+			return key.Scancode, true
+		}
+		return 0, false
+	}
+
+	code, ok := virtualKeys[key.VK]
+	return code, ok
+}
+
+// extendedScancodes 是带 E0 前缀的扫描码对应的 evdev 键码
+var extendedScancodes = map[uint16]uint16{
+	0x1c: 96,  // KEY_KPENTER
+	0x1d: 97,  // KEY_RIGHTCTRL
+	0x35: 98,  // KEY_KPSLASH
+	0x37: 99,  // KEY_SYSRQ
+	0x38: 100, // KEY_RIGHTALT
+	0x47: 102, // KEY_HOME
+	0x48: 103, // KEY_UP
+	0x49: 104, // KEY_PAGEUP
+	0x4b: 105, // KEY_LEFT
+	0x4d: 106, // KEY_RIGHT
+	0x4f: 107, // KEY_END
+	0x50: 108, // KEY_DOWN
+	0x51: 109, // KEY_PAGEDOWN
+	0x52: 110, // KEY_INSERT
+	0x53: 111, // KEY_DELETE
+	0x5b: 125, // KEY_LEFTMETA
+	0x5c: 126, // KEY_RIGHTMETA
+	0x5d: 127, // KEY_COMPOSE
+}
+
+// virtualKeys 是 Windows 虚拟键码对应的 evdev 键码，只包含按键表中以虚拟键码出现的按键
+var virtualKeys = map[uint16]uint16{
+	0x08: 14,  // VK_BACK -> KEY_BACKSPACE
+	0x10: 42,  // VK_SHIFT -> KEY_LEFTSHIFT
+	0x11: 29,  // VK_CONTROL -> KEY_LEFTCTRL
+	0x12: 56,  // VK_MENU -> KEY_LEFTALT
+	0x13: 119, // VK_PAUSE -> KEY_PAUSE
+	0x14: 58,  // VK_CAPITAL -> KEY_CAPSLOCK
+	0x21: 104, // VK_PRIOR -> KEY_PAGEUP
+	0x22: 109, // VK_NEXT -> KEY_PAGEDOWN
+	0x23: 107, // VK_END -> KEY_END
+	0x24: 102, // VK_HOME -> KEY_HOME
+	0x25: 105, // VK_LEFT -> KEY_LEFT
+	0x26: 103, // VK_UP -> KEY_UP
+	0x27: 106, // VK_RIGHT -> KEY_RIGHT
+	0x28: 108, // VK_DOWN -> KEY_DOWN
+	0x2c: 99,  // VK_SNAPSHOT -> KEY_SYSRQ
+	0x2d: 110, // VK_INSERT -> KEY_INSERT
+	0x2e: 111, // VK_DELETE -> KEY_DELETE
+	0x5b: 125, // VK_LWIN -> KEY_LEFTMETA
+	0x5c: 126, // VK_RWIN -> KEY_RIGHTMETA
+	0x5d: 127, // VK_APPS -> KEY_COMPOSE
+	0x7c: 183, // VK_F13 -> KEY_F13
+	0x7d: 184, // VK_F14
+	0x7e: 185, // VK_F15
+	0x7f: 186, // VK_F16
+	0x80: 187, // VK_F17
+	0x81: 188, // VK_F18
+	0x82: 189, // VK_F19
+	0x83: 190, // VK_F20
+	0x84: 191, // VK_F21
+	0x85: 192, // VK_F22
+	0x86: 193, // VK_F23
+	0x87: 194, // VK_F24
+	0x91: 70,  // VK_SCROLL -> KEY_SCROLLLOCK
+	0xa0: 42,  // VK_LSHIFT -> KEY_LEFTSHIFT
+	0xa1: 54,  // VK_RSHIFT -> KEY_RIGHTSHIFT
+	0xa2: 29,  // VK_LCONTROL -> KEY_LEFTCTRL
+	0xa3: 97,  // VK_RCONTROL -> KEY_RIGHTCTRL
+	0xa4: 56,  // VK_LMENU -> KEY_LEFTALT
+	0xa5: 100, // VK_RMENU -> KEY_RIGHTALT
+	0xa6: 158, // VK_BROWSER_BACK -> KEY_BACK
+	0xa7: 159, // VK_BROWSER_FORWARD -> KEY_FORWARD
+	0xa8: 173, // VK_BROWSER_REFRESH -> KEY_REFRESH
+	0xa9: 128, // VK_BROWSER_STOP -> KEY_STOP
+	0xaa: 217, // VK_BROWSER_SEARCH -> KEY_SEARCH
+	0xab: 364, // VK_BROWSER_FAVORITES -> KEY_FAVORITES
+	0xac: 172, // VK_BROWSER_HOME -> KEY_HOMEPAGE
+	0xad: 113, // VK_VOLUME_MUTE -> KEY_MUTE
+	0xae: 114, // VK_VOLUME_DOWN -> KEY_VOLUMEDOWN
+	0xaf: 115, // VK_VOLUME_UP -> KEY_VOLUMEUP
+	0xb0: 163, // VK_MEDIA_NEXT_TRACK -> KEY_NEXTSONG
+	0xb1: 165, // VK_MEDIA_PREV_TRACK -> KEY_PREVIOUSSONG
+	0xb2: 166, // VK_MEDIA_STOP -> KEY_STOPCD
+	0xb3: 164, // VK_MEDIA_PLAY_PAUSE -> KEY_PLAYPAUSE
+	0xb4: 155, // VK_LAUNCH_MAIL -> KEY_MAIL
+	0xba: 39,  // VK_OEM_1 -> KEY_SEMICOLON
+	0xbb: 13,  // VK_OEM_PLUS -> KEY_EQUAL
+	0xbc: 51,  // VK_OEM_COMMA -> KEY_COMMA
+	0xbd: 12,  // VK_OEM_MINUS -> KEY_MINUS
+	0xbe: 52,  // VK_OEM_PERIOD -> KEY_DOT
+	0xbf: 53,  // VK_OEM_2 -> KEY_SLASH
+	0xc0: 41,  // VK_OEM_3 -> KEY_GRAVE
+	0xdb: 26,  // VK_OEM_4 -> KEY_LEFTBRACE
+	0xdc: 43,  // VK_OEM_5 -> KEY_BACKSLASH
+	0xdd: 27,  // VK_OEM_6 -> KEY_RIGHTBRACE
+	0xde: 40,  // VK_OEM_7 -> KEY_APOSTROPHE
+	0xe2: 86,  // VK_OEM_102 -> KEY_102ND
+}
@@ -0,0 +1,8 @@
+//go:build !windows && !linux
+
+package input
+
+// New 在不支持的平台上返回 ErrUnsupported
+func New() (Injector, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,312 @@
+package input
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"unsafe"
+
+	"github.com/m4n5ter/lindows/winapi"
+)
+
+// New 返回基于 SendInput 的 Injector，触摸和笔分别通过触摸注入和合成指针设备注入
+func New() (Injector, error) {
+	return &sendInput{}, nil
+}
+
+type sendInput struct {
+	// 触摸注入和合成指针设备在第一次使用时才初始化，不使用触摸的会话不会在系统中留下触摸设备
+	touchOnce sync.Once
+	touchErr  error
+
+	penOnce sync.Once
+	pen     winapi.HSYNTHETICPOINTERDEVICE
+	penErr  error
+}
+
+func (*sendInput) Bounds() image.Rectangle {
+	x := int(winapi.GetSystemMetrics(winapi.SMXVirtualScreen))
+	y := int(winapi.GetSystemMetrics(winapi.SMYVirtualScreen))
+	width := int(winapi.GetSystemMetrics(winapi.SMCXVirtualScreen))
+	height := int(winapi.GetSystemMetrics(winapi.SMCYVirtualScreen))
+
+	return image.Rect(x, y, x+width, y+height)
+}
+
+// MoveAbsolute 把虚拟桌面上的像素坐标归一化到 0~65535 后注入
+func (injector *sendInput) MoveAbsolute(x, y int) error {
+	dx, dy := Normalize(image.Pt(x, y), injector.Bounds())
+
+	return mouse(winapi.MouseInput{
+		Dx:      dx,
+		Dy:      dy,
+		DwFlags: winapi.MouseEventFMove | winapi.MouseEventFABSolute | winapi.MouseEventFVirtualDesk,
+	})
+}
+
+func (*sendInput) MoveRelative(dx, dy int) error {
+	return mouse(winapi.MouseInput{
+		Dx:      int32(dx),
+		Dy:      int32(dy),
+		DwFlags: winapi.MouseEventFMove,
+	})
+}
+
+func (*sendInput) ButtonDown(button Button) error {
+	flags, data, err := buttonFlags(button, true)
+	if err != nil {
+		return err
+	}
+
+	return mouse(winapi.MouseInput{MouseData: data, DwFlags: flags})
+}
+
+func (*sendInput) ButtonUp(button Button) error {
+	flags, data, err := buttonFlags(button, false)
+	if err != nil {
+		return err
+	}
+
+	return mouse(winapi.MouseInput{MouseData: data, DwFlags: flags})
+}
+
+func buttonFlags(button Button, down bool) (flags, data uint32, err error) {
+	switch button {
+	case ButtonLeft:
+		flags = pick(down, winapi.MouseEventFLeftDown, winapi.MouseEventFLeftUp)
+	case ButtonRight:
+		flags = pick(down, winapi.MouseEventFRightDown, winapi.MouseEventFRightUp)
+	case ButtonMiddle:
+		flags = pick(down, winapi.MouseEventFMiddleDown, winapi.MouseEventFMiddleUp)
+	case ButtonX1:
+		flags, data = pick(down, winapi.MouseEventFXDown, winapi.MouseEventFXUp), winapi.XButton1
+	case ButtonX2:
+		flags, data = pick(down, winapi.MouseEventFXDown, winapi.MouseEventFXUp), winapi.XButton2
+	default:
+		return 0, 0, fmt.Errorf("unknown mouse button %d", button)
+	}
+
+	return flags, data, nil
+}
+
+func pick(down bool, downFlag, upFlag uint32) uint32 {
+	if down {
+		return downFlag
+	}
+	return upFlag
+}
+
+func (*sendInput) Wheel(delta int) error {
+	// mouseData 是有符号数，负数表示向下滚动
+	return mouse(winapi.MouseInput{MouseData: uint32(int32(delta)), DwFlags: winapi.MouseEventFWheel})
+}
+
+func (*sendInput) HWheel(delta int) error {
+	return mouse(winapi.MouseInput{MouseData: uint32(int32(delta)), DwFlags: winapi.MouseEventFHWheel})
+}
+
+func (*sendInput) KeyDown(key Key) error {
+	return keyboard(stroke{key: key})
+}
+
+func (*sendInput) KeyUp(key Key) error {
+	return keyboard(stroke{key: key, up: true})
+}
+
+// Text 以 KEYEVENTF_UNICODE 注入文本，系统合成 VK_PACKET，不受当前键盘布局影响
+func (*sendInput) Text(text string) error {
+	return keyboard(textStrokes(text)...)
+}
+
+// Touch 通过 InjectTouchInput 注入一帧触摸
+func (injector *sendInput) Touch(contacts []Contact) error {
+	injector.touchOnce.Do(func() {
+		if err := winapi.InitializeTouchInjection(MaxContacts, winapi.TouchFeedbackDefault); err != nil {
+			injector.touchErr = fmt.Errorf("%w: InitializeTouchInjection: %v", ErrUnsupported, err)
+		}
+	})
+	if injector.touchErr != nil {
+		return injector.touchErr
+	}
+
+	infos := make([]winapi.POINTER_TOUCH_INFO, len(contacts))
+	for i, contact := range contacts {
+		infos[i] = touchInfo(contact)
+	}
+
+	if err := winapi.InjectTouchInput(infos); err != nil {
+		return fmt.Errorf("InjectTouchInput: %w", err)
+	}
+	return nil
+}
+
+func touchInfo(contact Contact) winapi.POINTER_TOUCH_INFO {
+	info := winapi.POINTER_TOUCH_INFO{
+		PointerInfo: winapi.POINTER_INFO{
+			PointerType:     winapi.PTTouch,
+			PointerId:       contact.ID,
+			PtPixelLocation: winapi.POINT{X: int32(contact.X), Y: int32(contact.Y)},
+		},
+	}
+
+	switch contact.State {
+	case ContactDown:
+		info.PointerInfo.PointerFlags = winapi.PointerFlagDown | winapi.PointerFlagInRange | winapi.PointerFlagInContact
+	case ContactUpdate:
+		info.PointerInfo.PointerFlags = winapi.PointerFlagUpdate | winapi.PointerFlagInRange | winapi.PointerFlagInContact
+	case ContactUp:
+		info.PointerInfo.PointerFlags = winapi.PointerFlagUp
+	case ContactCancel:
+		info.PointerInfo.PointerFlags = winapi.PointerFlagUp | winapi.PointerFlagCanceled
+	}
+
+	if !contact.Area.Empty() {
+		info.TouchMask |= winapi.TouchMaskContactArea
+		info.RcContact = winapi.RECT{
+			Left:   int32(contact.Area.Min.X),
+			Top:    int32(contact.Area.Min.Y),
+			Right:  int32(contact.Area.Max.X),
+			Bottom: int32(contact.Area.Max.Y),
+		}
+	}
+	if contact.Pressure > 0 {
+		info.TouchMask |= winapi.TouchMaskPressure
+		info.Pressure = contact.Pressure
+	}
+
+	return info
+}
+
+// Pen 通过合成指针设备注入笔的输入，需要 Windows 10 1809 及以上
+func (injector *sendInput) Pen(pen Pen) error {
+	injector.penOnce.Do(func() {
+		device, err := winapi.CreateSyntheticPointerDevice(winapi.PTPen, 1, winapi.TouchFeedbackDefault)
+		if err != nil {
+			injector.penErr = fmt.Errorf("%w: CreateSyntheticPointerDevice: %v", ErrUnsupported, err)
+			return
+		}
+		injector.pen = device
+	})
+	if injector.penErr != nil {
+		return injector.penErr
+	}
+
+	info := []winapi.POINTER_TYPE_INFO_PEN{{Type: winapi.PTPen, PenInfo: penInfo(pen)}}
+	if err := winapi.InjectSyntheticPointerInput(injector.pen, info); err != nil {
+		return fmt.Errorf("InjectSyntheticPointerInput: %w", err)
+	}
+	return nil
+}
+
+func penInfo(pen Pen) winapi.POINTER_PEN_INFO {
+	info := winapi.POINTER_PEN_INFO{
+		PointerInfo: winapi.POINTER_INFO{
+			PointerType:     winapi.PTPen,
+			PtPixelLocation: winapi.POINT{X: int32(pen.X), Y: int32(pen.Y)},
+		},
+		PenMask:  winapi.PenMaskPressure | winapi.PenMaskRotation | winapi.PenMaskTiltX | winapi.PenMaskTiltY,
+		Pressure: pen.Pressure,
+		Rotation: pen.Rotation,
+		TiltX:    int32(pen.TiltX),
+		TiltY:    int32(pen.TiltY),
+	}
+
+	contact := winapi.PointerFlagInRange | winapi.PointerFlagInContact | winapi.PointerFlagFirstButton
+	switch pen.State {
+	case PenHover:
+		info.PointerInfo.PointerFlags = winapi.PointerFlagUpdate | winapi.PointerFlagInRange
+	case PenDown:
+		info.PointerInfo.PointerFlags = winapi.PointerFlagDown | contact
+	case PenMove:
+		info.PointerInfo.PointerFlags = winapi.PointerFlagUpdate | contact
+	case PenUp:
+		info.PointerInfo.PointerFlags = winapi.PointerFlagUp | winapi.PointerFlagInRange
+	case PenLeave:
+		// 没有 INRANGE 的更新表示笔离开了感应范围
+		info.PointerInfo.PointerFlags = winapi.PointerFlagUpdate
+	}
+
+	if pen.Barrel {
+		info.PenFlags |= winapi.PenFlagBarrel
+	}
+	if pen.Eraser {
+		info.PenFlags |= winapi.PenFlagInverted
+		if pen.State == PenDown || pen.State == PenMove {
+			info.PenFlags |= winapi.PenFlagEraser
+		}
+	}
+
+	return info
+}
+
+func (injector *sendInput) Close() error {
+	if injector.pen != 0 {
+		winapi.DestroySyntheticPointerDevice(injector.pen)
+		injector.pen = 0
+	}
+	return nil
+}
+
+// keyboard 在一次 SendInput 中注入所有击键，避免和用户的输入交错
+func keyboard(strokes ...stroke) error {
+	if len(strokes) == 0 {
+		return nil
+	}
+
+	inputs := make([]winapi.KInput, len(strokes))
+	for i, stroke := range strokes {
+		inputs[i] = winapi.KInput{
+			Type: winapi.InputKeyboard,
+			KI:   keyboardInput(stroke),
+		}
+	}
+
+	sent := winapi.SendInput(uint32(len(inputs)), unsafe.Pointer(&inputs[0]), uint32(unsafe.Sizeof(inputs[0])))
+	if int(sent) != len(inputs) {
+		return fmt.Errorf("SendInput failed: sent %d of %d keyboard inputs", sent, len(inputs))
+	}
+	return nil
+}
+
+func keyboardInput(stroke stroke) winapi.KeyboardInput {
+	var ki winapi.KeyboardInput
+
+	switch {
+	case stroke.unicode != 0:
+		ki.WScan = stroke.unicode
+		ki.DwFlags = winapi.KeyEventFUnicode
+
+	case stroke.key.Scancode != 0:
+		ki.WScan = stroke.key.Scancode
+		ki.DwFlags = winapi.KeyEventFScancode
+		if stroke.key.Extended {
+			ki.DwFlags |= winapi.KeyEventFExtendedKey
+		}
+
+	default:
+		// 按虚拟键码注入时同时填上扫描码，读取扫描码的程序（例如游戏）也能识别
+		ki.WVk = stroke.key.VK
+		scancode := winapi.MapVirtualKey(uint32(stroke.key.VK), winapi.MapVKVKToVSCEx)
+		ki.WScan = uint16(scancode & 0xff)
+		if scancode>>8 == 0xe0 || scancode>>8 == 0xe1 || extendedVK(stroke.key.VK) {
+			ki.DwFlags = winapi.KeyEventFExtendedKey
+		}
+	}
+
+	if stroke.up {
+		ki.DwFlags |= winapi.KeyEventFKeyUp
+	}
+	return ki
+}
+
+func mouse(mi winapi.MouseInput) error {
+	input := winapi.MInput{
+		Type: winapi.InputMouse,
+		MI:   mi,
+	}
+
+	if winapi.SendInput(1, unsafe.Pointer(&input), uint32(unsafe.Sizeof(input))) != 1 {
+		return fmt.Errorf("SendInput failed: flags %#x", mi.DwFlags)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Package input 定义与平台无关的键盘鼠标注入接口
+package input
+
+import (
+	"errors"
+	"image"
+)
+
+var ErrUnsupported = errors.New("input injection is not supported on this platform")
+
+// WheelDelta 是滚轮转动一格的量，与 Windows 的 WHEEL_DELTA 相同
+const WheelDelta = 120
+
+// Button 表示鼠标按键
+type Button uint8
+
+const (
+	ButtonLeft Button = iota
+	ButtonRight
+	ButtonMiddle
+	ButtonX1
+	ButtonX2
+)
+
+func (button Button) String() string {
+	switch button {
+	case ButtonLeft:
+		return "left"
+	case ButtonRight:
+		return "right"
+	case ButtonMiddle:
+		return "middle"
+	case ButtonX1:
+		return "x1"
+	case ButtonX2:
+		return "x2"
+	default:
+		return "unknown"
+	}
+}
+
+// Key 表示一个键盘按键，Scancode 不为 0 时优先按扫描码注入，否则按虚拟键码注入
+type Key struct {
+	// Scancode 是 PC/AT set 1 扫描码
+	Scancode uint16
+	// Extended 表示扫描码带 E0 前缀，例如方向键和右侧的 Ctrl/Alt
+	Extended bool
+	// VK 是 Windows 虚拟键码
+	VK uint16
+}
+
+// Injector 向系统注入键盘鼠标事件
+type Injector interface {
+	// Bounds 返回虚拟桌面的范围，多显示器时左上角可能是负数
+	Bounds() image.Rectangle
+	// MoveAbsolute 把指针移动到虚拟桌面上的像素坐标
+	MoveAbsolute(x, y int) error
+	// MoveRelative 按像素相对移动指针
+	MoveRelative(dx, dy int) error
+	ButtonDown(button Button) error
+	ButtonUp(button Button) error
+	// Wheel 垂直滚动，单位为 WheelDelta 的分数，正数表示向上
+	Wheel(delta int) error
+	// HWheel 水平滚动，单位为 WheelDelta 的分数，正数表示向右
+	HWheel(delta int) error
+	KeyDown(key Key) error
+	KeyUp(key Key) error
+	// Text 直接输入 Unicode 文本，不受键盘布局影响
+	Text(text string) error
+	// Touch 注入一帧触摸，contacts 必须包含当前所有活动的触点，见 Contact
+	Touch(contacts []Contact) error
+	// Pen 注入一次笔的状态
+	Pen(pen Pen) error
+	Close() error
+}
+
+// Unsupported 是没有注入能力时使用的 Injector，所有操作都返回 Err
+type Unsupported struct {
+	Err error
+}
+
+func (unsupported Unsupported) Bounds() image.Rectangle     { return image.Rectangle{} }
+func (unsupported Unsupported) MoveAbsolute(int, int) error { return unsupported.Err }
+func (unsupported Unsupported) MoveRelative(int, int) error { return unsupported.Err }
+func (unsupported Unsupported) ButtonDown(Button) error     { return unsupported.Err }
+func (unsupported Unsupported) ButtonUp(Button) error       { return unsupported.Err }
+func (unsupported Unsupported) Wheel(int) error             { return unsupported.Err }
+func (unsupported Unsupported) HWheel(int) error            { return unsupported.Err }
+func (unsupported Unsupported) KeyDown(Key) error           { return unsupported.Err }
+func (unsupported Unsupported) KeyUp(Key) error             { return unsupported.Err }
+func (unsupported Unsupported) Text(string) error           { return unsupported.Err }
+func (unsupported Unsupported) Touch([]Contact) error       { return unsupported.Err }
+func (unsupported Unsupported) Pen(Pen) error               { return unsupported.Err }
+func (unsupported Unsupported) Close() error                { return nil }
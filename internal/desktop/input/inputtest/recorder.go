@@ -0,0 +1,197 @@
+// Package inputtest 提供记录注入事件的 input.Injector，用于在测试中断言事件序列
+package inputtest
+
+import (
+	"fmt"
+	"image"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+)
+
+// Op 是被记录的操作类型
+type Op string
+
+const (
+	OpMove         Op = "move"
+	OpMoveRelative Op = "move_relative"
+	OpButtonDown   Op = "button_down"
+	OpButtonUp     Op = "button_up"
+	OpWheel        Op = "wheel"
+	OpHWheel       Op = "hwheel"
+	OpKeyDown      Op = "key_down"
+	OpKeyUp        Op = "key_up"
+	OpText         Op = "text"
+	OpTouch        Op = "touch"
+	OpPen          Op = "pen"
+)
+
+// Event 是一次被记录的注入操作，只有与 Op 相关的字段有值
+type Event struct {
+	Op     Op
+	X, Y   int
+	Button input.Button
+	Key    input.Key
+	Text   string
+	// Contacts 是 OpTouch 注入的一帧
+	Contacts []input.Contact
+	Pen      input.Pen
+}
+
+func (event Event) String() string {
+	switch event.Op {
+	case OpMove, OpMoveRelative:
+		return fmt.Sprintf("%s(%d, %d)", event.Op, event.X, event.Y)
+	case OpButtonDown, OpButtonUp:
+		return fmt.Sprintf("%s(%s)", event.Op, event.Button)
+	case OpWheel, OpHWheel:
+		return fmt.Sprintf("%s(%d)", event.Op, event.X)
+	case OpKeyDown, OpKeyUp:
+		return fmt.Sprintf("%s(scancode=%#x extended=%t vk=%#x)", event.Op, event.Key.Scancode, event.Key.Extended, event.Key.VK)
+	case OpText:
+		return fmt.Sprintf("%s(%q)", event.Op, event.Text)
+	case OpTouch:
+		parts := make([]string, len(event.Contacts))
+		for i, contact := range event.Contacts {
+			parts[i] = fmt.Sprintf("%d:%s(%d, %d area=%v pressure=%d)", contact.ID, contact.State, contact.X, contact.Y, contact.Area, contact.Pressure)
+		}
+		return fmt.Sprintf("%s[%s]", event.Op, strings.Join(parts, " "))
+	case OpPen:
+		pen := event.Pen
+		return fmt.Sprintf("%s(%s %d, %d pressure=%d tilt=%d,%d rotation=%d eraser=%t barrel=%t)",
+			event.Op, pen.State, pen.X, pen.Y, pen.Pressure, pen.TiltX, pen.TiltY, pen.Rotation, pen.Eraser, pen.Barrel)
+	default:
+		return string(event.Op)
+	}
+}
+
+func Move(x, y int) Event             { return Event{Op: OpMove, X: x, Y: y} }
+func MoveRelative(dx, dy int) Event   { return Event{Op: OpMoveRelative, X: dx, Y: dy} }
+func ButtonDown(b input.Button) Event { return Event{Op: OpButtonDown, Button: b} }
+func ButtonUp(b input.Button) Event   { return Event{Op: OpButtonUp, Button: b} }
+func Wheel(delta int) Event           { return Event{Op: OpWheel, X: delta} }
+func HWheel(delta int) Event          { return Event{Op: OpHWheel, X: delta} }
+func KeyDown(key input.Key) Event     { return Event{Op: OpKeyDown, Key: key} }
+func KeyUp(key input.Key) Event       { return Event{Op: OpKeyUp, Key: key} }
+func Text(text string) Event          { return Event{Op: OpText, Text: text} }
+func PenEvent(pen input.Pen) Event    { return Event{Op: OpPen, Pen: pen} }
+
+func Touch(contacts ...input.Contact) Event {
+	return Event{Op: OpTouch, Contacts: contacts}
+}
+
+// Recorder 记录所有注入的事件，Err 不为空时每个操作都返回它但仍然记录
+type Recorder struct {
+	bounds image.Rectangle
+
+	mu     sync.Mutex
+	events []Event
+	closed bool
+	Err    error
+}
+
+// NewRecorder 创建虚拟桌面范围为 bounds 的 Recorder
+func NewRecorder(bounds image.Rectangle) *Recorder {
+	return &Recorder{bounds: bounds}
+}
+
+func (recorder *Recorder) record(event Event) error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	recorder.events = append(recorder.events, event)
+	return recorder.Err
+}
+
+// Events 返回目前记录的所有事件
+func (recorder *Recorder) Events() []Event {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	return append([]Event(nil), recorder.events...)
+}
+
+// Reset 清空已经记录的事件
+func (recorder *Recorder) Reset() {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	recorder.events = nil
+}
+
+// Closed 判断 Close 是否被调用过
+func (recorder *Recorder) Closed() bool {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	return recorder.closed
+}
+
+// Assert 断言记录的事件序列与 want 完全一致，然后清空记录
+func (recorder *Recorder) Assert(t testing.TB, want ...Event) {
+	t.Helper()
+
+	got := recorder.Events()
+	recorder.Reset()
+
+	equal := len(got) == len(want)
+	for i := 0; equal && i < len(got); i++ {
+		equal = reflect.DeepEqual(got[i], want[i])
+	}
+	if !equal {
+		t.Errorf("unexpected input events\n got: %s\nwant: %s", format(got), format(want))
+	}
+}
+
+func format(events []Event) string {
+	if len(events) == 0 {
+		return "[]"
+	}
+
+	parts := make([]string, len(events))
+	for i, event := range events {
+		parts[i] = event.String()
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func (recorder *Recorder) Bounds() image.Rectangle { return recorder.bounds }
+
+func (recorder *Recorder) MoveAbsolute(x, y int) error { return recorder.record(Move(x, y)) }
+
+func (recorder *Recorder) MoveRelative(dx, dy int) error {
+	return recorder.record(MoveRelative(dx, dy))
+}
+
+func (recorder *Recorder) ButtonDown(button input.Button) error {
+	return recorder.record(ButtonDown(button))
+}
+
+func (recorder *Recorder) ButtonUp(button input.Button) error {
+	return recorder.record(ButtonUp(button))
+}
+
+func (recorder *Recorder) Wheel(delta int) error  { return recorder.record(Wheel(delta)) }
+func (recorder *Recorder) HWheel(delta int) error { return recorder.record(HWheel(delta)) }
+
+func (recorder *Recorder) KeyDown(key input.Key) error { return recorder.record(KeyDown(key)) }
+func (recorder *Recorder) KeyUp(key input.Key) error   { return recorder.record(KeyUp(key)) }
+
+func (recorder *Recorder) Text(text string) error { return recorder.record(Text(text)) }
+
+func (recorder *Recorder) Touch(contacts []input.Contact) error {
+	return recorder.record(Touch(append([]input.Contact(nil), contacts...)...))
+}
+
+func (recorder *Recorder) Pen(pen input.Pen) error { return recorder.record(PenEvent(pen)) }
+
+func (recorder *Recorder) Close() error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	recorder.closed = true
+	return nil
+}
@@ -0,0 +1,66 @@
+package input
+
+import "unicode/utf16"
+
+// 文本中需要按物理按键注入的字符
+var (
+	keyEnter = Key{Scancode: 0x1c}
+	keyTab   = Key{Scancode: 0x0f}
+)
+
+// stroke 是一次按下或释放，unicode 不为 0 时按 UTF-16 码元注入，忽略 key
+type stroke struct {
+	key     Key
+	unicode uint16
+	up      bool
+}
+
+// textStrokes 把文本拆成按下和释放的序列。
+//
+// 换行和制表符按 Enter 和 Tab 键注入，因为很多程序不处理 Unicode 的控制字符；
+// 其余控制字符被丢弃，BMP 之外的字符拆成两个代理码元依次注入。
+func textStrokes(text string) []stroke {
+	strokes := make([]stroke, 0, 2*len(text))
+	press := func(key Key) {
+		strokes = append(strokes, stroke{key: key}, stroke{key: key, up: true})
+	}
+
+	var previous rune
+	for _, r := range text {
+		switch {
+		case r == '\n' && previous == '\r':
+			// \r\n 只按一次 Enter
+		case r == '\r' || r == '\n':
+			press(keyEnter)
+		case r == '\t':
+			press(keyTab)
+		case r < 0x20 || r == 0x7f:
+		default:
+			for _, unit := range utf16.Encode([]rune{r}) {
+				strokes = append(strokes, stroke{unicode: unit}, stroke{unicode: unit, up: true})
+			}
+		}
+		previous = r
+	}
+
+	return strokes
+}
+
+// extendedVK 判断虚拟键码对应的按键是否带 E0 前缀，MapVirtualKey 不能区分的按键以这里为准
+func extendedVK(vk uint16) bool {
+	switch vk {
+	case 0x21, 0x22, 0x23, 0x24, // VK_PRIOR, VK_NEXT, VK_END, VK_HOME
+		0x25, 0x26, 0x27, 0x28, // VK_LEFT, VK_UP, VK_RIGHT, VK_DOWN
+		0x2c, 0x2d, 0x2e, // VK_SNAPSHOT, VK_INSERT, VK_DELETE
+		0x5b, 0x5c, 0x5d, // VK_LWIN, VK_RWIN, VK_APPS
+		0x6f,       // VK_DIVIDE
+		0x90,       // VK_NUMLOCK
+		0xa3, 0xa5, // VK_RCONTROL, VK_RMENU
+		0xa6, 0xa7, 0xa8, 0xa9, 0xaa, 0xab, 0xac, // VK_BROWSER_*
+		0xad, 0xae, 0xaf, 0xb0, 0xb1, 0xb2, 0xb3, // VK_VOLUME_*, VK_MEDIA_*
+		0xb4, 0xb5, 0xb6, 0xb7: // VK_LAUNCH_*
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,77 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func unicodeStrokes(units ...uint16) []stroke {
+	var strokes []stroke
+	for _, unit := range units {
+		strokes = append(strokes, stroke{unicode: unit}, stroke{unicode: unit, up: true})
+	}
+	return strokes
+}
+
+func pressStrokes(key Key) []stroke {
+	return []stroke{{key: key}, {key: key, up: true}}
+}
+
+func TestTextStrokes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []stroke
+	}{
+		{"empty", "", []stroke{}},
+		{"ascii", "hi", unicodeStrokes('h', 'i')},
+		{"chinese", "中文", unicodeStrokes(0x4e2d, 0x6587)},
+		{"german", "ß", unicodeStrokes(0xdf)},
+		{"emoji", "😀", unicodeStrokes(0xd83d, 0xde00)},
+		{"crlf", "a\r\nb", append(append(unicodeStrokes('a'), pressStrokes(keyEnter)...), unicodeStrokes('b')...)},
+		{"lf lf", "\n\n", append(pressStrokes(keyEnter), pressStrokes(keyEnter)...)},
+		{"tab", "\t", pressStrokes(keyTab)},
+		{"control", "\x00\x1b\x7f", []stroke{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := textStrokes(test.text); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("textStrokes(%q) = %+v, want %+v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestExtendedVK(t *testing.T) {
+	for vk, want := range map[uint16]bool{
+		0x25: true,  // VK_LEFT
+		0x2e: true,  // VK_DELETE
+		0xa3: true,  // VK_RCONTROL
+		0xa5: true,  // VK_RMENU
+		0x5b: true,  // VK_LWIN
+		0xa2: false, // VK_LCONTROL
+		0x41: false, // A
+		0x0d: false, // VK_RETURN
+	} {
+		if got := extendedVK(vk); got != want {
+			t.Errorf("extendedVK(%#x) = %t, want %t", vk, got, want)
+		}
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	for _, test := range []struct{ a, b, want string }{
+		{"", "abc", ""},
+		{"ni", "nih", "ni"},
+		{"nihao", "ni", "ni"},
+		{"かんじ", "漢字", ""},
+		// 「丫」(E4 B8 AB) 和「中」(E4 B8 AD) 的 UTF-8 编码前两个字节相同，不能只取相同的字节
+		{"丫头", "中国", ""},
+		{"中文", "中国", "中"},
+	} {
+		if got := commonPrefix(test.a, test.b); got != test.want {
+			t.Errorf("commonPrefix(%q, %q) = %q, want %q", test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package input
+
+// CodeKey 返回 W3C KeyboardEvent.code 对应的按键，code 表示按键的物理位置，与键盘布局无关。
+//
+// 扫描码与 Chromium 在 Windows 上使用的一致，见 https://www.w3.org/TR/uievents-code/
+func CodeKey(code string) (Key, bool) {
+	key, ok := codes[code]
+	return key, ok
+}
+
+func scancode(code uint16) Key { return Key{Scancode: code} }
+func extended(code uint16) Key { return Key{Scancode: code, Extended: true} }
+
+var codes = map[string]Key{
+	// 书写系统区
+	"Backquote":     scancode(0x29),
+	"Backslash":     scancode(0x2b),
+	"BracketLeft":   scancode(0x1a),
+	"BracketRight":  scancode(0x1b),
+	"Comma":         scancode(0x33),
+	"Digit0":        scancode(0x0b),
+	"Digit1":        scancode(0x02),
+	"Digit2":        scancode(0x03),
+	"Digit3":        scancode(0x04),
+	"Digit4":        scancode(0x05),
+	"Digit5":        scancode(0x06),
+	"Digit6":        scancode(0x07),
+	"Digit7":        scancode(0x08),
+	"Digit8":        scancode(0x09),
+	"Digit9":        scancode(0x0a),
+	"Equal":         scancode(0x0d),
+	"IntlBackslash": scancode(0x56), // ISO 键盘左 Shift 右侧的键
+	"IntlRo":        scancode(0x73), // JIS 键盘右 Shift 左侧的 ろ
+	"IntlYen":       scancode(0x7d), // JIS 键盘退格左侧的 ￥
+	"KeyA":          scancode(0x1e),
+	"KeyB":          scancode(0x30),
+	"KeyC":          scancode(0x2e),
+	"KeyD":          scancode(0x20),
+	"KeyE":          scancode(0x12),
+	"KeyF":          scancode(0x21),
+	"KeyG":          scancode(0x22),
+	"KeyH":          scancode(0x23),
+	"KeyI":          scancode(0x17),
+	"KeyJ":          scancode(0x24),
+	"KeyK":          scancode(0x25),
+	"KeyL":          scancode(0x26),
+	"KeyM":          scancode(0x32),
+	"KeyN":          scancode(0x31),
+	"KeyO":          scancode(0x18),
+	"KeyP":          scancode(0x19),
+	"KeyQ":          scancode(0x10),
+	"KeyR":          scancode(0x13),
+	"KeyS":          scancode(0x1f),
+	"KeyT":          scancode(0x14),
+	"KeyU":          scancode(0x16),
+	"KeyV":          scancode(0x2f),
+	"KeyW":          scancode(0x11),
+	"KeyX":          scancode(0x2d),
+	"KeyY":          scancode(0x15),
+	"KeyZ":          scancode(0x2c),
+	"Minus":         scancode(0x0c),
+	"Period":        scancode(0x34),
+	"Quote":         scancode(0x28),
+	"Semicolon":     scancode(0x27),
+	"Slash":         scancode(0x35),
+
+	// 功能区
+	"AltLeft":      scancode(0x38),
+	"AltRight":     extended(0x38),
+	"Backspace":    scancode(0x0e),
+	"CapsLock":     scancode(0x3a),
+	"ContextMenu":  extended(0x5d),
+	"ControlLeft":  scancode(0x1d),
+	"ControlRight": extended(0x1d),
+	"Enter":        scancode(0x1c),
+	"MetaLeft":     extended(0x5b),
+	"MetaRight":    extended(0x5c),
+	"ShiftLeft":    scancode(0x2a),
+	"ShiftRight":   scancode(0x36),
+	"Space":        scancode(0x39),
+	"Tab":          scancode(0x0f),
+	"Convert":      scancode(0x79), // 変換
+	"KanaMode":     scancode(0x70), // カタカナ/ひらがな
+	"Lang1":        scancode(0x72), // 한/영，Mac 的かな
+	"Lang2":        scancode(0x71), // 한자，Mac 的英数
+	"NonConvert":   scancode(0x7b), // 無変換
+
+	// 控制区和方向键
+	"Delete":     extended(0x53),
+	"End":        extended(0x4f),
+	"Home":       extended(0x47),
+	"Insert":     extended(0x52),
+	"PageDown":   extended(0x51),
+	"PageUp":     extended(0x49),
+	"ArrowDown":  extended(0x50),
+	"ArrowLeft":  extended(0x4b),
+	"ArrowRight": extended(0x4d),
+	"ArrowUp":    extended(0x48),
+
+	// 小键盘
+	"NumLock":        extended(0x45),
+	"Numpad0":        scancode(0x52),
+	"Numpad1":        scancode(0x4f),
+	"Numpad2":        scancode(0x50),
+	"Numpad3":        scancode(0x51),
+	"Numpad4":        scancode(0x4b),
+	"Numpad5":        scancode(0x4c),
+	"Numpad6":        scancode(0x4d),
+	"Numpad7":        scancode(0x47),
+	"Numpad8":        scancode(0x48),
+	"Numpad9":        scancode(0x49),
+	"NumpadAdd":      scancode(0x4e),
+	"NumpadComma":    scancode(0x7e),
+	"NumpadDecimal":  scancode(0x53),
+	"NumpadDivide":   extended(0x35),
+	"NumpadEnter":    extended(0x1c),
+	"NumpadEqual":    scancode(0x59),
+	"NumpadMultiply": scancode(0x37),
+	"NumpadSubtract": scancode(0x4a),
+
+	// 功能键
+	"Escape":      scancode(0x01),
+	"F1":          scancode(0x3b),
+	"F2":          scancode(0x3c),
+	"F3":          scancode(0x3d),
+	"F4":          scancode(0x3e),
+	"F5":          scancode(0x3f),
+	"F6":          scancode(0x40),
+	"F7":          scancode(0x41),
+	"F8":          scancode(0x42),
+	"F9":          scancode(0x43),
+	"F10":         scancode(0x44),
+	"F11":         scancode(0x57),
+	"F12":         scancode(0x58),
+	"F13":         scancode(0x64),
+	"F14":         scancode(0x65),
+	"F15":         scancode(0x66),
+	"F16":         scancode(0x67),
+	"F17":         scancode(0x68),
+	"F18":         scancode(0x69),
+	"F19":         scancode(0x6a),
+	"F20":         scancode(0x6b),
+	"F21":         scancode(0x6c),
+	"F22":         scancode(0x6d),
+	"F23":         scancode(0x6e),
+	"F24":         scancode(0x76),
+	"PrintScreen": extended(0x37),
+	"ScrollLock":  scancode(0x46),
+	// Pause 的扫描码是 E1 1D 45，无法用一个扫描码注入
+	"Pause": {VK: 0x13},
+
+	// 多媒体键
+	"AudioVolumeDown":    extended(0x2e),
+	"AudioVolumeMute":    extended(0x20),
+	"AudioVolumeUp":      extended(0x30),
+	"BrowserBack":        extended(0x6a),
+	"BrowserFavorites":   extended(0x66),
+	"BrowserForward":     extended(0x69),
+	"BrowserHome":        extended(0x32),
+	"BrowserRefresh":     extended(0x67),
+	"BrowserSearch":      extended(0x65),
+	"BrowserStop":        extended(0x68),
+	"LaunchApp1":         extended(0x6b),
+	"LaunchApp2":         extended(0x21),
+	"LaunchMail":         extended(0x6c),
+	"MediaPlayPause":     extended(0x22),
+	"MediaSelect":        extended(0x6d),
+	"MediaStop":          extended(0x24),
+	"MediaTrackNext":     extended(0x19),
+	"MediaTrackPrevious": extended(0x10),
+	"Power":              extended(0x5e),
+	"Sleep":              extended(0x5f),
+	"WakeUp":             extended(0x63),
+}
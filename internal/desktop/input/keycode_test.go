@@ -0,0 +1,219 @@
+package input_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/input/inputtest"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+var update = flag.Bool("update", false, "用测试结果更新 testdata 中的 golden 文件")
+
+// press 是客户端一次按键时浏览器报告的 KeyboardEvent.code 和 KeyboardEvent.key
+type press struct {
+	code, key string
+}
+
+// stroke 是按住 modifiers 时按下并释放 press
+type stroke struct {
+	modifiers []press
+	press
+}
+
+var (
+	shift   = press{"ShiftLeft", "Shift"}
+	control = press{"ControlLeft", "Control"}
+	// Windows 上的浏览器按 AltGr 时先报告一个 ControlLeft
+	altGraph = []press{{"ControlLeft", "Control"}, {"AltRight", "AltGraph"}}
+)
+
+func plain(code, key string) stroke { return stroke{press: press{code, key}} }
+
+func with(modifiers []press, code, key string) stroke {
+	return stroke{modifiers: modifiers, press: press{code, key}}
+}
+
+// layouts 是各个客户端键盘布局下浏览器报告的按键
+var layouts = map[string][]stroke{
+	"us": {
+		plain("KeyA", "a"),
+		with([]press{shift}, "KeyA", "A"),
+		plain("Digit2", "2"),
+		with([]press{shift}, "Digit2", "@"),
+		plain("Semicolon", ";"),
+		plain("Quote", "'"),
+		plain("Backquote", "`"),
+		plain("Space", " "),
+		plain("Enter", "Enter"),
+		plain("NumpadEnter", "Enter"),
+		plain("ArrowLeft", "ArrowLeft"),
+		plain("MetaLeft", "Meta"),
+		with([]press{control}, "KeyC", "c"),
+	},
+	"de": {
+		plain("KeyY", "z"),
+		plain("KeyZ", "y"),
+		plain("Semicolon", "ö"),
+		plain("Quote", "ä"),
+		plain("BracketLeft", "ü"),
+		plain("Minus", "ß"),
+		with([]press{shift}, "Digit2", "\""),
+		with([]press{shift}, "Digit7", "/"),
+		plain("IntlBackslash", "<"),
+		// ´ 是死键，与下一个按键组合成 é
+		plain("Equal", "Dead"),
+		plain("KeyE", "é"),
+		with(altGraph, "KeyQ", "@"),
+		with(altGraph, "KeyE", "€"),
+		// Ctrl+Z 撤销，德文布局上 Z 在 Y 的位置
+		with([]press{control}, "KeyY", "z"),
+	},
+	"ja": {
+		plain("BracketLeft", "@"),
+		with([]press{shift}, "Digit2", "\""),
+		plain("Equal", "^"),
+		plain("Quote", ":"),
+		plain("BracketRight", "["),
+		plain("Backslash", "]"),
+		plain("IntlYen", "\\"),
+		with([]press{shift}, "IntlYen", "|"),
+		plain("IntlRo", "\\"),
+		with([]press{shift}, "IntlRo", "_"),
+		plain("Backquote", "Zenkaku"),
+		plain("NonConvert", "NonConvert"),
+		plain("Convert", "Convert"),
+		plain("KanaMode", "KanaMode"),
+		with([]press{control}, "KeyA", "a"),
+	},
+}
+
+func keyCode(down bool, mode int32, p press) message.Message {
+	p4, _ := json.Marshal(message.Keyboard{Code: p.code, Key: p.key})
+
+	msg := message.Message{Event: message.KeyCode, P1: mode, P4: string(p4)}
+	if !down {
+		msg.P3 = 1
+	}
+	return msg
+}
+
+// translate 返回在 mode 下输入 stroke 注入的事件
+func translate(t *testing.T, mode int32, stroke stroke) string {
+	t.Helper()
+
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, nil)
+
+	var messages []message.Message
+	for _, modifier := range stroke.modifiers {
+		messages = append(messages, keyCode(true, mode, modifier))
+	}
+	messages = append(messages, keyCode(true, mode, stroke.press), keyCode(false, mode, stroke.press))
+	for i := len(stroke.modifiers) - 1; i >= 0; i-- {
+		messages = append(messages, keyCode(false, mode, stroke.modifiers[i]))
+	}
+
+	for _, msg := range messages {
+		if err := dispatcher.Dispatch(msg); err != nil {
+			t.Fatalf("Dispatch(%+v): %v", msg, err)
+		}
+	}
+	if state := dispatcher.State(); len(state.Keys) != 0 {
+		t.Errorf("%+v leaves keys pressed: %+v", stroke, state.Keys)
+	}
+
+	events := recorder.Events()
+	if len(events) == 0 {
+		return "(none)"
+	}
+	parts := make([]string, len(events))
+	for i, event := range events {
+		parts[i] = event.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestKeyCodeLayouts(t *testing.T) {
+	for name, strokes := range layouts {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			for _, stroke := range strokes {
+				var modifiers []string
+				for _, modifier := range stroke.modifiers {
+					modifiers = append(modifiers, modifier.code)
+				}
+				fmt.Fprintf(&b, "%s %q\n", strings.Join(append(modifiers, stroke.code), "+"), stroke.key)
+				fmt.Fprintf(&b, "\tpositional: %s\n", translate(t, message.KeyboardPositional, stroke))
+				fmt.Fprintf(&b, "\tsymbolic:   %s\n", translate(t, message.KeyboardSymbolic, stroke))
+			}
+
+			path := filepath.Join("testdata", "keyboard", name+".golden")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != string(want) {
+				t.Errorf("%s layout does not match %s, run go test -update and review the diff\n%s", name, path, got)
+			}
+		})
+	}
+}
+
+func TestKeyCodeDefaultMode(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, nil)
+	dispatcher.SetKeyboardMode(input.Symbolic)
+
+	a := press{"KeyQ", "a"}
+	_ = dispatcher.Dispatch(keyCode(true, message.KeyboardDefault, a))
+	_ = dispatcher.Dispatch(keyCode(false, message.KeyboardDefault, a))
+	recorder.Assert(t, inputtest.Text("a"))
+
+	// 在按下和释放之间切换方式，释放的仍然是按下的按键
+	_ = dispatcher.Dispatch(keyCode(true, message.KeyboardPositional, a))
+	_ = dispatcher.Dispatch(keyCode(false, message.KeyboardSymbolic, a))
+	q := input.Key{Scancode: 0x10}
+	recorder.Assert(t, inputtest.KeyDown(q), inputtest.KeyUp(q))
+}
+
+func TestKeyCodeInvalid(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, nil)
+
+	if err := dispatcher.Dispatch(keyCode(true, message.KeyboardPositional, press{"Fn", "Fn"})); !errors.Is(err, input.ErrUnhandled) {
+		t.Errorf("Dispatch(Fn) = %v, want ErrUnhandled", err)
+	}
+	if err := dispatcher.Dispatch(message.Message{Event: message.KeyCode, P4: "KeyA"}); !errors.Is(err, message.ErrInvalid) {
+		t.Errorf("Dispatch(invalid p4) = %v, want ErrInvalid", err)
+	}
+	recorder.Assert(t)
+}
+
+func TestParseKeyboardMode(t *testing.T) {
+	for _, mode := range []input.KeyboardMode{input.Positional, input.Symbolic} {
+		if got, err := input.ParseKeyboardMode(mode.String()); err != nil || got != mode {
+			t.Errorf("ParseKeyboardMode(%q) = %v, %v", mode, got, err)
+		}
+	}
+	if _, err := input.ParseKeyboardMode("azerty"); err == nil {
+		t.Error("ParseKeyboardMode(azerty) succeeded")
+	}
+}
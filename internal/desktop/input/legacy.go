@@ -0,0 +1,215 @@
+package input
+
+import "github.com/m4n5ter/lindows/internal/types/message"
+
+// LegacyKeys 是 KeyMap，翻译旧版客户端 Event 枚举中的按键序号。
+//
+// 枚举按顺序列出了 keybd_event 的按键表，不区分键盘布局，必须与客户端逐项一致，
+// 只为还没有发送 KeyCode 消息的客户端保留，不再添加新的按键。
+func LegacyKeys(index uint8) (Key, bool) {
+	if int(index) >= len(legacyKeys) || legacyKeys[index] == (Key{}) {
+		return Key{}, false
+	}
+	return legacyKeys[index], true
+}
+
+var legacyKeys = [message.KeyCount]Key{
+	{VK: 0x10},       // VK_SHIFT
+	{VK: 0x11},       // VK_CTRL
+	{VK: 0x12},       // VK_ALT
+	{VK: 0xa0},       // VK_LSHIFT
+	{VK: 0xa1},       // VK_RSHIFT
+	{VK: 0xa2},       // VK_LCONTROL
+	{VK: 0xa3},       // VK_RCONTROL
+	{VK: 0x5b},       // VK_LWIN
+	{VK: 0x5c},       // VK_RWIN
+	{},               // KEYEVENTF_KEYUP，不是按键
+	{},               // KEYEVENTF_SCANCODE，不是按键
+	{Scancode: 0x29}, // VK_SP1
+	{Scancode: 0x0c}, // VK_SP2
+	{Scancode: 0x0d}, // VK_SP3
+	{Scancode: 0x1a}, // VK_SP4
+	{Scancode: 0x1b}, // VK_SP5
+	{Scancode: 0x27}, // VK_SP6
+	{Scancode: 0x28}, // VK_SP7
+	{Scancode: 0x2b}, // VK_SP8
+	{Scancode: 0x33}, // VK_SP9
+	{Scancode: 0x34}, // VK_SP10
+	{Scancode: 0x35}, // VK_SP11
+	{Scancode: 0x56}, // VK_SP12
+	{Scancode: 0x01}, // VK_ESC
+	{Scancode: 0x02}, // VK_1
+	{Scancode: 0x03}, // VK_2
+	{Scancode: 0x04}, // VK_3
+	{Scancode: 0x05}, // VK_4
+	{Scancode: 0x06}, // VK_5
+	{Scancode: 0x07}, // VK_6
+	{Scancode: 0x08}, // VK_7
+	{Scancode: 0x09}, // VK_8
+	{Scancode: 0x0a}, // VK_9
+	{Scancode: 0x0b}, // VK_0
+	{Scancode: 0x10}, // VK_Q
+	{Scancode: 0x11}, // VK_W
+	{Scancode: 0x12}, // VK_E
+	{Scancode: 0x13}, // VK_R
+	{Scancode: 0x14}, // VK_T
+	{Scancode: 0x15}, // VK_Y
+	{Scancode: 0x16}, // VK_U
+	{Scancode: 0x17}, // VK_I
+	{Scancode: 0x18}, // VK_O
+	{Scancode: 0x19}, // VK_P
+	{Scancode: 0x1e}, // VK_A
+	{Scancode: 0x1f}, // VK_S
+	{Scancode: 0x20}, // VK_D
+	{Scancode: 0x21}, // VK_F
+	{Scancode: 0x22}, // VK_G
+	{Scancode: 0x23}, // VK_H
+	{Scancode: 0x24}, // VK_J
+	{Scancode: 0x25}, // VK_K
+	{Scancode: 0x26}, // VK_L
+	{Scancode: 0x2c}, // VK_Z
+	{Scancode: 0x2d}, // VK_X
+	{Scancode: 0x2e}, // VK_C
+	{Scancode: 0x2f}, // VK_V
+	{Scancode: 0x30}, // VK_B
+	{Scancode: 0x31}, // VK_N
+	{Scancode: 0x32}, // VK_M
+	{Scancode: 0x3b}, // VK_F1
+	{Scancode: 0x3c}, // VK_F2
+	{Scancode: 0x3d}, // VK_F3
+	{Scancode: 0x3e}, // VK_F4
+	{Scancode: 0x3f}, // VK_F5
+	{Scancode: 0x40}, // VK_F6
+	{Scancode: 0x41}, // VK_F7
+	{Scancode: 0x42}, // VK_F8
+	{Scancode: 0x43}, // VK_F9
+	{Scancode: 0x44}, // VK_F10
+	{Scancode: 0x57}, // VK_F11
+	{Scancode: 0x58}, // VK_F12
+	{VK: 0x7c},       // VK_F13
+	{VK: 0x7d},       // VK_F14
+	{VK: 0x7e},       // VK_F15
+	{VK: 0x7f},       // VK_F16
+	{VK: 0x80},       // VK_F17
+	{VK: 0x81},       // VK_F18
+	{VK: 0x82},       // VK_F19
+	{VK: 0x83},       // VK_F20
+	{VK: 0x84},       // VK_F21
+	{VK: 0x85},       // VK_F22
+	{VK: 0x86},       // VK_F23
+	{VK: 0x87},       // VK_F24
+	{Scancode: 0x45}, // VK_NUMLOCK
+	{Scancode: 0x46}, // VK_SCROLLLOCK
+	{Scancode: 0x00}, // VK_RESERVED
+	{Scancode: 0x0c}, // VK_MINUS
+	{Scancode: 0x0d}, // VK_EQUAL
+	{Scancode: 0x0e}, // VK_BACKSPACE
+	{Scancode: 0x0f}, // VK_TAB
+	{Scancode: 0x1a}, // VK_LEFTBRACE
+	{Scancode: 0x1b}, // VK_RIGHTBRACE
+	{Scancode: 0x1c}, // VK_ENTER
+	{Scancode: 0x27}, // VK_SEMICOLON
+	{Scancode: 0x28}, // VK_APOSTROPHE
+	{Scancode: 0x29}, // VK_GRAVE
+	{Scancode: 0x2b}, // VK_BACKSLASH
+	{Scancode: 0x33}, // VK_COMMA
+	{Scancode: 0x34}, // VK_DOT
+	{Scancode: 0x35}, // VK_SLASH
+	{Scancode: 0x37}, // VK_KPASTERISK
+	{Scancode: 0x39}, // VK_SPACE
+	{Scancode: 0x3a}, // VK_CAPSLOCK
+	{Scancode: 0x52}, // VK_KP0
+	{Scancode: 0x4f}, // VK_KP1
+	{Scancode: 0x50}, // VK_KP2
+	{Scancode: 0x51}, // VK_KP3
+	{Scancode: 0x4b}, // VK_KP4
+	{Scancode: 0x4c}, // VK_KP5
+	{Scancode: 0x4d}, // VK_KP6
+	{Scancode: 0x47}, // VK_KP7
+	{Scancode: 0x48}, // VK_KP8
+	{Scancode: 0x49}, // VK_KP9
+	{Scancode: 0x4a}, // VK_KPMINUS
+	{Scancode: 0x4e}, // VK_KPPLUS
+	{Scancode: 0x53}, // VK_KPDOT
+	{VK: 0x01},       // VK_LBUTTON
+	{VK: 0x02},       // VK_RBUTTON
+	{VK: 0x03},       // VK_CANCEL
+	{VK: 0x04},       // VK_MBUTTON
+	{VK: 0x05},       // VK_XBUTTON1
+	{VK: 0x06},       // VK_XBUTTON2
+	{VK: 0x08},       // VK_BACK
+	{VK: 0x0c},       // VK_CLEAR
+	{VK: 0x13},       // VK_PAUSE
+	{VK: 0x14},       // VK_CAPITAL
+	{VK: 0x15},       // VK_KANA
+	{VK: 0x15},       // VK_HANGUEL
+	{VK: 0x15},       // VK_HANGUL
+	{VK: 0x17},       // VK_JUNJA
+	{VK: 0x18},       // VK_FINAL
+	{VK: 0x19},       // VK_HANJA
+	{VK: 0x19},       // VK_KANJI
+	{VK: 0x1c},       // VK_CONVERT
+	{VK: 0x1d},       // VK_NONCONVERT
+	{VK: 0x1e},       // VK_ACCEPT
+	{VK: 0x1f},       // VK_MODECHANGE
+	{VK: 0x21},       // VK_PAGEUP
+	{VK: 0x22},       // VK_PAGEDOWN
+	{VK: 0x23},       // VK_END
+	{VK: 0x24},       // VK_HOME
+	{VK: 0x25},       // VK_LEFT
+	{VK: 0x26},       // VK_UP
+	{VK: 0x27},       // VK_RIGHT
+	{VK: 0x28},       // VK_DOWN
+	{VK: 0x29},       // VK_SELECT
+	{VK: 0x2a},       // VK_PRINT
+	{VK: 0x2b},       // VK_EXECUTE
+	{VK: 0x2c},       // VK_SNAPSHOT
+	{VK: 0x2d},       // VK_INSERT
+	{VK: 0x2e},       // VK_DELETE
+	{VK: 0x2f},       // VK_HELP
+	{VK: 0x91},       // VK_SCROLL
+	{VK: 0xa4},       // VK_LMENU
+	{VK: 0xa5},       // VK_RMENU
+	{VK: 0xa6},       // VK_BROWSER_BACK
+	{VK: 0xa7},       // VK_BROWSER_FORWARD
+	{VK: 0xa8},       // VK_BROWSER_REFRESH
+	{VK: 0xa9},       // VK_BROWSER_STOP
+	{VK: 0xaa},       // VK_BROWSER_SEARCH
+	{VK: 0xab},       // VK_BROWSER_FAVORITES
+	{VK: 0xac},       // VK_BROWSER_HOME
+	{VK: 0xad},       // VK_VOLUME_MUTE
+	{VK: 0xae},       // VK_VOLUME_DOWN
+	{VK: 0xaf},       // VK_VOLUME_UP
+	{VK: 0xb0},       // VK_MEDIA_NEXT_TRACK
+	{VK: 0xb1},       // VK_MEDIA_PREV_TRACK
+	{VK: 0xb2},       // VK_MEDIA_STOP
+	{VK: 0xb3},       // VK_MEDIA_PLAY_PAUSE
+	{VK: 0xb4},       // VK_LAUNCH_MAIL
+	{VK: 0xb5},       // VK_LAUNCH_MEDIA_SELECT
+	{VK: 0xb6},       // VK_LAUNCH_APP1
+	{VK: 0xb7},       // VK_LAUNCH_APP2
+	{VK: 0xba},       // VK_OEM_1
+	{VK: 0xbb},       // VK_OEM_PLUS
+	{VK: 0xbc},       // VK_OEM_COMMA
+	{VK: 0xbd},       // VK_OEM_MINUS
+	{VK: 0xbe},       // VK_OEM_PERIOD
+	{VK: 0xbf},       // VK_OEM_2
+	{VK: 0xc0},       // VK_OEM_3
+	{VK: 0xdb},       // VK_OEM_4
+	{VK: 0xdc},       // VK_OEM_5
+	{VK: 0xdd},       // VK_OEM_6
+	{VK: 0xde},       // VK_OEM_7
+	{VK: 0xdf},       // VK_OEM_8
+	{VK: 0xe2},       // VK_OEM_102
+	{VK: 0xe5},       // VK_PROCESSKEY
+	{VK: 0xe7},       // VK_PACKET
+	{VK: 0xf6},       // VK_ATTN
+	{VK: 0xf7},       // VK_CRSEL
+	{VK: 0xf8},       // VK_EXSEL
+	{VK: 0xf9},       // VK_EREOF
+	{VK: 0xfa},       // VK_PLAY
+	{VK: 0xfb},       // VK_ZOOM
+	{VK: 0xfc},       // VK_NONAME
+	{VK: 0xfd},       // VK_PA1
+	{VK: 0xfe},       // VK_OEM_CLEAR
+}
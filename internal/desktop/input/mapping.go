@@ -0,0 +1,80 @@
+package input
+
+import (
+	"image"
+	"math"
+)
+
+// normalizedRange 是 MOUSEEVENTF_ABSOLUTE 归一化坐标的范围，坐标取值为 0~65535
+const normalizedRange = 65536
+
+// Mapping 描述客户端看到的视频画面和虚拟桌面之间的关系。
+//
+// 视频画面是 Region 区域的内容，Region 以物理像素表示；Desktop 是注入使用的坐标系，
+// 进程没有感知 DPI 时它是系统缩放后的逻辑像素，物理像素除以 Scale 得到逻辑像素。
+type Mapping struct {
+	// Desktop 是虚拟桌面在注入坐标系中的范围，通常是 Injector.Bounds()
+	Desktop image.Rectangle
+	// Region 是视频画面对应的桌面区域，以物理像素表示，为空时表示整个虚拟桌面
+	Region image.Rectangle
+	// Scale 是物理像素与注入坐标的比例，例如系统缩放为 150% 时为 1.5，0 表示 1
+	Scale float64
+}
+
+// Point 把客户端视频画面上的坐标换算成 Desktop 上的坐标。
+//
+// width 和 height 是客户端渲染的视频尺寸，(x, y) 取该像素的中心换算，
+// 所以视频缩小或放大显示时点击仍然落在对应区域的中间；超出画面的坐标会被限制在边缘。
+func (mapping Mapping) Point(x, y, width, height int) image.Point {
+	if width <= 0 || height <= 0 || mapping.Desktop.Empty() {
+		return mapping.Desktop.Min
+	}
+
+	x = min(max(x, 0), width-1)
+	y = min(max(y, 0), height-1)
+
+	minX, minY, regionWidth, regionHeight := mapping.region()
+	point := image.Point{
+		X: int(math.Floor(minX + (float64(x)+0.5)*regionWidth/float64(width))),
+		Y: int(math.Floor(minY + (float64(y)+0.5)*regionHeight/float64(height))),
+	}
+
+	return image.Point{
+		X: min(max(point.X, mapping.Desktop.Min.X), mapping.Desktop.Max.X-1),
+		Y: min(max(point.Y, mapping.Desktop.Min.Y), mapping.Desktop.Max.Y-1),
+	}
+}
+
+// region 返回视频画面在 Desktop 坐标系中的位置和大小
+func (mapping Mapping) region() (x, y, width, height float64) {
+	if mapping.Region.Empty() {
+		desktop := mapping.Desktop
+		return float64(desktop.Min.X), float64(desktop.Min.Y), float64(desktop.Dx()), float64(desktop.Dy())
+	}
+
+	scale := mapping.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+
+	region := mapping.Region
+	return float64(region.Min.X) / scale, float64(region.Min.Y) / scale,
+		float64(region.Dx()) / scale, float64(region.Dy()) / scale
+}
+
+// Normalize 把 desktop 上的坐标换算成 MOUSEEVENTF_ABSOLUTE|MOUSEEVENTF_VIRTUALDESK 使用的 0~65535。
+//
+// 系统把归一化坐标 n 映射到像素 n*width/65536 并向下取整，所以这里向上取整，保证注入后正好落在 p 上。
+func Normalize(p image.Point, desktop image.Rectangle) (int32, int32) {
+	return normalize(p.X-desktop.Min.X, desktop.Dx()), normalize(p.Y-desktop.Min.Y, desktop.Dy())
+}
+
+func normalize(offset, size int) int32 {
+	if size <= 0 {
+		return 0
+	}
+
+	offset = min(max(offset, 0), size-1)
+	n := (offset*normalizedRange + size - 1) / size
+	return int32(min(n, normalizedRange-1))
+}
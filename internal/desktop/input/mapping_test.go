@@ -0,0 +1,228 @@
+package input_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/input/inputtest"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+// denormalize 模拟系统把归一化坐标映射回像素的方式
+func denormalize(n int32, desktop image.Rectangle, vertical bool) int {
+	if vertical {
+		return desktop.Min.Y + int(n)*desktop.Dy()/65536
+	}
+	return desktop.Min.X + int(n)*desktop.Dx()/65536
+}
+
+func TestNormalizeRoundTrip(t *testing.T) {
+	for _, width := range []int{1, 2, 3, 640, 1024, 1280, 1366, 1920, 2560, 3440, 3840, 5760, 7680, 32768, 65536} {
+		desktop := image.Rect(0, 0, width, 1)
+
+		previous := int32(-1)
+		for x := 0; x < width; x++ {
+			n, _ := input.Normalize(image.Pt(x, 0), desktop)
+			if n < 0 || n > 65535 {
+				t.Fatalf("width %d: Normalize(%d) = %d, out of range", width, x, n)
+			}
+			if n <= previous {
+				t.Fatalf("width %d: Normalize(%d) = %d, not increasing after %d", width, x, n, previous)
+			}
+			if got := denormalize(n, desktop, false); got != x {
+				t.Fatalf("width %d: Normalize(%d) = %d, lands on pixel %d", width, x, n, got)
+			}
+			previous = n
+		}
+	}
+}
+
+func TestNormalizeVirtualDesktop(t *testing.T) {
+	// 左侧显示器在主显示器左边，虚拟桌面左上角是负数
+	desktop := image.Rect(-1920, -200, 1920, 1080)
+
+	tests := []struct {
+		point  image.Point
+		nx, ny int32
+	}{
+		{image.Pt(-1920, -200), 0, 0},
+		{image.Pt(0, 0), 32768, 10240},
+		{image.Pt(1919, 1079), 65519, 65485},
+		// 超出虚拟桌面的坐标被限制在边缘
+		{image.Pt(-5000, -5000), 0, 0},
+		{image.Pt(5000, 5000), 65519, 65485},
+	}
+
+	for _, test := range tests {
+		nx, ny := input.Normalize(test.point, desktop)
+		if nx != test.nx || ny != test.ny {
+			t.Errorf("Normalize(%v) = %d, %d, want %d, %d", test.point, nx, ny, test.nx, test.ny)
+		}
+
+		want := image.Pt(min(max(test.point.X, -1920), 1919), min(max(test.point.Y, -200), 1079))
+		if got := image.Pt(denormalize(nx, desktop, false), denormalize(ny, desktop, true)); got != want {
+			t.Errorf("Normalize(%v) lands on %v, want %v", test.point, got, want)
+		}
+	}
+}
+
+func TestNormalizeEmptyDesktop(t *testing.T) {
+	if nx, ny := input.Normalize(image.Pt(10, 10), image.Rectangle{}); nx != 0 || ny != 0 {
+		t.Errorf("Normalize on empty desktop = %d, %d, want 0, 0", nx, ny)
+	}
+}
+
+func TestMappingPoint(t *testing.T) {
+	tests := []struct {
+		name          string
+		mapping       input.Mapping
+		x, y          int
+		width, height int
+		want          image.Point
+	}{
+		{
+			name:    "identity",
+			mapping: input.Mapping{Desktop: image.Rect(0, 0, 1920, 1080)},
+			x:       1234, y: 567, width: 1920, height: 1080,
+			want: image.Pt(1234, 567),
+		},
+		{
+			name:    "identity last pixel",
+			mapping: input.Mapping{Desktop: image.Rect(0, 0, 1920, 1080)},
+			x:       1919, y: 1079, width: 1920, height: 1080,
+			want: image.Pt(1919, 1079),
+		},
+		{
+			// 每个视频像素对应 3x3 的桌面像素，取中间的那个
+			name:    "video scaled down",
+			mapping: input.Mapping{Desktop: image.Rect(0, 0, 1920, 1080)},
+			x:       0, y: 359, width: 640, height: 360,
+			want: image.Pt(1, 1078),
+		},
+		{
+			name:    "video scaled up",
+			mapping: input.Mapping{Desktop: image.Rect(0, 0, 1280, 720)},
+			x:       2559, y: 1, width: 2560, height: 1440,
+			want: image.Pt(1279, 0),
+		},
+		{
+			name:    "ratio",
+			mapping: input.Mapping{Desktop: image.Rect(0, 0, 1920, 1080)},
+			x:       5000, y: 2500, width: message.Ratio, height: message.Ratio,
+			want: image.Pt(960, 270),
+		},
+		{
+			name:    "multi monitor",
+			mapping: input.Mapping{Desktop: image.Rect(-1920, 0, 1920, 1080)},
+			x:       0, y: 0, width: 3840, height: 1080,
+			want: image.Pt(-1920, 0),
+		},
+		{
+			name: "region",
+			mapping: input.Mapping{
+				Desktop: image.Rect(0, 0, 1920, 1080),
+				Region:  image.Rect(100, 50, 1380, 770),
+			},
+			x: 10, y: 20, width: 1280, height: 720,
+			want: image.Pt(110, 70),
+		},
+		{
+			name: "region on left monitor",
+			mapping: input.Mapping{
+				Desktop: image.Rect(-1920, 0, 1920, 1080),
+				Region:  image.Rect(-1920, 0, 0, 1080),
+			},
+			x: 959, y: 539, width: 960, height: 540,
+			want: image.Pt(-1, 1079),
+		},
+		{
+			// 系统缩放 150%：物理 1920x1080 对应逻辑 1280x720
+			name: "dpi scaled",
+			mapping: input.Mapping{
+				Desktop: image.Rect(0, 0, 1280, 720),
+				Region:  image.Rect(0, 0, 1920, 1080),
+				Scale:   1.5,
+			},
+			x: 960, y: 1079, width: 1920, height: 1080,
+			want: image.Pt(640, 719),
+		},
+		{
+			name: "dpi scaled region on second monitor",
+			mapping: input.Mapping{
+				Desktop: image.Rect(0, 0, 2560, 720),
+				Region:  image.Rect(1920, 0, 3840, 1080),
+				Scale:   1.5,
+			},
+			x: 0, y: 540, width: 1920, height: 1080,
+			want: image.Pt(1280, 360),
+		},
+		{
+			name:    "outside video",
+			mapping: input.Mapping{Desktop: image.Rect(0, 0, 1920, 1080)},
+			x:       -50, y: 5000, width: 1920, height: 1080,
+			want: image.Pt(0, 1079),
+		},
+		{
+			name: "region outside desktop",
+			mapping: input.Mapping{
+				Desktop: image.Rect(0, 0, 1920, 1080),
+				Region:  image.Rect(1800, 1000, 2200, 1200),
+			},
+			x: 399, y: 199, width: 400, height: 200,
+			want: image.Pt(1919, 1079),
+		},
+		{
+			name:    "empty video",
+			mapping: input.Mapping{Desktop: image.Rect(-10, -20, 1920, 1080)},
+			x:       5, y: 5, width: 0, height: 0,
+			want: image.Pt(-10, -20),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.mapping.Point(test.x, test.y, test.width, test.height); got != test.want {
+				t.Errorf("Point(%d, %d, %d, %d) = %v, want %v", test.x, test.y, test.width, test.height, got, test.want)
+			}
+		})
+	}
+}
+
+// TestMappingEndToEnd 从视频坐标换算到归一化坐标，再按系统的方式换算回像素，应该落在区域内对应的位置
+func TestMappingEndToEnd(t *testing.T) {
+	mapping := input.Mapping{
+		Desktop: image.Rect(-1280, -1024, 1920, 1080),
+		Region:  image.Rect(-1280, -1024, 0, 0),
+	}
+
+	for y := 0; y < 1024; y += 7 {
+		for x := 0; x < 1280; x += 7 {
+			point := mapping.Point(x, y, 1280, 1024)
+			nx, ny := input.Normalize(point, mapping.Desktop)
+
+			want := image.Pt(-1280+x, -1024+y)
+			if got := image.Pt(denormalize(nx, mapping.Desktop, false), denormalize(ny, mapping.Desktop, true)); got != want {
+				t.Fatalf("video (%d, %d) lands on %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestDispatchMouseAbsolute(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 1920, 1080))
+	dispatcher := input.NewDispatcher(recorder, keys)
+	dispatcher.SetRegion(image.Rect(960, 540, 1920, 1080), 1)
+
+	if err := dispatcher.Dispatch(message.Message{Event: message.MouseAbsolute, P1: 240, P2: 135, P3: message.PackSize(480, 270)}); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if err := dispatcher.Dispatch(message.Message{Event: message.MouseMove, P1: message.Ratio / 2, P2: 0}); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+
+	recorder.Assert(t,
+		inputtest.Move(1441, 811),
+		inputtest.Move(1440, 540),
+	)
+}
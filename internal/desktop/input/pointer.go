@@ -0,0 +1,99 @@
+package input
+
+import "image"
+
+// MaxContacts 是同时注入的触点数上限，与 Windows 触摸注入允许的最大值无关，大多数触摸屏支持 10 点
+const MaxContacts = 10
+
+// MaxPressure 是 Contact 和 Pen 的最大压力值，与 Windows 指针输入的范围相同
+const MaxPressure = 1024
+
+// ContactState 是触点在一帧中的状态
+type ContactState uint8
+
+const (
+	ContactDown ContactState = iota
+	ContactUpdate
+	ContactUp
+	// ContactCancel 表示触点被取消，应用不应把它当作一次点击
+	ContactCancel
+)
+
+func (state ContactState) String() string {
+	switch state {
+	case ContactDown:
+		return "down"
+	case ContactUpdate:
+		return "update"
+	case ContactUp:
+		return "up"
+	case ContactCancel:
+		return "cancel"
+	default:
+		return "unknown"
+	}
+}
+
+// Contact 是一帧触摸中的一个触点。
+//
+// Windows 要求每一帧包含所有活动的触点，没有变化的触点也要以 ContactUpdate 出现，
+// 否则系统会认为它被取消。
+type Contact struct {
+	// ID 在 0 到 MaxContacts-1 之间，触点抬起前保持不变
+	ID uint32
+	// X 和 Y 是虚拟桌面上的像素坐标
+	X, Y int
+	// Area 是接触区域，为空时不注入接触区域
+	Area image.Rectangle
+	// Pressure 为 0~MaxPressure，为 0 时不注入压力
+	Pressure uint32
+	State    ContactState
+}
+
+// PenState 是笔相对屏幕的状态
+type PenState uint8
+
+const (
+	// PenHover 表示笔尖在感应范围内但没有接触屏幕
+	PenHover PenState = iota
+	PenDown
+	// PenMove 表示笔尖接触屏幕并移动
+	PenMove
+	PenUp
+	// PenLeave 表示笔离开感应范围
+	PenLeave
+)
+
+func (state PenState) String() string {
+	switch state {
+	case PenHover:
+		return "hover"
+	case PenDown:
+		return "down"
+	case PenMove:
+		return "move"
+	case PenUp:
+		return "up"
+	case PenLeave:
+		return "leave"
+	default:
+		return "unknown"
+	}
+}
+
+// Pen 是笔的一次状态
+type Pen struct {
+	// X 和 Y 是虚拟桌面上的像素坐标
+	X, Y  int
+	State PenState
+	// Pressure 为 0~MaxPressure
+	Pressure uint32
+	// TiltX 和 TiltY 为 -90~90 度
+	TiltX, TiltY int
+	// Rotation 为 0~359 度
+	Rotation uint32
+	// Eraser 表示使用橡皮擦端
+	Eraser bool
+	// Barrel 表示按住了笔杆上的按钮
+	Barrel bool
+}
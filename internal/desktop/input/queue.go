@@ -0,0 +1,220 @@
+package input
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+// DefaultQueueSize 是每个会话最多排队的消息数
+const DefaultQueueSize = 1024
+
+// QueueStats 是输入队列的计数
+type QueueStats struct {
+	// Dispatched 是实际交给 Dispatcher 的消息数
+	Dispatched uint64 `json:"dispatched"`
+	// Coalesced 是与后一条移动合并的移动消息数
+	Coalesced uint64 `json:"coalesced"`
+	// Dropped 是队列满或关闭时丢弃的消息数
+	Dropped uint64 `json:"dropped"`
+}
+
+// Add 返回两个计数的和
+func (stats QueueStats) Add(other QueueStats) QueueStats {
+	return QueueStats{
+		Dispatched: stats.Dispatched + other.Dispatched,
+		Coalesced:  stats.Coalesced + other.Coalesced,
+		Dropped:    stats.Dropped + other.Dropped,
+	}
+}
+
+// Queue 在后台按顺序分发一个会话的输入消息。
+//
+// 还没有分发的连续鼠标移动会合并为一条，鼠标移动的注入间隔不小于 interval；
+// 按键、按钮等其他消息从不合并，并且总是在它之前的移动之后分发。
+type Queue struct {
+	dispatch func(message.Message) error
+	onError  func(message.Message, error)
+	interval time.Duration
+	size     int
+
+	mu      sync.Mutex
+	pending []message.Message
+	closed  bool
+
+	wake chan struct{}
+	done chan struct{}
+	idle chan struct{}
+
+	dispatched atomic.Uint64
+	coalesced  atomic.Uint64
+	dropped    atomic.Uint64
+}
+
+// NewQueue 创建队列并启动分发的 goroutine，interval 为 0 时不限制移动的频率，
+// onError 在 dispatch 返回错误时调用，可以为空
+func NewQueue(dispatch func(message.Message) error, interval time.Duration, size int, onError func(message.Message, error)) *Queue {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+
+	queue := &Queue{
+		dispatch: dispatch,
+		onError:  onError,
+		interval: interval,
+		size:     size,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		idle:     make(chan struct{}),
+	}
+	go queue.run()
+
+	return queue
+}
+
+// Push 把消息加入队列，不会阻塞
+func (queue *Queue) Push(msg message.Message) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	if queue.closed {
+		queue.dropped.Add(1)
+		return
+	}
+
+	if n := len(queue.pending); n > 0 && coalesce(&queue.pending[n-1], msg) {
+		queue.coalesced.Add(1)
+		queue.signal()
+		return
+	}
+
+	if len(queue.pending) >= queue.size {
+		// 队列满时优先丢弃最早的移动，按键和按钮的顺序和配对比位置更重要
+		i := slices.IndexFunc(queue.pending, isMove)
+		if isMove(msg) || i < 0 {
+			queue.dropped.Add(1)
+			return
+		}
+		queue.pending = slices.Delete(queue.pending, i, i+1)
+		queue.dropped.Add(1)
+	}
+
+	queue.pending = append(queue.pending, msg)
+	queue.signal()
+}
+
+// Stats 返回队列目前的计数
+func (queue *Queue) Stats() QueueStats {
+	return QueueStats{
+		Dispatched: queue.dispatched.Load(),
+		Coalesced:  queue.coalesced.Load(),
+		Dropped:    queue.dropped.Load(),
+	}
+}
+
+// Close 丢弃还没有分发的消息，等待正在分发的消息完成后返回
+func (queue *Queue) Close() {
+	queue.mu.Lock()
+	if queue.closed {
+		queue.mu.Unlock()
+		<-queue.idle
+		return
+	}
+	queue.closed = true
+	queue.dropped.Add(uint64(len(queue.pending)))
+	queue.pending = nil
+	queue.mu.Unlock()
+
+	close(queue.done)
+	<-queue.idle
+}
+
+func (queue *Queue) signal() {
+	select {
+	case queue.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (queue *Queue) run() {
+	defer close(queue.idle)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	var next time.Time
+	for {
+		select {
+		case <-queue.wake:
+		case <-queue.done:
+			return
+		}
+
+		for {
+			queue.mu.Lock()
+			if queue.closed || len(queue.pending) == 0 {
+				queue.mu.Unlock()
+				break
+			}
+
+			msg := queue.pending[0]
+			// 只有队列中只剩这条移动时才等待，等待期间到达的移动会合并到它上面，
+			// 后面已经有按钮或按键时立即分发，不让点击等待
+			if isMove(msg) && len(queue.pending) == 1 && queue.interval > 0 {
+				if wait := time.Until(next); wait > 0 {
+					queue.mu.Unlock()
+					timer.Reset(wait)
+					select {
+					case <-timer.C:
+					case <-queue.wake:
+						if !timer.Stop() {
+							<-timer.C
+						}
+					case <-queue.done:
+						return
+					}
+					continue
+				}
+			}
+			queue.pending = slices.Delete(queue.pending, 0, 1)
+			queue.mu.Unlock()
+
+			if isMove(msg) {
+				next = time.Now().Add(queue.interval)
+			}
+			if err := queue.dispatch(msg); err != nil && queue.onError != nil {
+				queue.onError(msg, err)
+			}
+			queue.dispatched.Add(1)
+		}
+	}
+}
+
+// isMove 判断消息是否为可以合并的鼠标移动
+func isMove(msg message.Message) bool {
+	switch msg.Event {
+	case message.MouseMove, message.MouseAbsolute, message.MouseRelative:
+		return true
+	}
+	return false
+}
+
+// coalesce 把移动 msg 合并到队尾的同类移动 last 上，绝对移动取后一个位置，相对移动累加
+func coalesce(last *message.Message, msg message.Message) bool {
+	if !isMove(msg) || last.Event != msg.Event {
+		return false
+	}
+
+	if msg.Event == message.MouseRelative {
+		last.P1 += msg.P1
+		last.P2 += msg.P2
+		return true
+	}
+
+	*last = msg
+	return true
+}
@@ -0,0 +1,216 @@
+package input_test
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+// blockingDispatch 记录分发的消息，第一条消息会阻塞到 release 被调用，以便在它之后堆积消息
+type blockingDispatch struct {
+	mu      sync.Mutex
+	got     []message.Message
+	started chan struct{}
+	block   chan struct{}
+	done    chan struct{}
+	want    int
+}
+
+func newBlockingDispatch(want int) *blockingDispatch {
+	return &blockingDispatch{
+		started: make(chan struct{}),
+		block:   make(chan struct{}),
+		done:    make(chan struct{}),
+		want:    want,
+	}
+}
+
+func (d *blockingDispatch) dispatch(msg message.Message) error {
+	d.mu.Lock()
+	d.got = append(d.got, msg)
+	n := len(d.got)
+	d.mu.Unlock()
+
+	if n == 1 {
+		close(d.started)
+		<-d.block
+	}
+	if n == d.want {
+		close(d.done)
+	}
+	return nil
+}
+
+func (d *blockingDispatch) wait(t *testing.T) []message.Message {
+	t.Helper()
+
+	select {
+	case <-d.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for dispatch")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]message.Message(nil), d.got...)
+}
+
+func move(x, y int32) message.Message {
+	return message.Message{Event: message.MouseMove, P1: x, P2: y}
+}
+
+func relative(dx, dy int32) message.Message {
+	return message.Message{Event: message.MouseRelative, P1: dx, P2: dy}
+}
+
+var (
+	leftDown = message.Message{Event: message.MouseLeftDown}
+	leftUp   = message.Message{Event: message.MouseLeftUp}
+)
+
+func TestQueueCoalescesMoves(t *testing.T) {
+	d := newBlockingDispatch(7)
+	queue := input.NewQueue(d.dispatch, 0, 0, nil)
+	defer queue.Close()
+
+	queue.Push(move(1, 1))
+	<-d.started
+
+	for _, msg := range []message.Message{
+		move(2, 2), move(3, 3), move(4, 4),
+		leftDown,
+		move(5, 5), move(6, 6),
+		relative(1, 2), relative(3, -4), relative(5, 0),
+		leftUp,
+		relative(7, 7),
+	} {
+		queue.Push(msg)
+	}
+	close(d.block)
+
+	want := []message.Message{
+		move(1, 1),
+		move(4, 4), leftDown,
+		// 绝对移动和相对移动不会互相合并
+		move(6, 6), relative(9, -2),
+		leftUp, relative(7, 7),
+	}
+	if got := d.wait(t); !reflect.DeepEqual(got, want) {
+		t.Errorf("dispatched %v, want %v", got, want)
+	}
+
+	queue.Close()
+	if stats, want := queue.Stats(), (input.QueueStats{Dispatched: 7, Coalesced: 5}); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestQueueDropsWhenFull(t *testing.T) {
+	d := newBlockingDispatch(4)
+	queue := input.NewQueue(d.dispatch, 0, 3, nil)
+	defer queue.Close()
+
+	queue.Push(leftDown)
+	<-d.started
+
+	key := message.Message{Event: 0x1e}
+	// 队列中是 move、key、relative，满了之后新的移动被丢弃，按钮挤掉最早的移动
+	queue.Push(move(1, 1))
+	queue.Push(key)
+	queue.Push(relative(1, 1))
+	queue.Push(move(2, 2))
+	queue.Push(leftUp)
+	close(d.block)
+
+	want := []message.Message{leftDown, key, relative(1, 1), leftUp}
+	if got := d.wait(t); !reflect.DeepEqual(got, want) {
+		t.Errorf("dispatched %v, want %v", got, want)
+	}
+
+	queue.Close()
+	if stats, want := queue.Stats(), (input.QueueStats{Dispatched: 4, Dropped: 2}); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestQueueRateLimitsMoves(t *testing.T) {
+	const interval = 100 * time.Millisecond
+
+	dispatched := make(chan message.Message, 10)
+	queue := input.NewQueue(func(msg message.Message) error {
+		dispatched <- msg
+		return nil
+	}, interval, 0, nil)
+	defer queue.Close()
+
+	start := time.Now()
+	queue.Push(move(1, 1))
+	<-dispatched
+
+	// 下一条移动要等到间隔之后，期间到达的移动合并为一条
+	queue.Push(move(2, 2))
+	queue.Push(move(3, 3))
+	if got := <-dispatched; got != move(3, 3) {
+		t.Errorf("dispatched %v, want the latest move", got)
+	}
+	if elapsed := time.Since(start); elapsed < interval {
+		t.Errorf("second move dispatched after %v, want at least %v", elapsed, interval)
+	}
+
+	// 移动后面有按钮时不等待，按钮不会被延迟
+	start = time.Now()
+	queue.Push(move(4, 4))
+	queue.Push(leftDown)
+	if got := <-dispatched; got != move(4, 4) {
+		t.Errorf("dispatched %v, want move before button", got)
+	}
+	if got := <-dispatched; got != leftDown {
+		t.Errorf("dispatched %v, want button", got)
+	}
+	if elapsed := time.Since(start); elapsed >= interval {
+		t.Errorf("button dispatched after %v, want no rate limit", elapsed)
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	d := newBlockingDispatch(1)
+	failed := make(chan error, 1)
+	queue := input.NewQueue(func(msg message.Message) error {
+		_ = d.dispatch(msg)
+		return errors.New("injection failed")
+	}, 0, 0, func(_ message.Message, err error) { failed <- err })
+
+	queue.Push(leftDown)
+	<-d.started
+	queue.Push(leftUp)
+	queue.Push(move(1, 1))
+
+	closed := make(chan struct{})
+	go func() {
+		queue.Close()
+		close(closed)
+	}()
+
+	// Close 等待正在分发的消息完成
+	select {
+	case <-closed:
+		t.Fatal("Close returned while dispatch was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(d.block)
+	<-closed
+
+	if err := <-failed; err == nil {
+		t.Error("onError was not called")
+	}
+
+	queue.Push(leftUp)
+	if stats, want := queue.Stats(), (input.QueueStats{Dispatched: 1, Dropped: 3}); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
@@ -0,0 +1,100 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+// MaxTextLength 是一条 TextInput 或 TextComposition 消息最多包含的字符数
+const MaxTextLength = 4096
+
+var keyBackspace = Key{Scancode: 0x0e}
+
+// composition 记录输入法预览已经输入到远程桌面的文本。
+//
+// 组字在客户端完成，服务端只看到结果：预览先按普通文本输入，下一次预览或提交时
+// 用退格删掉与新文本不同的部分再输入新的部分，最终留下的就是提交的文本。
+type composition struct {
+	mu      sync.Mutex
+	preview string
+}
+
+// text 注入输入法的预览或提交的文本，commit 为 true 时结束当前的组字
+func (dispatcher *Dispatcher) text(text string, commit bool) error {
+	if utf8.RuneCountInString(text) > MaxTextLength {
+		return fmt.Errorf("%w: text longer than %d characters", message.ErrInvalid, MaxTextLength)
+	}
+	if !commit {
+		// 预览中的控制字符不会被输入，删除时无法计算数量
+		text = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, text)
+	}
+
+	state := &dispatcher.composition
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	// 与已经输入的预览相同的前缀保留，例如拼音预览逐字增长时只需要追加
+	prefix := commonPrefix(state.preview, text)
+	if err := dispatcher.erase(utf8.RuneCountInString(state.preview) - utf8.RuneCountInString(prefix)); err != nil {
+		state.preview = ""
+		return err
+	}
+	state.preview = prefix
+
+	if rest := text[len(prefix):]; rest != "" {
+		if err := dispatcher.injector.Text(rest); err != nil {
+			state.preview = ""
+			return err
+		}
+	}
+
+	if commit {
+		state.preview = ""
+	} else {
+		state.preview = text
+	}
+	return nil
+}
+
+// erase 按 count 次退格
+func (dispatcher *Dispatcher) erase(count int) error {
+	for range count {
+		if err := dispatcher.injector.KeyDown(keyBackspace); err != nil {
+			return err
+		}
+		if err := dispatcher.injector.KeyUp(keyBackspace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetComposition 放弃当前的组字，已经输入的预览保留在远程桌面上
+func (dispatcher *Dispatcher) resetComposition() {
+	dispatcher.composition.mu.Lock()
+	defer dispatcher.composition.mu.Unlock()
+
+	dispatcher.composition.preview = ""
+}
+
+// commonPrefix 返回 a 和 b 的公共前缀，不会截断多字节字符
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	for n < len(a) && n > 0 && !utf8.RuneStart(a[n]) {
+		n--
+	}
+	return a[:n]
+}
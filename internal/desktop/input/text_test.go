@@ -0,0 +1,81 @@
+package input_test
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/input/inputtest"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+var backspace = input.Key{Scancode: 0x0e}
+
+func backspaces(count int) []inputtest.Event {
+	var events []inputtest.Event
+	for range count {
+		events = append(events, inputtest.KeyDown(backspace), inputtest.KeyUp(backspace))
+	}
+	return events
+}
+
+func TestDispatchComposition(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, nil)
+
+	dispatch := func(event uint8, text string) {
+		t.Helper()
+		if err := dispatcher.Dispatch(message.Message{Event: event, P4: text}); err != nil {
+			t.Fatalf("Dispatch(%d, %q): %v", event, text, err)
+		}
+	}
+
+	// 没有组字时直接输入
+	dispatch(message.TextInput, "hello")
+	recorder.Assert(t, inputtest.Text("hello"))
+
+	// 日文输入法：假名预览逐字增长，然后转换成汉字并提交
+	dispatch(message.TextComposition, "か")
+	dispatch(message.TextComposition, "かん")
+	dispatch(message.TextComposition, "かんじ")
+	dispatch(message.TextComposition, "漢字")
+	dispatch(message.TextInput, "漢字")
+	recorder.Assert(t, append(append([]inputtest.Event{
+		inputtest.Text("か"),
+		inputtest.Text("ん"),
+		inputtest.Text("じ"),
+	}, backspaces(3)...),
+		inputtest.Text("漢字"),
+	)...)
+
+	// 提交之后新的组字从头开始，取消组字时删除预览
+	dispatch(message.TextComposition, "ni\x08")
+	dispatch(message.TextComposition, "")
+	recorder.Assert(t, append([]inputtest.Event{inputtest.Text("ni")}, backspaces(2)...)...)
+
+	// 提交的文本与预览不同时替换不同的部分
+	dispatch(message.TextComposition, "zhongwen")
+	dispatch(message.TextInput, "中文")
+	recorder.Assert(t, append(append([]inputtest.Event{inputtest.Text("zhongwen")}, backspaces(8)...), inputtest.Text("中文"))...)
+
+	// 释放后预览留在桌面上，不再被删除
+	dispatch(message.TextComposition, "abc")
+	if err := dispatcher.Release(); err != nil {
+		t.Fatal(err)
+	}
+	dispatch(message.TextInput, "x")
+	recorder.Assert(t, inputtest.Text("abc"), inputtest.Text("x"))
+}
+
+func TestDispatchTextTooLong(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, nil)
+
+	err := dispatcher.Dispatch(message.Message{Event: message.TextInput, P4: strings.Repeat("字", input.MaxTextLength+1)})
+	if !errors.Is(err, message.ErrInvalid) {
+		t.Errorf("Dispatch(long text) = %v, want ErrInvalid", err)
+	}
+	recorder.Assert(t)
+}
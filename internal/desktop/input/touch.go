@@ -0,0 +1,254 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"sort"
+	"sync"
+
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+var ErrTooManyContacts = errors.New("too many touch contacts")
+
+// touches 记录一个会话活动的触点，把客户端的 pointerId 映射到 0~MaxContacts-1 的触点 ID
+type touches struct {
+	mu       sync.Mutex
+	contacts map[int32]Contact
+}
+
+// update 更新客户端 id 对应的触点并返回要注入的一帧，不需要注入时返回 nil
+func (touches *touches) update(id int32, phase int32, contact Contact) ([]Contact, error) {
+	touches.mu.Lock()
+	defer touches.mu.Unlock()
+
+	if touches.contacts == nil {
+		touches.contacts = make(map[int32]Contact)
+	}
+
+	previous, active := touches.contacts[id]
+	switch phase {
+	case message.PointerDown:
+		if active {
+			// 重复的按下当作移动
+			contact.ID, contact.State = previous.ID, ContactUpdate
+			break
+		}
+		slot, ok := touches.free()
+		if !ok {
+			return nil, ErrTooManyContacts
+		}
+		contact.ID, contact.State = slot, ContactDown
+	case message.PointerMove:
+		if !active {
+			return nil, nil
+		}
+		contact.ID, contact.State = previous.ID, ContactUpdate
+	case message.PointerUp, message.PointerCancel:
+		if !active {
+			return nil, nil
+		}
+		contact.ID, contact.State = previous.ID, ContactUp
+		if phase == message.PointerCancel {
+			contact.State = ContactCancel
+		}
+	default:
+		return nil, ErrUnhandled
+	}
+
+	touches.contacts[id] = contact
+	frame := touches.frame()
+
+	if contact.State == ContactUp || contact.State == ContactCancel {
+		delete(touches.contacts, id)
+	} else {
+		contact.State = ContactUpdate
+		touches.contacts[id] = contact
+	}
+
+	return frame, nil
+}
+
+// cancel 取消所有活动的触点并返回要注入的一帧，没有活动的触点时返回 nil
+func (touches *touches) cancel() []Contact {
+	touches.mu.Lock()
+	defer touches.mu.Unlock()
+
+	for id, contact := range touches.contacts {
+		contact.State = ContactCancel
+		touches.contacts[id] = contact
+	}
+	frame := touches.frame()
+	touches.contacts = nil
+
+	return frame
+}
+
+// free 返回最小的未使用的触点 ID
+func (touches *touches) free() (uint32, bool) {
+	used := make(map[uint32]bool, len(touches.contacts))
+	for _, contact := range touches.contacts {
+		used[contact.ID] = true
+	}
+
+	for slot := range uint32(MaxContacts) {
+		if !used[slot] {
+			return slot, true
+		}
+	}
+	return 0, false
+}
+
+// frame 按触点 ID 排序返回所有活动的触点
+func (touches *touches) frame() []Contact {
+	if len(touches.contacts) == 0 {
+		return nil
+	}
+
+	frame := make([]Contact, 0, len(touches.contacts))
+	for _, contact := range touches.contacts {
+		frame = append(frame, contact)
+	}
+	sort.Slice(frame, func(i, j int) bool { return frame[i].ID < frame[j].ID })
+
+	return frame
+}
+
+// pen 记录一个会话的笔最后的状态，用于释放时抬起笔并让它离开感应范围
+type pen struct {
+	mu   sync.Mutex
+	last Pen
+	// inRange 为 false 时笔不在感应范围内，释放时什么都不用做
+	inRange bool
+}
+
+func (pen *pen) update(state Pen) {
+	pen.mu.Lock()
+	defer pen.mu.Unlock()
+
+	pen.last = state
+	pen.inRange = state.State != PenLeave
+}
+
+// release 返回让笔抬起并离开感应范围需要注入的状态
+func (pen *pen) release() []Pen {
+	pen.mu.Lock()
+	defer pen.mu.Unlock()
+
+	if !pen.inRange {
+		return nil
+	}
+	pen.inRange = false
+
+	var states []Pen
+	last := pen.last
+	if last.State == PenDown || last.State == PenMove {
+		last.State = PenUp
+		states = append(states, last)
+	}
+	last.State, last.Pressure = PenLeave, 0
+
+	return append(states, last)
+}
+
+// parsePointer 解析 Touch 和 Pen 消息 p4 中的 message.Pointer，p4 为空时返回零值
+func parsePointer(p4 string) (message.Pointer, error) {
+	var pointer message.Pointer
+	if p4 == "" {
+		return pointer, nil
+	}
+
+	if err := json.Unmarshal([]byte(p4), &pointer); err != nil {
+		return pointer, fmt.Errorf("%w: %v", message.ErrInvalid, err)
+	}
+	return pointer, nil
+}
+
+// pressure 把 PointerEvent 0~1 的压力换算成 0~MaxPressure
+func pressure(value float64) uint32 {
+	return uint32(min(max(value, 0), 1)*MaxPressure + 0.5)
+}
+
+// touch 注入一条 Touch 消息
+func (dispatcher *Dispatcher) touch(msg message.Message) error {
+	pointer, err := parsePointer(msg.P4)
+	if err != nil {
+		return err
+	}
+
+	mapping := dispatcher.mapping()
+	point := mapping.Point(int(msg.P1), int(msg.P2), message.Ratio, message.Ratio)
+	contact := Contact{X: point.X, Y: point.Y, Pressure: pressure(pointer.Pressure)}
+
+	if pointer.Width > 0 && pointer.Height > 0 {
+		// 接触区域以触点为中心
+		x, y, halfWidth, halfHeight := int(msg.P1), int(msg.P2), int(pointer.Width)/2, int(pointer.Height)/2
+		contact.Area = image.Rectangle{
+			Min: mapping.Point(x-halfWidth, y-halfHeight, message.Ratio, message.Ratio),
+			Max: mapping.Point(x+halfWidth, y+halfHeight, message.Ratio, message.Ratio).Add(image.Pt(1, 1)),
+		}
+	}
+
+	frame, err := dispatcher.touches.update(pointer.ID, msg.P3, contact)
+	if err != nil || frame == nil {
+		return err
+	}
+	return dispatcher.injector.Touch(frame)
+}
+
+// pen 注入一条 Pen 消息
+func (dispatcher *Dispatcher) pen(msg message.Message) error {
+	pointer, err := parsePointer(msg.P4)
+	if err != nil {
+		return err
+	}
+
+	state, ok := map[int32]PenState{
+		message.PointerHover:  PenHover,
+		message.PointerDown:   PenDown,
+		message.PointerMove:   PenMove,
+		message.PointerUp:     PenUp,
+		message.PointerCancel: PenLeave,
+	}[msg.P3]
+	if !ok {
+		return ErrUnhandled
+	}
+
+	point := dispatcher.mapping().Point(int(msg.P1), int(msg.P2), message.Ratio, message.Ratio)
+	pen := Pen{
+		X:        point.X,
+		Y:        point.Y,
+		State:    state,
+		TiltX:    int(min(max(pointer.TiltX, -90), 90)),
+		TiltY:    int(min(max(pointer.TiltY, -90), 90)),
+		Rotation: uint32(((pointer.Twist % 360) + 360) % 360),
+		Eraser:   pointer.Eraser,
+		Barrel:   pointer.Barrel,
+	}
+	if state == PenDown || state == PenMove {
+		pen.Pressure = pressure(pointer.Pressure)
+	}
+
+	if err := dispatcher.injector.Pen(pen); err != nil {
+		return err
+	}
+	dispatcher.stylus.update(pen)
+
+	return nil
+}
+
+// releasePointers 取消该会话所有的触点并让笔离开感应范围
+func (dispatcher *Dispatcher) releasePointers() error {
+	var errs []error
+
+	if frame := dispatcher.touches.cancel(); frame != nil {
+		errs = append(errs, dispatcher.injector.Touch(frame))
+	}
+	for _, state := range dispatcher.stylus.release() {
+		errs = append(errs, dispatcher.injector.Pen(state))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,149 @@
+package input_test
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/input/inputtest"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+func touch(phase int32, x, y int32, p4 string) message.Message {
+	return message.Message{Event: message.Touch, P1: x, P2: y, P3: phase, P4: p4}
+}
+
+func TestDispatchTouch(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 1000, 1000))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	for _, msg := range []message.Message{
+		touch(message.PointerDown, 1000, 1000, `{"id":7}`),
+		touch(message.PointerDown, 5000, 5000, `{"id":9,"width":100,"height":200,"pressure":0.5}`),
+		touch(message.PointerMove, 2000, 1000, `{"id":7}`),
+		// 没有按下的触点的移动和抬起被忽略
+		touch(message.PointerMove, 0, 0, `{"id":3}`),
+		touch(message.PointerUp, 2000, 1000, `{"id":7}`),
+		touch(message.PointerCancel, 5000, 5000, `{"id":9}`),
+		// 抬起后触点 ID 可以重新使用
+		touch(message.PointerDown, 0, 0, `{"id":3}`),
+		touch(message.PointerUp, 0, 0, `{"id":3}`),
+	} {
+		if err := dispatcher.Dispatch(msg); err != nil {
+			t.Fatalf("Dispatch(%+v): %v", msg, err)
+		}
+	}
+
+	area := image.Rect(495, 490, 506, 511)
+	recorder.Assert(t,
+		inputtest.Touch(
+			input.Contact{ID: 0, X: 100, Y: 100, State: input.ContactDown},
+		),
+		inputtest.Touch(
+			input.Contact{ID: 0, X: 100, Y: 100, State: input.ContactUpdate},
+			input.Contact{ID: 1, X: 500, Y: 500, Area: area, Pressure: 512, State: input.ContactDown},
+		),
+		inputtest.Touch(
+			input.Contact{ID: 0, X: 200, Y: 100, State: input.ContactUpdate},
+			input.Contact{ID: 1, X: 500, Y: 500, Area: area, Pressure: 512, State: input.ContactUpdate},
+		),
+		inputtest.Touch(
+			input.Contact{ID: 0, X: 200, Y: 100, State: input.ContactUp},
+			input.Contact{ID: 1, X: 500, Y: 500, Area: area, Pressure: 512, State: input.ContactUpdate},
+		),
+		inputtest.Touch(
+			input.Contact{ID: 1, X: 500, Y: 500, State: input.ContactCancel},
+		),
+		inputtest.Touch(input.Contact{ID: 0, State: input.ContactDown}),
+		inputtest.Touch(input.Contact{ID: 0, State: input.ContactUp}),
+	)
+}
+
+func TestDispatchTooManyContacts(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 1000, 1000))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	for id := range input.MaxContacts {
+		msg := touch(message.PointerDown, 0, 0, fmt.Sprintf(`{"id":%d}`, id))
+		if err := dispatcher.Dispatch(msg); err != nil {
+			t.Fatalf("Dispatch(contact %d): %v", id, err)
+		}
+	}
+	recorder.Reset()
+
+	err := dispatcher.Dispatch(touch(message.PointerDown, 0, 0, `{"id":100}`))
+	if !errors.Is(err, input.ErrTooManyContacts) {
+		t.Errorf("Dispatch(contact %d) = %v, want ErrTooManyContacts", input.MaxContacts, err)
+	}
+	recorder.Assert(t)
+
+	if err := dispatcher.Dispatch(touch(message.PointerDown, 0, 0, `{"id`)); !errors.Is(err, message.ErrInvalid) {
+		t.Errorf("Dispatch(invalid p4) = %v, want ErrInvalid", err)
+	}
+}
+
+func TestDispatchPen(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 1000, 1000))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	for _, msg := range []message.Message{
+		{Event: message.Pen, P1: 1000, P2: 1000, P3: message.PointerHover, P4: `{"pressure":0.3}`},
+		{Event: message.Pen, P1: 1000, P2: 1000, P3: message.PointerDown, P4: `{"pressure":0.25,"tiltX":-30,"tiltY":120,"twist":-90}`},
+		{Event: message.Pen, P1: 2000, P2: 1000, P3: message.PointerMove, P4: `{"pressure":2,"eraser":true,"barrel":true}`},
+		{Event: message.Pen, P1: 2000, P2: 1000, P3: message.PointerUp},
+		{Event: message.Pen, P1: 2000, P2: 1000, P3: message.PointerCancel},
+	} {
+		if err := dispatcher.Dispatch(msg); err != nil {
+			t.Fatalf("Dispatch(%+v): %v", msg, err)
+		}
+	}
+
+	recorder.Assert(t,
+		// 悬停时没有压力
+		inputtest.PenEvent(input.Pen{X: 100, Y: 100, State: input.PenHover}),
+		inputtest.PenEvent(input.Pen{X: 100, Y: 100, State: input.PenDown, Pressure: 256, TiltX: -30, TiltY: 90, Rotation: 270}),
+		inputtest.PenEvent(input.Pen{X: 200, Y: 100, State: input.PenMove, Pressure: input.MaxPressure, Eraser: true, Barrel: true}),
+		inputtest.PenEvent(input.Pen{X: 200, Y: 100, State: input.PenUp}),
+		inputtest.PenEvent(input.Pen{X: 200, Y: 100, State: input.PenLeave}),
+	)
+
+	if err := dispatcher.Dispatch(message.Message{Event: message.Pen, P3: 9}); !errors.Is(err, input.ErrUnhandled) {
+		t.Errorf("Dispatch(unknown phase) = %v, want ErrUnhandled", err)
+	}
+}
+
+func TestReleasePointers(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 1000, 1000))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	for _, msg := range []message.Message{
+		touch(message.PointerDown, 1000, 1000, `{"id":1}`),
+		touch(message.PointerDown, 2000, 2000, `{"id":2}`),
+		{Event: message.Pen, P1: 5000, P2: 5000, P3: message.PointerDown, P4: `{"pressure":0.5}`},
+	} {
+		if err := dispatcher.Dispatch(msg); err != nil {
+			t.Fatalf("Dispatch(%+v): %v", msg, err)
+		}
+	}
+	recorder.Reset()
+
+	if err := dispatcher.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	recorder.Assert(t,
+		inputtest.Touch(
+			input.Contact{ID: 0, X: 100, Y: 100, State: input.ContactCancel},
+			input.Contact{ID: 1, X: 200, Y: 200, State: input.ContactCancel},
+		),
+		inputtest.PenEvent(input.Pen{X: 500, Y: 500, State: input.PenUp, Pressure: 512}),
+		inputtest.PenEvent(input.Pen{X: 500, Y: 500, State: input.PenLeave}),
+	)
+
+	// 已经释放过，再次释放什么都不做
+	if err := dispatcher.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	recorder.Assert(t)
+}
@@ -0,0 +1,144 @@
+package input
+
+import (
+	"errors"
+	"slices"
+	"sync"
+)
+
+// Modifier 是修饰键的位掩码，不区分左右
+type Modifier uint8
+
+const (
+	ModifierShift Modifier = 1 << iota
+	ModifierControl
+	ModifierAlt
+	ModifierMeta
+)
+
+// State 是一个会话当前按住的按键
+type State struct {
+	Keys      []Key
+	Buttons   []Button
+	Modifiers Modifier
+}
+
+// Tracker 包装 Injector 并记录按住的按键和鼠标按钮，以便在断开时全部释放
+type Tracker struct {
+	Injector
+
+	mu      sync.Mutex
+	keys    []Key
+	buttons []Button
+}
+
+func NewTracker(injector Injector) *Tracker {
+	return &Tracker{Injector: injector}
+}
+
+func (tracker *Tracker) KeyDown(key Key) error {
+	if err := tracker.Injector.KeyDown(key); err != nil {
+		return err
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if !slices.Contains(tracker.keys, key) {
+		tracker.keys = append(tracker.keys, key)
+	}
+	return nil
+}
+
+// KeyUp 即使注入失败也认为按键已经释放，避免之后重复释放
+func (tracker *Tracker) KeyUp(key Key) error {
+	tracker.mu.Lock()
+	tracker.keys = slices.DeleteFunc(tracker.keys, func(pressed Key) bool { return pressed == key })
+	tracker.mu.Unlock()
+
+	return tracker.Injector.KeyUp(key)
+}
+
+func (tracker *Tracker) ButtonDown(button Button) error {
+	if err := tracker.Injector.ButtonDown(button); err != nil {
+		return err
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if !slices.Contains(tracker.buttons, button) {
+		tracker.buttons = append(tracker.buttons, button)
+	}
+	return nil
+}
+
+func (tracker *Tracker) ButtonUp(button Button) error {
+	tracker.mu.Lock()
+	tracker.buttons = slices.DeleteFunc(tracker.buttons, func(pressed Button) bool { return pressed == button })
+	tracker.mu.Unlock()
+
+	return tracker.Injector.ButtonUp(button)
+}
+
+// State 返回当前按住的按键，按按下的先后排列
+func (tracker *Tracker) State() State {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	state := State{
+		Keys:    slices.Clone(tracker.keys),
+		Buttons: slices.Clone(tracker.buttons),
+	}
+	for _, key := range tracker.keys {
+		state.Modifiers |= modifierOf(key)
+	}
+	return state
+}
+
+// Release 按与按下相反的顺序释放所有按住的鼠标按钮和按键
+func (tracker *Tracker) Release() error {
+	tracker.mu.Lock()
+	keys, buttons := tracker.keys, tracker.buttons
+	tracker.keys, tracker.buttons = nil, nil
+	tracker.mu.Unlock()
+
+	var errs []error
+	for i := len(buttons) - 1; i >= 0; i-- {
+		errs = append(errs, tracker.Injector.ButtonUp(buttons[i]))
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		errs = append(errs, tracker.Injector.KeyUp(keys[i]))
+	}
+
+	return errors.Join(errs...)
+}
+
+// modifierOf 判断按键是否为修饰键，同时识别扫描码和虚拟键码
+func modifierOf(key Key) Modifier {
+	if key.Scancode != 0 {
+		switch {
+		case key.Scancode == 0x2a || key.Scancode == 0x36:
+			return ModifierShift
+		case key.Scancode == 0x1d:
+			return ModifierControl
+		case key.Scancode == 0x38:
+			return ModifierAlt
+		case key.Extended && (key.Scancode == 0x5b || key.Scancode == 0x5c):
+			return ModifierMeta
+		}
+		return 0
+	}
+
+	switch key.VK {
+	case 0x10, 0xa0, 0xa1: // VK_SHIFT, VK_LSHIFT, VK_RSHIFT
+		return ModifierShift
+	case 0x11, 0xa2, 0xa3: // VK_CONTROL, VK_LCONTROL, VK_RCONTROL
+		return ModifierControl
+	case 0x12, 0xa4, 0xa5: // VK_MENU, VK_LMENU, VK_RMENU
+		return ModifierAlt
+	case 0x5b, 0x5c: // VK_LWIN, VK_RWIN
+		return ModifierMeta
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+package input_test
+
+import (
+	"errors"
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/input/inputtest"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+var (
+	lshift = input.Key{VK: 0xa0}
+	ctrl   = input.Key{Scancode: 0x1d}
+	ralt   = input.Key{Scancode: 0x38, Extended: true}
+	lwin   = input.Key{Scancode: 0x5b, Extended: true}
+	keyA   = input.Key{Scancode: 30}
+)
+
+func TestTrackerRelease(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	tracker := input.NewTracker(recorder)
+
+	_ = tracker.KeyDown(ctrl)
+	_ = tracker.KeyDown(lshift)
+	_ = tracker.ButtonDown(input.ButtonLeft)
+	_ = tracker.KeyDown(keyA)
+	_ = tracker.KeyDown(keyA) // 自动重复不会重复记录
+	_ = tracker.KeyUp(keyA)
+	_ = tracker.ButtonDown(input.ButtonX1)
+	recorder.Reset()
+
+	state := tracker.State()
+	want := input.State{
+		Keys:      []input.Key{ctrl, lshift},
+		Buttons:   []input.Button{input.ButtonLeft, input.ButtonX1},
+		Modifiers: input.ModifierControl | input.ModifierShift,
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("State() = %+v, want %+v", state, want)
+	}
+
+	if err := tracker.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	recorder.Assert(t,
+		inputtest.ButtonUp(input.ButtonX1),
+		inputtest.ButtonUp(input.ButtonLeft),
+		inputtest.KeyUp(lshift),
+		inputtest.KeyUp(ctrl),
+	)
+
+	// 已经释放过的按键不会再次释放
+	if err := tracker.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	recorder.Assert(t)
+}
+
+func TestTrackerModifiers(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	tracker := input.NewTracker(recorder)
+
+	_ = tracker.KeyDown(ralt)
+	_ = tracker.KeyDown(lwin)
+	_ = tracker.KeyDown(keyA)
+
+	if got, want := tracker.State().Modifiers, input.ModifierAlt|input.ModifierMeta; got != want {
+		t.Errorf("Modifiers = %b, want %b", got, want)
+	}
+
+	_ = tracker.KeyUp(lwin)
+	if got, want := tracker.State().Modifiers, input.ModifierAlt; got != want {
+		t.Errorf("Modifiers = %b, want %b", got, want)
+	}
+}
+
+func TestTrackerFailedPress(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	recorder.Err = errors.New("blocked")
+	tracker := input.NewTracker(recorder)
+
+	_ = tracker.KeyDown(ctrl)
+	_ = tracker.ButtonDown(input.ButtonRight)
+
+	if state := tracker.State(); len(state.Keys) != 0 || len(state.Buttons) != 0 {
+		t.Errorf("State() = %+v, want nothing pressed", state)
+	}
+}
+
+func TestDispatchFocusLost(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	_ = dispatcher.Dispatch(message.Message{Event: 0})
+	_ = dispatcher.Dispatch(message.Message{Event: message.MouseRightDown})
+	recorder.Reset()
+
+	if err := dispatcher.Dispatch(message.Message{Event: message.FocusLost}); err != nil {
+		t.Fatalf("Dispatch(FocusLost): %v", err)
+	}
+	recorder.Assert(t,
+		inputtest.ButtonUp(input.ButtonRight),
+		inputtest.KeyUp(input.Key{VK: 0x10}),
+	)
+}
@@ -0,0 +1,163 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+// KeyboardMode 决定 KeyCode 消息按物理位置还是按字符翻译
+type KeyboardMode uint8
+
+const (
+	// Positional 注入按键位置对应的扫描码，产生的字符由远程桌面的键盘布局决定，
+	// 适合游戏和两端布局相同的情况
+	Positional KeyboardMode = iota
+	// Symbolic 按客户端键盘布局产生的字符输入，两端布局不同时也能得到客户端看到的字符；
+	// 不产生字符的按键仍然按位置注入，按住 Ctrl、Alt 或 Win 时字母和数字按虚拟键码注入，保证快捷键可用
+	Symbolic
+)
+
+func (mode KeyboardMode) String() string {
+	switch mode {
+	case Positional:
+		return "positional"
+	case Symbolic:
+		return "symbolic"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseKeyboardMode 解析 positional 或 symbolic
+func ParseKeyboardMode(value string) (KeyboardMode, error) {
+	switch value {
+	case "positional":
+		return Positional, nil
+	case "symbolic":
+		return Symbolic, nil
+	default:
+		return Positional, fmt.Errorf("unknown keyboard mode %q, want positional or symbolic", value)
+	}
+}
+
+// keyCodes 记录每个物理按键按下时注入的按键，释放时原样释放，
+// 翻译方式或修饰键在按下和释放之间改变时也不会漏掉释放
+type keyCodes struct {
+	mu      sync.Mutex
+	pressed map[string]Key
+}
+
+// keyCode 注入一条 KeyCode 消息
+func (dispatcher *Dispatcher) keyCode(msg message.Message) error {
+	var event message.Keyboard
+	if err := json.Unmarshal([]byte(msg.P4), &event); err != nil {
+		return fmt.Errorf("%w: %v", message.ErrInvalid, err)
+	}
+
+	if msg.P3 != 0 {
+		return dispatcher.keyCodeUp(event.Code)
+	}
+
+	mode := dispatcher.keyboard
+	switch msg.P1 {
+	case message.KeyboardPositional:
+		mode = Positional
+	case message.KeyboardSymbolic:
+		mode = Symbolic
+	}
+
+	if mode == Symbolic {
+		// 死键由客户端组合，组合出的字符随下一次按键的 key 到达
+		if event.Key == "Dead" {
+			return nil
+		}
+
+		if r, ok := character(event.Key); ok {
+			if dispatcher.State().Modifiers&(ModifierControl|ModifierAlt|ModifierMeta) != 0 {
+				if key, ok := shortcutKey(r); ok {
+					return dispatcher.keyCodeDown(event.Code, key)
+				}
+			}
+			return dispatcher.injector.Text(event.Key)
+		}
+	}
+
+	key, ok := CodeKey(event.Code)
+	if !ok {
+		return ErrUnhandled
+	}
+	return dispatcher.keyCodeDown(event.Code, key)
+}
+
+func (dispatcher *Dispatcher) keyCodeDown(code string, key Key) error {
+	codes := &dispatcher.keyCodes
+	codes.mu.Lock()
+	defer codes.mu.Unlock()
+
+	// 按住时重复的按下注入的可能是另一个按键，先释放之前的
+	if previous, ok := codes.pressed[code]; ok && previous != key {
+		delete(codes.pressed, code)
+		if err := dispatcher.injector.KeyUp(previous); err != nil {
+			return err
+		}
+	}
+
+	if err := dispatcher.injector.KeyDown(key); err != nil {
+		return err
+	}
+
+	if codes.pressed == nil {
+		codes.pressed = make(map[string]Key)
+	}
+	codes.pressed[code] = key
+	return nil
+}
+
+// keyCodeUp 释放 code 按下时注入的按键，按下时输入的是字符则什么都不做
+func (dispatcher *Dispatcher) keyCodeUp(code string) error {
+	codes := &dispatcher.keyCodes
+	codes.mu.Lock()
+	defer codes.mu.Unlock()
+
+	key, ok := codes.pressed[code]
+	if !ok {
+		return nil
+	}
+	delete(codes.pressed, code)
+
+	return dispatcher.injector.KeyUp(key)
+}
+
+// resetKeyCodes 忘记所有按下的物理按键，按键本身由 Tracker 释放
+func (dispatcher *Dispatcher) resetKeyCodes() {
+	dispatcher.keyCodes.mu.Lock()
+	defer dispatcher.keyCodes.mu.Unlock()
+
+	dispatcher.keyCodes.pressed = nil
+}
+
+// character 判断 KeyboardEvent.key 是否是一个可见字符，Enter、ArrowUp 这样的按键名和空格都不是
+func character(key string) (rune, bool) {
+	r, size := utf8.DecodeRuneInString(key)
+	if size == 0 || size != len(key) || r == utf8.RuneError {
+		return 0, false
+	}
+	return r, unicode.IsGraphic(r) && !unicode.IsSpace(r)
+}
+
+// shortcutKey 返回快捷键中字母和数字的虚拟键码，由远程桌面的布局换算成扫描码
+func shortcutKey(r rune) (Key, bool) {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return Key{VK: uint16(r - 'a' + 'A')}, true
+	case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return Key{VK: uint16(r)}, true
+	default:
+		return Key{}, false
+	}
+}
@@ -0,0 +1,50 @@
+package input
+
+import "sync"
+
+// PixelsPerNotch 是浏览器滚动一格报告的像素数，Windows 上的 Chrome 和 Edge 在 100% 缩放时都是 100
+const PixelsPerNotch = 100
+
+// wheelAccumulator 把浏览器报告的像素换算成 WheelDelta 单位。
+//
+// 触控板每次只报告几个像素，换算后不足一个单位的部分会累积到下一次，
+// 所以缓慢滑动也能滚动，滚动的总量与像素总量一致。
+type wheelAccumulator struct {
+	mu        sync.Mutex
+	remainder int
+}
+
+// add 累积 pixels 并返回可以注入的 WheelDelta 单位，方向改变时丢弃之前的余数
+func (accumulator *wheelAccumulator) add(pixels int) int {
+	accumulator.mu.Lock()
+	defer accumulator.mu.Unlock()
+
+	if pixels > 0 && accumulator.remainder < 0 || pixels < 0 && accumulator.remainder > 0 {
+		accumulator.remainder = 0
+	}
+
+	total := pixels*WheelDelta + accumulator.remainder
+	units := total / PixelsPerNotch
+	accumulator.remainder = total - units*PixelsPerNotch
+
+	return units
+}
+
+// wheel 注入浏览器 WheelEvent 的 deltaX 和 deltaY。
+//
+// 浏览器向下和向右为正，Windows 的垂直滚轮向上为正，水平滚轮向右为正。
+func (dispatcher *Dispatcher) wheel(deltaX, deltaY int32) error {
+	if units := dispatcher.vertical.add(int(deltaY)); units != 0 {
+		if err := dispatcher.injector.Wheel(-units); err != nil {
+			return err
+		}
+	}
+
+	if units := dispatcher.horizontal.add(int(deltaX)); units != 0 {
+		if err := dispatcher.injector.HWheel(units); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
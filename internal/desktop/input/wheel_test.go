@@ -0,0 +1,118 @@
+package input_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/input/inputtest"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+func wheel(dispatcher *input.Dispatcher, t *testing.T, deltaX, deltaY int32) {
+	t.Helper()
+
+	if err := dispatcher.Dispatch(message.Message{Event: message.MouseWheel, P1: deltaX, P2: deltaY}); err != nil {
+		t.Fatalf("Dispatch(wheel %d, %d): %v", deltaX, deltaY, err)
+	}
+}
+
+func TestWheelNotches(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	wheel(dispatcher, t, 0, 100)
+	wheel(dispatcher, t, 0, -300)
+	wheel(dispatcher, t, 100, 0)
+	wheel(dispatcher, t, -100, 100)
+
+	recorder.Assert(t,
+		inputtest.Wheel(-input.WheelDelta),
+		inputtest.Wheel(3*input.WheelDelta),
+		inputtest.HWheel(input.WheelDelta),
+		inputtest.Wheel(-input.WheelDelta),
+		inputtest.HWheel(-input.WheelDelta),
+	)
+}
+
+func TestWheelAccumulatesTrackpad(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	// 每次 1 像素只有 1.2 个单位，余数累积下来，5 次正好 6 个单位
+	var total int
+	for range 5 {
+		wheel(dispatcher, t, 0, -1)
+	}
+	for _, event := range recorder.Events() {
+		if event.Op != inputtest.OpWheel || event.X <= 0 {
+			t.Fatalf("unexpected event %v", event)
+		}
+		total += event.X
+	}
+	if total != 6 {
+		t.Errorf("total wheel = %d, want 6", total)
+	}
+	recorder.Reset()
+
+	// 1000 像素分 7 次滚动，总量仍然是 10 格
+	total = 0
+	for _, pixels := range []int32{143, 143, 143, 143, 143, 143, 142} {
+		wheel(dispatcher, t, pixels, 0)
+	}
+	for _, event := range recorder.Events() {
+		total += event.X
+	}
+	if total != 10*input.WheelDelta {
+		t.Errorf("total hwheel = %d, want %d", total, 10*input.WheelDelta)
+	}
+}
+
+func TestWheelDirectionChangeDropsRemainder(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	wheel(dispatcher, t, 0, 53) // 63 个单位，余 60
+	wheel(dispatcher, t, 0, -4) // 方向改变，余数被丢弃
+	recorder.Assert(t,
+		inputtest.Wheel(-63),
+		inputtest.Wheel(4),
+	)
+}
+
+func TestDispatchXButtons(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	for _, msg := range []message.Message{
+		{Event: message.MouseXDown, P1: 1},
+		{Event: message.MouseXUp, P1: 1},
+		{Event: message.MouseXDown, P1: 2},
+		{Event: message.MouseXUp, P1: 2},
+	} {
+		if err := dispatcher.Dispatch(msg); err != nil {
+			t.Fatalf("Dispatch(%+v): %v", msg, err)
+		}
+	}
+
+	if err := dispatcher.Dispatch(message.Message{Event: message.MouseXDown, P1: 3}); err != input.ErrUnhandled {
+		t.Errorf("Dispatch(XDown 3) = %v, want ErrUnhandled", err)
+	}
+
+	recorder.Assert(t,
+		inputtest.ButtonDown(input.ButtonX1),
+		inputtest.ButtonUp(input.ButtonX1),
+		inputtest.ButtonDown(input.ButtonX2),
+		inputtest.ButtonUp(input.ButtonX2),
+	)
+}
+
+func TestDispatchHWheel(t *testing.T) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+	dispatcher := input.NewDispatcher(recorder, keys)
+
+	if err := dispatcher.Dispatch(message.Message{Event: message.MouseHWheel, P1: -200}); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	recorder.Assert(t, inputtest.HWheel(-2*input.WheelDelta))
+}
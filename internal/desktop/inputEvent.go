@@ -0,0 +1,182 @@
+package desktop
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+// dispatchers 为每个会话保存独立的 Dispatcher 和输入队列，这样每个会话按住的按键可以分别释放
+type dispatchers struct {
+	mu       sync.Mutex
+	sessions map[string]*input.Dispatcher
+	queues   map[string]*input.Queue
+	// closed 是已经关闭的队列的计数之和
+	closed input.QueueStats
+}
+
+// InputStats 是输入队列的计数
+type InputStats struct {
+	// Sessions 是每个会话当前队列的计数
+	Sessions map[string]input.QueueStats `json:"sessions"`
+	// Total 包括已经关闭的队列
+	Total input.QueueStats `json:"total"`
+}
+
+// Input 返回用于注入键盘鼠标事件的 Injector
+func (manager *Manager) Input() input.Injector {
+	return manager.input
+}
+
+// DispatchInput 把会话在 key 和 mouse 通道的一条消息加入该会话的输入队列，由队列按顺序注入
+func (manager *Manager) DispatchInput(sessionID string, msg message.Message) {
+	manager.dispatchers.mu.Lock()
+	dispatcher, ok := manager.dispatchers.sessions[sessionID]
+	if !ok {
+		dispatcher = input.NewDispatcher(manager.input, input.LegacyKeys)
+		dispatcher.SetRegion(manager.config.Region, manager.scale)
+		dispatcher.SetKeyboardMode(manager.config.Keyboard)
+		manager.dispatchers.sessions[sessionID] = dispatcher
+	}
+	queue, ok := manager.dispatchers.queues[sessionID]
+	if !ok {
+		queue = input.NewQueue(dispatcher.Dispatch, manager.inputInterval(), input.DefaultQueueSize, func(msg message.Message, err error) {
+			if errors.Is(err, input.ErrUnhandled) {
+				manager.logger.Debug("Unhandled input event", "session_id", sessionID, "event", msg.Event)
+				return
+			}
+			manager.logger.Warn("Failed to inject input", "session_id", sessionID, "event", msg.Event, "error", err)
+		})
+		manager.dispatchers.queues[sessionID] = queue
+	}
+	manager.dispatchers.mu.Unlock()
+
+	queue.Push(msg)
+}
+
+// inputInterval 返回鼠标移动的最小注入间隔
+func (manager *Manager) inputInterval() time.Duration {
+	if manager.config.InputRate <= 0 {
+		return 0
+	}
+	return time.Second / time.Duration(manager.config.InputRate)
+}
+
+// InputStats 返回输入队列的计数
+func (manager *Manager) InputStats() InputStats {
+	manager.dispatchers.mu.Lock()
+	defer manager.dispatchers.mu.Unlock()
+
+	stats := InputStats{
+		Sessions: make(map[string]input.QueueStats, len(manager.dispatchers.queues)),
+		Total:    manager.dispatchers.closed,
+	}
+	for id, queue := range manager.dispatchers.queues {
+		stats.Sessions[id] = queue.Stats()
+		stats.Total = stats.Total.Add(stats.Sessions[id])
+	}
+	return stats
+}
+
+// InputHandler 返回输入的 HTTP 接口:
+//
+//	GET /input/stats  查询输入队列合并和丢弃的消息数
+func (manager *Manager) InputHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /input/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(manager.InputStats())
+	})
+
+	return mux
+}
+
+// closeQueue 关闭会话的输入队列，丢弃还没有注入的消息，等待正在注入的消息完成。
+// 之后再释放按键，排队的按下事件就不会在释放之后才注入
+func (manager *Manager) closeQueue(sessionID string) {
+	manager.dispatchers.mu.Lock()
+	queue, ok := manager.dispatchers.queues[sessionID]
+	delete(manager.dispatchers.queues, sessionID)
+	manager.dispatchers.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	queue.Close()
+	stats := queue.Stats()
+
+	manager.dispatchers.mu.Lock()
+	manager.dispatchers.closed = manager.dispatchers.closed.Add(stats)
+	manager.dispatchers.mu.Unlock()
+
+	manager.logger.Debug("Input queue closed", "session_id", sessionID, "dispatched", stats.Dispatched, "coalesced", stats.Coalesced, "dropped", stats.Dropped)
+}
+
+// InputState 返回会话当前按住的按键和鼠标按钮
+func (manager *Manager) InputState(sessionID string) input.State {
+	manager.dispatchers.mu.Lock()
+	dispatcher, ok := manager.dispatchers.sessions[sessionID]
+	manager.dispatchers.mu.Unlock()
+
+	if !ok {
+		return input.State{}
+	}
+	return dispatcher.State()
+}
+
+// PointerLocked 判断会话是否处于相对指针模式
+func (manager *Manager) PointerLocked(sessionID string) bool {
+	manager.dispatchers.mu.Lock()
+	dispatcher, ok := manager.dispatchers.sessions[sessionID]
+	manager.dispatchers.mu.Unlock()
+
+	return ok && dispatcher.Relative()
+}
+
+// ReleaseInput 释放会话按住的所有按键、鼠标按钮、触点和笔，在会话关闭时调用
+func (manager *Manager) ReleaseInput(sessionID string) {
+	manager.dispatchers.mu.Lock()
+	dispatcher, ok := manager.dispatchers.sessions[sessionID]
+	delete(manager.dispatchers.sessions, sessionID)
+	manager.dispatchers.mu.Unlock()
+
+	manager.closeQueue(sessionID)
+	if ok {
+		manager.release(sessionID, dispatcher)
+	}
+}
+
+// ReleaseInputExcept 释放除 sessionID 以外所有会话的输入并退出相对指针模式，在控制权转移时调用
+func (manager *Manager) ReleaseInputExcept(sessionID string) {
+	manager.dispatchers.mu.Lock()
+	released := make(map[string]*input.Dispatcher, len(manager.dispatchers.sessions))
+	for id, dispatcher := range manager.dispatchers.sessions {
+		if id != sessionID {
+			released[id] = dispatcher
+		}
+	}
+	manager.dispatchers.mu.Unlock()
+
+	for id, dispatcher := range released {
+		manager.closeQueue(id)
+		manager.release(id, dispatcher)
+	}
+}
+
+// release 释放会话的输入。State 只包含按键和鼠标按钮，触点、笔、相对指针模式和组字也需要重置，
+// 所以总是调用 Release，没有需要释放的输入时它什么也不注入
+func (manager *Manager) release(sessionID string, dispatcher *input.Dispatcher) {
+	if state := dispatcher.State(); len(state.Keys) > 0 || len(state.Buttons) > 0 {
+		manager.logger.Info("Releasing pressed input", "session_id", sessionID, "keys", len(state.Keys), "buttons", len(state.Buttons))
+	}
+	if err := dispatcher.Release(); err != nil {
+		manager.logger.Warn("Failed to release pressed input", "session_id", sessionID, "error", err)
+	}
+}
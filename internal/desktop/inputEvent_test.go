@@ -0,0 +1,93 @@
+package desktop
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/input/inputtest"
+	"github.com/m4n5ter/lindows/internal/types/message"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+func newTestManager() (*Manager, *inputtest.Recorder) {
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 1000, 1000))
+
+	return &Manager{
+		logger: yalog.Default().With("module", "desktop"),
+		config: &config.Desktop{},
+		input:  recorder,
+		dispatchers: &dispatchers{
+			sessions: make(map[string]*input.Dispatcher),
+			queues:   make(map[string]*input.Queue),
+		},
+		scale: 1,
+	}, recorder
+}
+
+// dispatch 把消息加入会话的队列并等待它们被注入，关闭队列时还没有注入的消息会被丢弃
+func dispatch(t *testing.T, manager *Manager, sessionID string, msgs ...message.Message) {
+	t.Helper()
+
+	before := manager.InputStats().Total.Dispatched
+	for _, msg := range msgs {
+		manager.DispatchInput(sessionID, msg)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for manager.InputStats().Total.Dispatched-before < uint64(len(msgs)) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out dispatching %d messages", len(msgs))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReleaseInputPointers(t *testing.T) {
+	manager, recorder := newTestManager()
+
+	touchDown := message.Message{Event: message.Touch, P1: 1000, P2: 1000, P3: message.PointerDown, P4: `{"id":1}`}
+	penDown := message.Message{Event: message.Pen, P1: 5000, P2: 5000, P3: message.PointerDown, P4: `{"pressure":0.5}`}
+
+	// 只有触点的会话断开时取消触点
+	dispatch(t, manager, "touch", touchDown)
+	recorder.Reset()
+	manager.ReleaseInput("touch")
+	recorder.Assert(t, inputtest.Touch(input.Contact{ID: 0, X: 100, Y: 100, State: input.ContactCancel}))
+
+	// 笔按下的会话失去控制权时抬起笔
+	dispatch(t, manager, "pen", penDown)
+	recorder.Reset()
+	manager.ReleaseInputExcept("other")
+	recorder.Assert(t,
+		inputtest.PenEvent(input.Pen{X: 500, Y: 500, State: input.PenUp, Pressure: 512}),
+		inputtest.PenEvent(input.Pen{X: 500, Y: 500, State: input.PenLeave}),
+	)
+	manager.ReleaseInput("pen")
+}
+
+func TestReleaseInputRelative(t *testing.T) {
+	manager, recorder := newTestManager()
+
+	move := message.Message{Event: message.MouseMove, P1: 5000, P2: 5000}
+
+	// 相对指针模式下绝对坐标的移动被忽略
+	dispatch(t, manager, "a", message.Message{Event: message.PointerLock, P1: 1}, move)
+	recorder.Assert(t)
+	if !manager.PointerLocked("a") {
+		t.Fatal("session is not in relative mode")
+	}
+
+	// 失去控制权后退出相对指针模式，重新获得控制权时不需要 Pointer Lock 也能移动
+	manager.ReleaseInputExcept("b")
+	if manager.PointerLocked("a") {
+		t.Error("session is still in relative mode after losing control")
+	}
+	recorder.Assert(t)
+
+	dispatch(t, manager, "a", move)
+	recorder.Assert(t, inputtest.Move(500, 500))
+	manager.ReleaseInput("a")
+}
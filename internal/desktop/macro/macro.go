@@ -0,0 +1,137 @@
+// Package macro 录制和回放注入的键盘鼠标事件
+//
+// 宏文件是 JSON Lines：第一行是 Header，之后每行一个 Event，时间相对录制开始。
+package macro
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+)
+
+// Format 和 Version 标识宏文件，格式不兼容时增加 Version
+const (
+	Format  = "lindows-macro"
+	Version = 1
+)
+
+var ErrVersion = errors.New("unsupported macro version")
+
+// Op 是事件对应的 Injector 操作
+type Op string
+
+const (
+	OpMove         Op = "move"
+	OpMoveRelative Op = "move_relative"
+	OpButtonDown   Op = "button_down"
+	OpButtonUp     Op = "button_up"
+	OpWheel        Op = "wheel"
+	OpHWheel       Op = "hwheel"
+	OpKeyDown      Op = "key_down"
+	OpKeyUp        Op = "key_up"
+	OpText         Op = "text"
+	OpTouch        Op = "touch"
+	OpPen          Op = "pen"
+)
+
+// Header 是宏文件的第一行
+type Header struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	// Bounds 是录制时的虚拟桌面范围，回放时把坐标按比例换算到当前的范围
+	Bounds   image.Rectangle `json:"bounds"`
+	Recorded time.Time       `json:"recorded"`
+}
+
+// Event 是一次注入操作，只有与 Op 相关的字段有值
+type Event struct {
+	// Time 是相对录制开始的毫秒数
+	Time int64 `json:"t"`
+	Op   Op    `json:"op"`
+	// X 和 Y 是坐标或相对移动量，Wheel 和 HWheel 的滚动量在 X 中
+	X        int             `json:"x,omitempty"`
+	Y        int             `json:"y,omitempty"`
+	Button   input.Button    `json:"button,omitempty"`
+	Key      *input.Key      `json:"key,omitempty"`
+	Text     string          `json:"text,omitempty"`
+	Contacts []input.Contact `json:"contacts,omitempty"`
+	Pen      *input.Pen      `json:"pen,omitempty"`
+}
+
+// Macro 是一段录制好的事件
+type Macro struct {
+	Header Header
+	Events []Event
+}
+
+// Duration 返回以原速回放需要的时间
+func (macro *Macro) Duration() time.Duration {
+	if len(macro.Events) == 0 {
+		return 0
+	}
+	return time.Duration(macro.Events[len(macro.Events)-1].Time) * time.Millisecond
+}
+
+// Encode 把宏写成 JSON Lines
+func Encode(w io.Writer, macro *Macro) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	header := macro.Header
+	header.Format, header.Version = Format, Version
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+
+	for _, event := range macro.Events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode 读取 Encode 写出的宏，拒绝更新版本的文件
+func Decode(r io.Reader) (*Macro, error) {
+	scanner := bufio.NewScanner(r)
+	// Text 事件可能包含整段文本
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty macro")
+	}
+
+	macro := &Macro{}
+	if err := json.Unmarshal(scanner.Bytes(), &macro.Header); err != nil {
+		return nil, fmt.Errorf("invalid macro header: %w", err)
+	}
+	if macro.Header.Format != Format {
+		return nil, fmt.Errorf("not a macro file: format %q", macro.Header.Format)
+	}
+	if macro.Header.Version < 1 || macro.Header.Version > Version {
+		return nil, fmt.Errorf("%w %d, want %d", ErrVersion, macro.Header.Version, Version)
+	}
+
+	for line := 2; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("invalid macro event on line %d: %w", line, err)
+		}
+		macro.Events = append(macro.Events, event)
+	}
+
+	return macro, scanner.Err()
+}
@@ -0,0 +1,253 @@
+package macro_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/input/inputtest"
+	"github.com/m4n5ter/lindows/internal/desktop/macro"
+)
+
+var keyA = input.Key{Scancode: 0x1e, VK: 0x41}
+
+func TestEncodeDecode(t *testing.T) {
+	want := &macro.Macro{
+		Header: macro.Header{
+			Format:   macro.Format,
+			Version:  macro.Version,
+			Bounds:   image.Rect(-1920, 0, 1920, 1080),
+			Recorded: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		},
+		Events: []macro.Event{
+			{Time: 0, Op: macro.OpMove, X: 10, Y: 20},
+			{Time: 15, Op: macro.OpButtonDown, Button: input.ButtonRight},
+			{Time: 30, Op: macro.OpKeyDown, Key: &keyA},
+			{Time: 31, Op: macro.OpText, Text: "你好 <b>"},
+			{Time: 40, Op: macro.OpTouch, Contacts: []input.Contact{{ID: 1, X: 5, Y: 6, State: input.ContactDown}}},
+			{Time: 50, Op: macro.OpPen, Pen: &input.Pen{X: 1, Y: 2, State: input.PenDown, Pressure: 512}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := macro.Encode(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != len(want.Events)+1 {
+		t.Errorf("encoded %d lines, want %d:\n%s", lines, len(want.Events)+1, buf.String())
+	}
+
+	got, err := macro.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode(Encode(m)) = %+v, want %+v", got, want)
+	}
+	if got.Duration() != 50*time.Millisecond {
+		t.Errorf("Duration() = %v, want 50ms", got.Duration())
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		name, data string
+		version    bool
+	}{
+		{name: "empty", data: ""},
+		{name: "not json", data: "hello\n"},
+		{name: "wrong format", data: `{"format":"other","version":1}` + "\n"},
+		{name: "newer version", data: `{"format":"lindows-macro","version":2}` + "\n", version: true},
+		{name: "zero version", data: `{"format":"lindows-macro"}` + "\n", version: true},
+		{name: "bad event", data: `{"format":"lindows-macro","version":1}` + "\n{\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := macro.Decode(strings.NewReader(tt.data))
+			if err == nil {
+				t.Fatal("Decode succeeded, want error")
+			}
+			if errors.Is(err, macro.ErrVersion) != tt.version {
+				t.Errorf("Decode error %v, ErrVersion = %t, want %t", err, !tt.version, tt.version)
+			}
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	inner := inputtest.NewRecorder(image.Rect(0, 0, 800, 600))
+	recorder := macro.NewRecorder(inner)
+
+	// 录制开始前的事件照常转发但不记录
+	if err := recorder.MoveAbsolute(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorder.Stop(); !errors.Is(err, macro.ErrNotRecording) {
+		t.Errorf("Stop before Start = %v, want ErrNotRecording", err)
+	}
+
+	if err := recorder.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Start(); !errors.Is(err, macro.ErrRecording) {
+		t.Errorf("second Start = %v, want ErrRecording", err)
+	}
+	if !recorder.Recording() {
+		t.Error("Recording() = false after Start")
+	}
+
+	recorder.KeyDown(keyA)
+	recorder.KeyUp(keyA)
+	// 注入失败的事件不记录
+	inner.Err = errors.New("injection failed")
+	recorder.Wheel(120)
+	inner.Err = nil
+	recorder.Wheel(-120)
+
+	m, err := recorder.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Recording() {
+		t.Error("Recording() = true after Stop")
+	}
+
+	inner.Assert(t,
+		inputtest.Move(1, 1),
+		inputtest.KeyDown(keyA), inputtest.KeyUp(keyA),
+		inputtest.Wheel(120), inputtest.Wheel(-120),
+	)
+
+	if m.Header.Bounds != image.Rect(0, 0, 800, 600) {
+		t.Errorf("recorded bounds %v", m.Header.Bounds)
+	}
+	var ops []macro.Op
+	for i, event := range m.Events {
+		ops = append(ops, event.Op)
+		if i > 0 && event.Time < m.Events[i-1].Time {
+			t.Errorf("event %d time %d before previous %d", i, event.Time, m.Events[i-1].Time)
+		}
+	}
+	if want := []macro.Op{macro.OpKeyDown, macro.OpKeyUp, macro.OpWheel}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("recorded ops %v, want %v", ops, want)
+	}
+}
+
+func TestPlayScalesCoordinates(t *testing.T) {
+	m := &macro.Macro{
+		Header: macro.Header{Bounds: image.Rect(0, 0, 1000, 500)},
+		Events: []macro.Event{
+			{Op: macro.OpMove, X: 500, Y: 250},
+			{Op: macro.OpMoveRelative, X: 3, Y: 4},
+			{Op: macro.OpButtonDown, Button: input.ButtonLeft},
+			{Op: macro.OpButtonUp, Button: input.ButtonLeft},
+			{Op: macro.OpPen, Pen: &input.Pen{X: 1000, Y: 0, State: input.PenHover}},
+		},
+	}
+
+	recorder := inputtest.NewRecorder(image.Rect(-2000, 0, 0, 1000))
+	if err := macro.Play(context.Background(), m, recorder, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.Assert(t,
+		inputtest.Move(-1000, 500),
+		// 相对移动不换算
+		inputtest.MoveRelative(3, 4),
+		inputtest.ButtonDown(input.ButtonLeft),
+		inputtest.ButtonUp(input.ButtonLeft),
+		inputtest.PenEvent(input.Pen{X: 0, Y: 0, State: input.PenHover}),
+		// 回放结束时笔离开感应范围
+		inputtest.PenEvent(input.Pen{X: 0, Y: 0, State: input.PenLeave}),
+	)
+}
+
+func TestPlaySpeed(t *testing.T) {
+	m := &macro.Macro{Events: []macro.Event{
+		{Time: 0, Op: macro.OpWheel, X: 1},
+		{Time: 400, Op: macro.OpWheel, X: 2},
+	}}
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+
+	start := time.Now()
+	if err := macro.Play(context.Background(), m, recorder, 8); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 300*time.Millisecond {
+		t.Errorf("playing 400ms at 8x took %v, want about 50ms", elapsed)
+	}
+	recorder.Assert(t, inputtest.Wheel(1), inputtest.Wheel(2))
+
+	if err := macro.Play(context.Background(), m, recorder, 0); err == nil {
+		t.Error("Play with speed 0 succeeded")
+	}
+}
+
+func TestPlayCancelReleases(t *testing.T) {
+	m := &macro.Macro{Events: []macro.Event{
+		{Time: 0, Op: macro.OpKeyDown, Key: &keyA},
+		{Time: 0, Op: macro.OpButtonDown, Button: input.ButtonMiddle},
+		{Time: 0, Op: macro.OpTouch, Contacts: []input.Contact{{ID: 0, X: 1, Y: 2, State: input.ContactDown}}},
+		{Time: time.Hour.Milliseconds(), Op: macro.OpKeyUp, Key: &keyA},
+	}}
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 100, 100))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := macro.Play(ctx, m, recorder, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Play = %v, want DeadlineExceeded", err)
+	}
+
+	recorder.Assert(t,
+		inputtest.KeyDown(keyA),
+		inputtest.ButtonDown(input.ButtonMiddle),
+		inputtest.Touch(input.Contact{ID: 0, X: 1, Y: 2, State: input.ContactDown}),
+		inputtest.ButtonUp(input.ButtonMiddle),
+		inputtest.KeyUp(keyA),
+		inputtest.Touch(input.Contact{ID: 0, X: 1, Y: 2, State: input.ContactCancel}),
+	)
+}
+
+func TestPlayCancelLiftsPen(t *testing.T) {
+	down := input.Pen{X: 10, Y: 20, State: input.PenDown, Pressure: 512}
+	move := input.Pen{X: 30, Y: 40, State: input.PenMove, Pressure: 600, TiltX: 5}
+	m := &macro.Macro{
+		Header: macro.Header{Bounds: image.Rect(0, 0, 100, 100)},
+		Events: []macro.Event{
+			{Time: 0, Op: macro.OpPen, Pen: &down},
+			{Time: 0, Op: macro.OpPen, Pen: &move},
+			{Time: time.Hour.Milliseconds(), Op: macro.OpPen, Pen: &input.Pen{X: 30, Y: 40, State: input.PenUp}},
+		},
+	}
+	// 回放的桌面是录制时的两倍，抬起的位置同样换算
+	recorder := inputtest.NewRecorder(image.Rect(0, 0, 200, 200))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := macro.Play(ctx, m, recorder, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Play = %v, want DeadlineExceeded", err)
+	}
+
+	recorder.Assert(t,
+		inputtest.PenEvent(input.Pen{X: 20, Y: 40, State: input.PenDown, Pressure: 512}),
+		inputtest.PenEvent(input.Pen{X: 60, Y: 80, State: input.PenMove, Pressure: 600, TiltX: 5}),
+		inputtest.PenEvent(input.Pen{X: 60, Y: 80, State: input.PenUp, Pressure: 600, TiltX: 5}),
+		inputtest.PenEvent(input.Pen{X: 60, Y: 80, State: input.PenLeave, TiltX: 5}),
+	)
+
+	// 笔已经离开感应范围时不需要再抬起
+	leave := input.Pen{X: 30, Y: 40, State: input.PenLeave}
+	m.Events = []macro.Event{{Op: macro.OpPen, Pen: &down}, {Op: macro.OpPen, Pen: &leave}}
+	if err := macro.Play(context.Background(), m, recorder, 1); err != nil {
+		t.Fatal(err)
+	}
+	recorder.Assert(t,
+		inputtest.PenEvent(input.Pen{X: 20, Y: 40, State: input.PenDown, Pressure: 512}),
+		inputtest.PenEvent(input.Pen{X: 60, Y: 80, State: input.PenLeave}),
+	)
+}
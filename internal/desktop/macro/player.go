@@ -0,0 +1,166 @@
+package macro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+)
+
+// Play 通过 injector 回放宏，speed 为 1 时按原速，2 时快一倍。
+//
+// 录制和回放时的虚拟桌面范围不同时坐标按比例换算。ctx 取消或注入失败时停止回放，
+// 并释放回放中按下的按键、鼠标按钮和触点，抬起还接触着屏幕的笔。
+func Play(ctx context.Context, macro *Macro, injector input.Injector, speed float64) error {
+	if speed <= 0 {
+		return fmt.Errorf("invalid macro speed %v", speed)
+	}
+
+	tracker := input.NewTracker(injector)
+	scale := scaler(macro.Header.Bounds, injector.Bounds())
+	// touch 和 pen 是最后回放的触摸和笔事件，结束时用来取消触点和抬起笔
+	var touch, pen *Event
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	start := time.Now()
+	var err error
+	for i := range macro.Events {
+		event := &macro.Events[i]
+		// 按开始时间计算每个事件的时刻，注入的耗时不会累积
+		due := start.Add(time.Duration(float64(event.Time) * float64(time.Millisecond) / speed))
+		if wait := time.Until(due); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			break
+		}
+
+		if err = play(tracker, *event, scale); err != nil {
+			err = fmt.Errorf("%s at %dms: %w", event.Op, event.Time, err)
+			break
+		}
+		switch event.Op {
+		case OpTouch:
+			touch = event
+		case OpPen:
+			pen = event
+		}
+	}
+
+	errs := []error{err, tracker.Release()}
+	if touch != nil {
+		errs = append(errs, cancelTouch(tracker, *touch, scale))
+	}
+	if pen != nil {
+		errs = append(errs, liftPen(tracker, *pen.Pen, scale))
+	}
+	return errors.Join(errs...)
+}
+
+func play(injector input.Injector, event Event, scale func(image.Point) image.Point) error {
+	switch event.Op {
+	case OpMove:
+		point := scale(image.Pt(event.X, event.Y))
+		return injector.MoveAbsolute(point.X, point.Y)
+	case OpMoveRelative:
+		return injector.MoveRelative(event.X, event.Y)
+	case OpButtonDown:
+		return injector.ButtonDown(event.Button)
+	case OpButtonUp:
+		return injector.ButtonUp(event.Button)
+	case OpWheel:
+		return injector.Wheel(event.X)
+	case OpHWheel:
+		return injector.HWheel(event.X)
+	case OpKeyDown, OpKeyUp:
+		if event.Key == nil {
+			return fmt.Errorf("missing key")
+		}
+		if event.Op == OpKeyDown {
+			return injector.KeyDown(*event.Key)
+		}
+		return injector.KeyUp(*event.Key)
+	case OpText:
+		return injector.Text(event.Text)
+	case OpTouch:
+		contacts := make([]input.Contact, len(event.Contacts))
+		for i, contact := range event.Contacts {
+			point := scale(image.Pt(contact.X, contact.Y))
+			contact.X, contact.Y = point.X, point.Y
+			if !contact.Area.Empty() {
+				contact.Area = image.Rectangle{Min: scale(contact.Area.Min), Max: scale(contact.Area.Max)}
+			}
+			contacts[i] = contact
+		}
+		return injector.Touch(contacts)
+	case OpPen:
+		if event.Pen == nil {
+			return fmt.Errorf("missing pen")
+		}
+		pen := *event.Pen
+		point := scale(image.Pt(pen.X, pen.Y))
+		pen.X, pen.Y = point.X, point.Y
+		return injector.Pen(pen)
+	default:
+		return fmt.Errorf("unknown macro op %q", event.Op)
+	}
+}
+
+// cancelTouch 取消最后回放的一帧触摸中还按着的触点
+func cancelTouch(injector input.Injector, last Event, scale func(image.Point) image.Point) error {
+	var contacts []input.Contact
+	for _, contact := range last.Contacts {
+		if contact.State == input.ContactDown || contact.State == input.ContactUpdate {
+			contact.State = input.ContactCancel
+			contacts = append(contacts, contact)
+		}
+	}
+	if len(contacts) == 0 {
+		return nil
+	}
+	return play(injector, Event{Op: OpTouch, Contacts: contacts}, scale)
+}
+
+// liftPen 让最后回放的笔抬起并离开感应范围，与 Dispatcher 释放笔的方式相同
+func liftPen(injector input.Injector, last input.Pen, scale func(image.Point) image.Point) error {
+	if last.State == input.PenLeave {
+		return nil
+	}
+
+	var errs []error
+	if last.State == input.PenDown || last.State == input.PenMove {
+		last.State = input.PenUp
+		errs = append(errs, play(injector, Event{Op: OpPen, Pen: &last}, scale))
+	}
+	leave := last
+	leave.State, leave.Pressure = input.PenLeave, 0
+	errs = append(errs, play(injector, Event{Op: OpPen, Pen: &leave}, scale))
+	return errors.Join(errs...)
+}
+
+// scaler 返回把 from 中的坐标按比例换算到 to 的函数，范围相同或未知时不换算
+func scaler(from, to image.Rectangle) func(image.Point) image.Point {
+	if from.Empty() || to.Empty() || from == to {
+		return func(point image.Point) image.Point { return point }
+	}
+
+	return func(point image.Point) image.Point {
+		return image.Pt(
+			to.Min.X+(point.X-from.Min.X)*to.Dx()/from.Dx(),
+			to.Min.Y+(point.Y-from.Min.Y)*to.Dy()/from.Dy(),
+		)
+	}
+}
@@ -0,0 +1,135 @@
+package macro
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+)
+
+var (
+	ErrRecording    = errors.New("macro is already recording")
+	ErrNotRecording = errors.New("macro is not recording")
+)
+
+// Recorder 是转发给另一个 Injector 的 Injector，录制时记录每次成功的注入
+type Recorder struct {
+	input.Injector
+
+	mu        sync.Mutex
+	recording *Macro
+	start     time.Time
+
+	// now 用于测试
+	now func() time.Time
+}
+
+func NewRecorder(injector input.Injector) *Recorder {
+	return &Recorder{
+		Injector: injector,
+		now:      time.Now,
+	}
+}
+
+// Start 开始录制
+func (recorder *Recorder) Start() error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if recorder.recording != nil {
+		return ErrRecording
+	}
+
+	recorder.start = recorder.now()
+	recorder.recording = &Macro{
+		Header: Header{
+			Bounds:   recorder.Injector.Bounds(),
+			Recorded: recorder.start.UTC(),
+		},
+	}
+	return nil
+}
+
+// Recording 判断是否正在录制
+func (recorder *Recorder) Recording() bool {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	return recorder.recording != nil
+}
+
+// Stop 停止录制并返回录制的宏
+func (recorder *Recorder) Stop() (*Macro, error) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	macro := recorder.recording
+	if macro == nil {
+		return nil, ErrNotRecording
+	}
+	recorder.recording = nil
+
+	return macro, nil
+}
+
+// record 在 err 为空且正在录制时记录 event，然后返回 err
+func (recorder *Recorder) record(event Event, err error) error {
+	if err != nil {
+		return err
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if recorder.recording != nil {
+		event.Time = recorder.now().Sub(recorder.start).Milliseconds()
+		recorder.recording.Events = append(recorder.recording.Events, event)
+	}
+	return nil
+}
+
+func (recorder *Recorder) MoveAbsolute(x, y int) error {
+	return recorder.record(Event{Op: OpMove, X: x, Y: y}, recorder.Injector.MoveAbsolute(x, y))
+}
+
+func (recorder *Recorder) MoveRelative(dx, dy int) error {
+	return recorder.record(Event{Op: OpMoveRelative, X: dx, Y: dy}, recorder.Injector.MoveRelative(dx, dy))
+}
+
+func (recorder *Recorder) ButtonDown(button input.Button) error {
+	return recorder.record(Event{Op: OpButtonDown, Button: button}, recorder.Injector.ButtonDown(button))
+}
+
+func (recorder *Recorder) ButtonUp(button input.Button) error {
+	return recorder.record(Event{Op: OpButtonUp, Button: button}, recorder.Injector.ButtonUp(button))
+}
+
+func (recorder *Recorder) Wheel(delta int) error {
+	return recorder.record(Event{Op: OpWheel, X: delta}, recorder.Injector.Wheel(delta))
+}
+
+func (recorder *Recorder) HWheel(delta int) error {
+	return recorder.record(Event{Op: OpHWheel, X: delta}, recorder.Injector.HWheel(delta))
+}
+
+func (recorder *Recorder) KeyDown(key input.Key) error {
+	return recorder.record(Event{Op: OpKeyDown, Key: &key}, recorder.Injector.KeyDown(key))
+}
+
+func (recorder *Recorder) KeyUp(key input.Key) error {
+	return recorder.record(Event{Op: OpKeyUp, Key: &key}, recorder.Injector.KeyUp(key))
+}
+
+func (recorder *Recorder) Text(text string) error {
+	return recorder.record(Event{Op: OpText, Text: text}, recorder.Injector.Text(text))
+}
+
+func (recorder *Recorder) Touch(contacts []input.Contact) error {
+	frame := append([]input.Contact(nil), contacts...)
+	return recorder.record(Event{Op: OpTouch, Contacts: frame}, recorder.Injector.Touch(contacts))
+}
+
+func (recorder *Recorder) Pen(pen input.Pen) error {
+	return recorder.record(Event{Op: OpPen, Pen: &pen}, recorder.Injector.Pen(pen))
+}
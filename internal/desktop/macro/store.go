@@ -0,0 +1,121 @@
+package macro
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Extension 是宏文件的扩展名
+const Extension = ".macro"
+
+var (
+	ErrNotFound    = errors.New("macro not found")
+	ErrInvalidName = errors.New("invalid macro name")
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]{0,63}$`)
+
+// Store 按名称保存宏，dir 为空时只保存在内存中
+type Store struct {
+	dir string
+
+	mu     sync.Mutex
+	macros map[string]*Macro
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, macros: make(map[string]*Macro)}
+}
+
+// Save 保存宏，覆盖同名的宏
+func (store *Store) Save(name string, macro *Macro) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w %q", ErrInvalidName, name)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.dir == "" {
+		store.macros[name] = macro
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, macro); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(store.dir, 0o755); err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，回放时不会读到写了一半的文件
+	path := filepath.Join(store.dir, name+Extension)
+	if err := os.WriteFile(path+".tmp", buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Load 读取保存的宏
+func (store *Store) Load(name string) (*Macro, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w %q", ErrInvalidName, name)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.dir == "" {
+		macro, ok := store.macros[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return macro, nil
+	}
+
+	file, err := os.Open(filepath.Join(store.dir, name+Extension))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Decode(file)
+}
+
+// List 按字母顺序返回所有宏的名称
+func (store *Store) List() ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var names []string
+	if store.dir == "" {
+		for name := range store.macros {
+			names = append(names, name)
+		}
+	} else {
+		entries, err := os.ReadDir(store.dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), Extension)
+			if ok && entry.Type().IsRegular() && namePattern.MatchString(name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
@@ -0,0 +1,53 @@
+package macro_test
+
+import (
+	"errors"
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/macro"
+)
+
+func TestStore(t *testing.T) {
+	for name, dir := range map[string]string{"memory": "", "dir": t.TempDir()} {
+		t.Run(name, func(t *testing.T) {
+			store := macro.NewStore(dir)
+
+			if _, err := store.Load("missing"); !errors.Is(err, macro.ErrNotFound) {
+				t.Errorf("Load(missing) = %v, want ErrNotFound", err)
+			}
+			for _, invalid := range []string{"", ".hidden", "../escape", "a/b"} {
+				if err := store.Save(invalid, &macro.Macro{}); !errors.Is(err, macro.ErrInvalidName) {
+					t.Errorf("Save(%q) = %v, want ErrInvalidName", invalid, err)
+				}
+			}
+
+			want := &macro.Macro{
+				Header: macro.Header{Format: macro.Format, Version: macro.Version, Bounds: image.Rect(0, 0, 10, 10)},
+				Events: []macro.Event{{Time: 5, Op: macro.OpWheel, X: 120}},
+			}
+			for _, name := range []string{"login", "build-1.2"} {
+				if err := store.Save(name, want); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := store.Load("login")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Load = %+v, want %+v", got, want)
+			}
+
+			names, err := store.List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"build-1.2", "login"}; !reflect.DeepEqual(names, want) {
+				t.Errorf("List() = %v, want %v", names, want)
+			}
+		})
+	}
+}
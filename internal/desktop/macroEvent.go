@@ -0,0 +1,114 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/m4n5ter/lindows/internal/desktop/macro"
+	"github.com/m4n5ter/lindows/internal/session"
+)
+
+var (
+	ErrMacroPlaying    = errors.New("macro is already playing")
+	ErrMacroNotPlaying = errors.New("macro is not playing")
+)
+
+// macros 录制所有会话注入的事件，同一时间只回放一个宏
+type macros struct {
+	recorder *macro.Recorder
+	store    *macro.Store
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// AuthorizeMacro 检查会话是否可以录制和回放宏，会话必须拥有控制权和 macro 权限
+func (manager *Manager) AuthorizeMacro(requester *session.Session) error {
+	if !requester.IsController() || !requester.Can(session.PermissionMacro) {
+		manager.logger.Warn("Macro denied", "session_id", requester.ID(), "permissions", requester.Permissions())
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// StartRecording 开始录制注入的事件
+func (manager *Manager) StartRecording() error {
+	if err := manager.macros.recorder.Start(); err != nil {
+		return err
+	}
+
+	manager.logger.Info("Macro recording started")
+	return nil
+}
+
+// StopRecording 停止录制并返回录制的宏，name 不为空时按名称保存
+func (manager *Manager) StopRecording(name string) (*macro.Macro, error) {
+	recorded, err := manager.macros.recorder.Stop()
+	if err != nil {
+		return nil, err
+	}
+
+	manager.logger.Info("Macro recording stopped", "name", name, "events", len(recorded.Events), "duration", recorded.Duration())
+
+	if name != "" {
+		if err := manager.macros.store.Save(name, recorded); err != nil {
+			return recorded, err
+		}
+	}
+	return recorded, nil
+}
+
+// Macro 返回按名称保存的宏
+func (manager *Manager) Macro(name string) (*macro.Macro, error) {
+	return manager.macros.store.Load(name)
+}
+
+// Macros 返回所有保存的宏的名称
+func (manager *Manager) Macros() ([]string, error) {
+	return manager.macros.store.List()
+}
+
+// PlayMacro 以 speed 倍速回放宏，直到回放结束、ctx 取消或调用 CancelMacro。
+//
+// 回放直接使用底层的 Injector，回放的事件不会被正在进行的录制记录。
+func (manager *Manager) PlayMacro(ctx context.Context, recorded *macro.Macro, speed float64) error {
+	manager.macros.mu.Lock()
+	if manager.macros.cancel != nil {
+		manager.macros.mu.Unlock()
+		return ErrMacroPlaying
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	manager.macros.cancel = cancel
+	manager.macros.mu.Unlock()
+
+	defer func() {
+		manager.macros.mu.Lock()
+		manager.macros.cancel = nil
+		manager.macros.mu.Unlock()
+		cancel()
+	}()
+
+	manager.logger.Info("Macro playback started", "events", len(recorded.Events), "duration", recorded.Duration(), "speed", speed)
+
+	err := macro.Play(ctx, recorded, manager.macros.recorder.Injector, speed)
+	if err != nil {
+		manager.logger.Warn("Macro playback stopped", "error", err)
+		return err
+	}
+
+	manager.logger.Info("Macro playback finished")
+	return nil
+}
+
+// CancelMacro 停止正在进行的回放
+func (manager *Manager) CancelMacro() error {
+	manager.macros.mu.Lock()
+	defer manager.macros.mu.Unlock()
+
+	if manager.macros.cancel == nil {
+		return ErrMacroNotPlaying
+	}
+	manager.macros.cancel()
+	return nil
+}
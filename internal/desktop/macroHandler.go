@@ -0,0 +1,132 @@
+package desktop
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/m4n5ter/lindows/internal/desktop/macro"
+)
+
+// MaxMacroSize 是通过 HTTP 上传回放的宏的最大字节数
+const MaxMacroSize = 16 << 20
+
+// MacroHandler 返回宏的 HTTP 接口:
+//
+//	GET  /macros                      列出保存的宏
+//	GET  /macros/{name}               下载保存的宏
+//	POST /macros/record               开始录制
+//	POST /macros/stop?name=           停止录制并返回宏，name 不为空时按名称保存
+//	POST /macros/play?name=&speed=    回放保存的宏，没有 name 时回放请求体中的宏，回放结束后才返回
+//	POST /macros/cancel               停止正在进行的回放
+func (manager *Manager) MacroHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /macros", func(w http.ResponseWriter, r *http.Request) {
+		names, err := manager.Macros()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if names == nil {
+			names = []string{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(names)
+	})
+
+	mux.HandleFunc("GET /macros/{name}", func(w http.ResponseWriter, r *http.Request) {
+		recorded, err := manager.Macro(r.PathValue("name"))
+		if err != nil {
+			http.Error(w, err.Error(), macroStatus(err))
+			return
+		}
+		writeMacro(w, recorded)
+	})
+
+	mux.HandleFunc("POST /macros/record", func(w http.ResponseWriter, r *http.Request) {
+		if err := manager.StartRecording(); err != nil {
+			http.Error(w, err.Error(), macroStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("POST /macros/stop", func(w http.ResponseWriter, r *http.Request) {
+		recorded, err := manager.StopRecording(r.URL.Query().Get("name"))
+		if err != nil {
+			http.Error(w, err.Error(), macroStatus(err))
+			return
+		}
+		writeMacro(w, recorded)
+	})
+
+	mux.HandleFunc("POST /macros/play", func(w http.ResponseWriter, r *http.Request) {
+		speed := 1.0
+		if value := r.URL.Query().Get("speed"); value != "" {
+			var err error
+			if speed, err = strconv.ParseFloat(value, 64); err != nil || speed <= 0 {
+				http.Error(w, "invalid speed", http.StatusBadRequest)
+				return
+			}
+		}
+
+		var recorded *macro.Macro
+		var err error
+		if name := r.URL.Query().Get("name"); name != "" {
+			recorded, err = manager.Macro(name)
+		} else if recorded, err = macro.Decode(http.MaxBytesReader(w, r.Body, MaxMacroSize)); err != nil {
+			// 请求体中的宏无法解析都算作客户端的错误
+			var maxBytes *http.MaxBytesError
+			if !errors.As(err, &maxBytes) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if err != nil {
+			http.Error(w, err.Error(), macroStatus(err))
+			return
+		}
+
+		// 客户端断开时 r.Context() 被取消，回放随之停止
+		if err := manager.PlayMacro(r.Context(), recorded, speed); err != nil {
+			http.Error(w, err.Error(), macroStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("POST /macros/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if err := manager.CancelMacro(); err != nil {
+			http.Error(w, err.Error(), macroStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
+
+func writeMacro(w http.ResponseWriter, recorded *macro.Macro) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	_ = macro.Encode(w, recorded)
+}
+
+func macroStatus(err error) int {
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.Is(err, macro.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, macro.ErrRecording), errors.Is(err, macro.ErrNotRecording),
+		errors.Is(err, ErrMacroPlaying), errors.Is(err, ErrMacroNotPlaying):
+		return http.StatusConflict
+	case errors.As(err, &maxBytes):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, macro.ErrInvalidName):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
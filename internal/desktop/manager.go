@@ -1,7 +1,15 @@
 package desktop
 
 import (
+	"time"
+
 	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/desktop/clipboard"
+	"github.com/m4n5ter/lindows/internal/desktop/command"
+	"github.com/m4n5ter/lindows/internal/desktop/cursor"
+	"github.com/m4n5ter/lindows/internal/desktop/input"
+	"github.com/m4n5ter/lindows/internal/desktop/macro"
+	"github.com/m4n5ter/lindows/internal/desktop/screenshot"
 	"github.com/m4n5ter/lindows/pkg/yalog"
 )
 
@@ -10,16 +18,89 @@ type Manager struct {
 	shutdown                chan struct{}
 	config                  *config.Desktop
 	screenSizeChangeChannel chan bool
+	screenshot              screenshot.Grabber
+	cursor                  *cursor.Watcher
+	clipboard               clipboard.Clipboard
+	clipboardSync           *clipboard.Syncer
+	input                   input.Injector
+	dispatchers             *dispatchers
+	commands                *command.Runner
+	macros                  *macros
+	scale                   float64
 }
 
+// 光标的轮询间隔，约 60Hz
+const cursorInterval = 16 * time.Millisecond
+
+// 剪切板序号的轮询间隔，读取序号不需要打开剪切板，开销很小
+const clipboardInterval = 250 * time.Millisecond
+
 func New(cfg *config.Desktop) *Manager {
-	return &Manager{
+	manager := &Manager{
 		logger:                  yalog.Default().With("module", "desktop"),
 		shutdown:                make(chan struct{}),
 		config:                  cfg,
 		screenSizeChangeChannel: make(chan bool),
+		screenshot:              screenshot.New(),
+		clipboard:               clipboard.New(),
+		scale:                   displayScale(),
+	}
+
+	injector, err := input.New()
+	if err != nil {
+		manager.logger.Error("Failed to create input injector, remote control is disabled", "error", err)
+		injector = input.Unsupported{Err: err}
+	}
+	// 所有会话的输入都经过录制器，录制宏时才会记录
+	recorder := macro.NewRecorder(injector)
+	manager.input = recorder
+	manager.macros = &macros{recorder: recorder, store: macro.NewStore(cfg.MacroDir)}
+	manager.dispatchers = &dispatchers{
+		sessions: make(map[string]*input.Dispatcher),
+		queues:   make(map[string]*input.Queue),
+	}
+	manager.commands = command.NewRunner(command.NewSystem(), injector)
+
+	if cfg.Cursor {
+		source := cursor.NewSource()
+		if !cfg.Region.Empty() {
+			source = regionSource{Source: source, region: cfg.Region, scale: manager.scale}
+		}
+		manager.cursor = cursor.NewWatcher(source, cursorInterval)
+	}
+
+	if cfg.Clipboard {
+		manager.clipboardSync = clipboard.NewSyncer(manager.clipboard, clipboardInterval, clipboard.Limits{
+			Text:  cfg.ClipboardMaxSize,
+			Image: cfg.ClipboardMaxImageSize,
+			Rich:  cfg.ClipboardMaxRichSize,
+		})
 	}
+
+	return manager
 }
 
 func (manager *Manager) Start() {
+	if manager.cursor != nil {
+		manager.cursor.Start()
+	}
+	if manager.clipboardSync != nil {
+		manager.clipboardSync.Start()
+	}
+}
+
+func (manager *Manager) Shutdown() {
+	if manager.cursor != nil {
+		manager.cursor.Shutdown()
+	}
+	if manager.clipboardSync != nil {
+		manager.clipboardSync.Shutdown()
+	}
+
+	_ = manager.CancelMacro()
+	manager.ReleaseInputExcept("")
+
+	if err := manager.input.Close(); err != nil {
+		manager.logger.Error("Failed to close input injector", "error", err)
+	}
 }
@@ -0,0 +1,38 @@
+package desktop
+
+import (
+	"image"
+	"math"
+
+	"github.com/m4n5ter/lindows/internal/desktop/cursor"
+)
+
+// Region 返回共享的桌面区域，以虚拟桌面的物理像素表示，为空时共享整个虚拟桌面
+func (manager *Manager) Region() image.Rectangle {
+	return manager.config.Region
+}
+
+// regionSource 把光标位置换算成相对共享区域的位置，与视频画面一致
+type regionSource struct {
+	cursor.Source
+	region image.Rectangle
+	scale  float64
+}
+
+func (source regionSource) Current() (uintptr, cursor.Position, error) {
+	handle, position, err := source.Source.Current()
+	if err != nil {
+		return handle, position, err
+	}
+
+	// 光标坐标是相对虚拟屏幕左上角的逻辑像素，区域是物理像素
+	originX, originY := virtualOrigin()
+	region := image.Rect(
+		int(math.Floor(float64(source.region.Min.X)/source.scale))-originX,
+		int(math.Floor(float64(source.region.Min.Y)/source.scale))-originY,
+		int(math.Ceil(float64(source.region.Max.X)/source.scale))-originX,
+		int(math.Ceil(float64(source.region.Max.Y)/source.scale))-originY,
+	)
+
+	return handle, position.Crop(region), nil
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package desktop
+
+// displayScale 在这个平台上不做缩放
+func displayScale() float64 {
+	return 1
+}
+
+// virtualOrigin 在这个平台上虚拟屏幕从 (0, 0) 开始
+func virtualOrigin() (int, int) {
+	return 0, 0
+}
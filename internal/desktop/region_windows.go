@@ -0,0 +1,28 @@
+package desktop
+
+import "github.com/m4n5ter/lindows/winapi"
+
+// displayScale 返回主显示器物理像素与本进程看到的逻辑像素之比。
+//
+// 进程没有声明感知 DPI，系统缩放为 150% 时 GetSystemMetrics 返回的是缩放后的尺寸，
+// 而 gdigrab 按物理像素捕获，二者之比就是系统缩放。
+func displayScale() float64 {
+	hdc, err := winapi.GetDC(0)
+	if err != nil {
+		return 1
+	}
+	defer func() { _ = winapi.ReleaseDC(0, hdc) }()
+
+	logical := winapi.GetDeviceCaps(hdc, winapi.HorzRes)
+	physical := winapi.GetDeviceCaps(hdc, winapi.DesktopHorzRes)
+	if logical <= 0 || physical <= 0 {
+		return 1
+	}
+
+	return float64(physical) / float64(logical)
+}
+
+// virtualOrigin 返回虚拟屏幕左上角的逻辑坐标，主显示器左边或上边有显示器时为负数
+func virtualOrigin() (int, int) {
+	return int(winapi.GetSystemMetrics(winapi.SMXVirtualScreen)), int(winapi.GetSystemMetrics(winapi.SMYVirtualScreen))
+}
@@ -0,0 +1,80 @@
+// Package screenshot 抓取整个虚拟桌面的静态截图
+package screenshot
+
+import (
+	"errors"
+	"image"
+	"image/color"
+)
+
+var ErrUnsupported = errors.New("screenshot is not supported on this platform")
+
+// Grabber 抓取一帧屏幕图像
+type Grabber interface {
+	Grab() (image.Image, error)
+}
+
+// Fake 总是返回固定的图像，用于测试
+type Fake struct {
+	Image image.Image
+	Err   error
+}
+
+func (fake Fake) Grab() (image.Image, error) {
+	return fake.Image, fake.Err
+}
+
+// Scale 把 src 缩放到 width x height，缩小时取每个目标像素覆盖区域的平均值
+func Scale(src image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	if sw == 0 || sh == 0 || width <= 0 || height <= 0 {
+		return dst
+	}
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*sh/height
+		y1 := max(bounds.Min.Y+(y+1)*sh/height, y0+1)
+
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*sw/width
+			x1 := max(bounds.Min.X+(x+1)*sw/width, x0+1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+cr, g+cg, b+cb, a+ca
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
+
+// FitSize 计算缩放后的尺寸，width 或 height 为 0 时按原图比例计算
+func FitSize(src image.Rectangle, width, height int) (int, int) {
+	sw, sh := src.Dx(), src.Dy()
+	switch {
+	case sw == 0 || sh == 0:
+		return 0, 0
+	case width <= 0 && height <= 0:
+		return sw, sh
+	case width <= 0:
+		return max(sw*height/sh, 1), height
+	case height <= 0:
+		return width, max(sh*width/sw, 1)
+	default:
+		return width, height
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package screenshot
+
+// New 返回当前平台的截图实现
+func New() Grabber {
+	return Fake{Err: ErrUnsupported}
+}
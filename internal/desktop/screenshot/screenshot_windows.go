@@ -0,0 +1,86 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"runtime"
+	"unsafe"
+
+	"github.com/m4n5ter/lindows/winapi"
+)
+
+// New 返回当前平台的截图实现
+func New() Grabber {
+	return gdiGrabber{}
+}
+
+// gdiGrabber 通过 GDI 的 BitBlt 抓取整个虚拟桌面
+type gdiGrabber struct{}
+
+func (gdiGrabber) Grab() (image.Image, error) {
+	// GetDC 和 ReleaseDC 必须在同一线程调用
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	x := winapi.GetSystemMetrics(winapi.SMXVirtualScreen)
+	y := winapi.GetSystemMetrics(winapi.SMYVirtualScreen)
+	width := winapi.GetSystemMetrics(winapi.SMCXVirtualScreen)
+	height := winapi.GetSystemMetrics(winapi.SMCYVirtualScreen)
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid virtual screen size %dx%d", width, height)
+	}
+
+	hdc, err := winapi.GetDC(0)
+	if err != nil {
+		return nil, fmt.Errorf("GetDC: %v", err)
+	}
+	defer winapi.ReleaseDC(0, hdc)
+
+	memDC, err := winapi.CreateCompatibleDC(hdc)
+	if err != nil {
+		return nil, fmt.Errorf("CreateCompatibleDC: %v", err)
+	}
+	defer winapi.DeleteDC(memDC)
+
+	bitmap, err := winapi.CreateCompatibleBitmap(hdc, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("CreateCompatibleBitmap: %v", err)
+	}
+	defer winapi.DeleteObject(bitmap)
+
+	old, err := winapi.SelectObject(memDC, bitmap)
+	if err != nil {
+		return nil, fmt.Errorf("SelectObject: %v", err)
+	}
+	err = winapi.BitBlt(memDC, 0, 0, width, height, hdc, x, y, winapi.SrcCopy|winapi.CaptureBlt)
+	// GetDIBits 要求位图不能被选入任何 DC
+	_, _ = winapi.SelectObject(memDC, old)
+	if err != nil {
+		return nil, fmt.Errorf("BitBlt: %v", err)
+	}
+
+	info := winapi.BITMAPINFO{
+		BmiHeader: winapi.BITMAPINFOHEADER{
+			BiSize:        uint32(unsafe.Sizeof(winapi.BITMAPINFOHEADER{})),
+			BiWidth:       width,
+			BiHeight:      -height, // 负数表示自上而下的位图
+			BiPlanes:      1,
+			BiBitCount:    32,
+			BiCompression: winapi.BIRGB,
+		},
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	err = winapi.GetDIBits(memDC, bitmap, 0, uint32(height), unsafe.Pointer(&img.Pix[0]), &info, winapi.DIBRGBColors)
+	if err != nil {
+		return nil, fmt.Errorf("GetDIBits: %v", err)
+	}
+
+	// BGRA -> RGBA
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+2] = img.Pix[i+2], img.Pix[i]
+		img.Pix[i+3] = 0xFF
+	}
+
+	return img, nil
+}
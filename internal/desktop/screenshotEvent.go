@@ -0,0 +1,8 @@
+package desktop
+
+import "image"
+
+// Screenshot 抓取整个虚拟桌面的截图
+func (manager *Manager) Screenshot() (image.Image, error) {
+	return manager.screenshot.Grab()
+}
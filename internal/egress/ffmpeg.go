@@ -0,0 +1,94 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/m4n5ter/lindows/pkg/ffmpeg"
+)
+
+// ErrStreamClosed 表示视频源关闭了订阅
+var ErrStreamClosed = errors.New("stream closed")
+
+// RunFFmpeg 以 stream 为输入运行 ffmpeg，直到 ctx 被取消、ffmpeg 退出或视频源关闭。
+//
+// RTP 包被转发到一个本地 UDP 端口，ffmpeg 通过 SDP 文件读取它们，
+// 所以 options 的 Input、InputFormat 和 InputArgs 会被覆盖。
+func RunFFmpeg(ctx context.Context, stream Stream, options ffmpeg.Options) error {
+	port, err := freeUDPPort()
+	if err != nil {
+		return err
+	}
+
+	sdpPath, err := writeSDP(sessionDescription(stream.Codec(), "127.0.0.1", port, ""))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sdpPath)
+
+	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port})
+	if err != nil {
+		return fmt.Errorf("failed to dial udp: %v", err)
+	}
+	defer conn.Close()
+
+	packets, unsubscribe := stream.Subscribe()
+	defer unsubscribe()
+
+	options.InputFormat = ""
+	options.InputArgs = []string{"-protocol_whitelist", "file,udp,rtp"}
+	options.Input = sdpPath
+
+	job := ffmpeg.NewJob(options)
+	if err := job.Start(ctx); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case packet, ok := <-packets:
+			if !ok {
+				_ = job.Stop()
+				return ErrStreamClosed
+			}
+
+			buf, err := packet.Marshal()
+			if err != nil {
+				continue
+			}
+			// ffmpeg 还没有打开端口时发送会失败，忽略即可
+			_, _ = conn.Write(buf)
+
+		case <-job.Done():
+			return job.Wait()
+		}
+	}
+}
+
+func freeUDPPort() (int, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free udp port: %v", err)
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port, nil
+}
+
+func writeSDP(sdp string) (string, error) {
+	file, err := os.CreateTemp("", "lindows*.sdp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create sdp file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(sdp); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write sdp file: %v", err)
+	}
+
+	return file.Name(), nil
+}
@@ -0,0 +1,132 @@
+package egress
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/types/codec"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+	"github.com/pion/rtp"
+)
+
+// Stream 是输出使用的视频源，由 capture.StreamManager 实现
+type Stream interface {
+	Codec() codec.RTPCodec
+	Subscribe() (<-chan rtp.Packet, func())
+}
+
+type Manager struct {
+	logger *yalog.Logger
+	config *config.Egress
+
+	outputs map[string]*Output
+}
+
+func New(stream Stream, cfg *config.Egress) *Manager {
+	logger := yalog.Default().With("module", "egress")
+
+	outputs := make(map[string]*Output, len(cfg.Outputs))
+	for _, o := range cfg.Outputs {
+		var r runner
+		switch o.URL.Scheme {
+		case "rtsp":
+			r = newRTSPRunner(stream, o.URL)
+		default:
+			r = newPushRunner(stream, o.URL.String(), o.URL.Scheme)
+		}
+
+		outputs[o.Name] = newOutput(cfg, o, r, logger)
+	}
+
+	return &Manager{
+		logger:  logger,
+		config:  cfg,
+		outputs: outputs,
+	}
+}
+
+func (manager *Manager) Start() {
+	for _, o := range manager.config.Outputs {
+		if o.Enabled {
+			manager.outputs[o.Name].enable()
+		}
+	}
+}
+
+func (manager *Manager) Shutdown() {
+	for _, output := range manager.outputs {
+		output.disable()
+	}
+}
+
+// Statuses 返回所有输出的状态，按名称排序
+func (manager *Manager) Statuses() []Status {
+	statuses := make([]Status, 0, len(manager.outputs))
+	for _, output := range manager.outputs {
+		statuses = append(statuses, output.Status())
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func (manager *Manager) Enable(name string) (Status, bool) {
+	output, ok := manager.outputs[name]
+	if !ok {
+		return Status{}, false
+	}
+
+	output.enable()
+	return output.Status(), true
+}
+
+func (manager *Manager) Disable(name string) (Status, bool) {
+	output, ok := manager.outputs[name]
+	if !ok {
+		return Status{}, false
+	}
+
+	output.disable()
+	return output.Status(), true
+}
+
+// Handler 返回输出的 HTTP 接口:
+//
+//	GET  /outputs                 列出所有输出的状态
+//	POST /outputs/{name}/enable   启用输出
+//	POST /outputs/{name}/disable  禁用输出
+func (manager *Manager) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /outputs", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, manager.Statuses())
+	})
+
+	mux.HandleFunc("POST /outputs/{name}/enable", func(w http.ResponseWriter, r *http.Request) {
+		manager.respond(w, manager.Enable, r.PathValue("name"))
+	})
+
+	mux.HandleFunc("POST /outputs/{name}/disable", func(w http.ResponseWriter, r *http.Request) {
+		manager.respond(w, manager.Disable, r.PathValue("name"))
+	})
+
+	return mux
+}
+
+func (manager *Manager) respond(w http.ResponseWriter, action func(string) (Status, bool), name string) {
+	status, ok := action(name)
+	if !ok {
+		http.Error(w, "output not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
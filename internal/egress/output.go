@@ -0,0 +1,185 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+// 连续运行超过这个时间后，重连等待时间会恢复为初始值
+const stableDuration = 30 * time.Second
+
+var errOutputEnded = errors.New("output ended unexpectedly")
+
+type State string
+
+const (
+	StateDisabled     State = "disabled"
+	StateStarting     State = "starting"
+	StateRunning      State = "running"
+	StateReconnecting State = "reconnecting"
+)
+
+// Status 是一个输出的运行状态
+type Status struct {
+	Name        string    `json:"name"`
+	Kind        string    `json:"kind"`
+	URL         string    `json:"url"`
+	Enabled     bool      `json:"enabled"`
+	State       State     `json:"state"`
+	Since       time.Time `json:"since"`
+	LastError   string    `json:"last_error,omitempty"`
+	Reconnects  int       `json:"reconnects"`
+	Clients     int       `json:"clients,omitempty"`
+	FPS         float64   `json:"fps,omitempty"`
+	BitrateKbps float64   `json:"bitrate_kbps,omitempty"`
+}
+
+// runner 把视频流发送到某个目的地，直到 ctx 被取消或连接断开
+type runner interface {
+	run(ctx context.Context, output *Output) error
+}
+
+// Output 是一个可以独立启用、禁用的输出，断开后会按指数退避自动重连
+type Output struct {
+	logger *yalog.Logger
+	config *config.Egress
+	runner runner
+
+	mu     sync.Mutex
+	status Status
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newOutput(cfg *config.Egress, output config.EgressOutput, runner runner, logger *yalog.Logger) *Output {
+	return &Output{
+		logger: logger.With("output", output.Name),
+		config: cfg,
+		runner: runner,
+		status: Status{
+			Name:  output.Name,
+			Kind:  output.URL.Scheme,
+			URL:   redactURL(output.URL),
+			State: StateDisabled,
+			Since: time.Now(),
+		},
+	}
+}
+
+func (output *Output) Status() Status {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+
+	return output.status
+}
+
+func (output *Output) enable() {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+
+	if output.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	output.cancel = cancel
+	output.done = make(chan struct{})
+	output.status.Enabled = true
+
+	go output.loop(ctx, output.done)
+	output.logger.Info("Output enabled")
+}
+
+func (output *Output) disable() {
+	output.mu.Lock()
+	cancel, done := output.cancel, output.done
+	output.cancel, output.done = nil, nil
+	output.status.Enabled = false
+	output.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-done
+
+	output.setState(StateDisabled)
+	output.logger.Info("Output disabled")
+}
+
+func (output *Output) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	delay := output.config.ReconnectDelay
+	for {
+		output.setState(StateStarting)
+		started := time.Now()
+
+		err := output.runner.run(ctx, output)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errOutputEnded
+		}
+		if time.Since(started) > stableDuration {
+			delay = output.config.ReconnectDelay
+		}
+
+		output.logger.Warn("Output disconnected, reconnecting", "error", err, "delay", delay)
+		output.update(func(status *Status) {
+			status.LastError = err.Error()
+			status.Reconnects++
+			status.Clients = 0
+			status.FPS = 0
+			status.BitrateKbps = 0
+		})
+		output.setState(StateReconnecting)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, output.config.MaxReconnectDelay)
+	}
+}
+
+func (output *Output) setState(state State) {
+	output.update(func(status *Status) {
+		if status.State != state {
+			status.State = state
+			status.Since = time.Now()
+		}
+	})
+}
+
+func (output *Output) update(fn func(status *Status)) {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+
+	fn(&output.status)
+}
+
+// redactURL 去掉地址中的用户信息、查询参数和 RTMP 推流码，避免在状态接口中泄露凭据
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = ""
+	redacted.Fragment = ""
+
+	if (u.Scheme == "rtmp" || u.Scheme == "rtmps") && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+		redacted.Path = path.Join(path.Dir(u.Path), "redacted")
+		redacted.RawPath = ""
+	}
+
+	return redacted.String()
+}
@@ -0,0 +1,58 @@
+package egress
+
+import (
+	"context"
+
+	"github.com/m4n5ter/lindows/pkg/ffmpeg"
+)
+
+// pushRunner 用 ffmpeg 把视频流推送到 RTMP 或 SRT 服务器
+type pushRunner struct {
+	stream Stream
+	url    string
+	format string
+}
+
+func newPushRunner(stream Stream, url, scheme string) *pushRunner {
+	format := "flv"
+	if scheme == "srt" {
+		format = "mpegts"
+	}
+
+	return &pushRunner{
+		stream: stream,
+		url:    url,
+		format: format,
+	}
+}
+
+func (runner *pushRunner) run(ctx context.Context, output *Output) error {
+	return RunFFmpeg(ctx, runner.stream, ffmpeg.Options{
+		OutputArgs:   runner.codecArgs(),
+		OutputFormat: runner.format,
+		Output:       runner.url,
+		OnProgress: func(progress ffmpeg.Progress) {
+			output.setState(StateRunning)
+			output.update(func(status *Status) {
+				status.FPS = progress.FPS
+				status.BitrateKbps = progress.BitrateKbps
+			})
+		},
+		Logger: output.logger.With("module", "ffmpeg"),
+	})
+}
+
+// codecArgs 在源码流已经是 H.264 时直接复制，否则转码为 H.264，因为 FLV 和 MPEG-TS 都不支持 VP8/VP9
+func (runner *pushRunner) codecArgs() []string {
+	if runner.stream.Codec().Name == "h264" {
+		return []string{"-c:v", "copy"}
+	}
+
+	return []string{
+		"-c:v", "libx264",
+		"-preset", "veryfast",
+		"-tune", "zerolatency",
+		"-pix_fmt", "yuv420p",
+		"-g", "60",
+	}
+}
@@ -0,0 +1,338 @@
+package egress
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/pkg/yalog"
+	"github.com/pion/rtp"
+)
+
+const (
+	rtspTrackControl = "trackID=0"
+	rtspWriteTimeout = 5 * time.Second
+)
+
+// rtspRunner 是一个最小的 RTSP 服务器，只支持 TCP interleaved 传输。
+//
+// 每个客户端独立订阅视频流，RTP 包原样转发，不做转码。地址中带有 user:pass 时，
+// DESCRIBE、SETUP 和 PLAY 必须携带正确的凭据，见 rtspAuth。
+type rtspRunner struct {
+	stream Stream
+	addr   string
+	path   string
+	auth   *rtspAuth
+
+	mu      sync.Mutex
+	clients map[net.Conn]struct{}
+}
+
+func newRTSPRunner(stream Stream, u *url.URL) *rtspRunner {
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "554")
+	}
+
+	return &rtspRunner{
+		stream:  stream,
+		addr:    addr,
+		path:    strings.TrimSuffix(u.Path, "/"),
+		auth:    newRTSPAuth(u),
+		clients: make(map[net.Conn]struct{}),
+	}
+}
+
+func (runner *rtspRunner) run(ctx context.Context, output *Output) error {
+	listener, err := net.Listen("tcp", runner.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen rtsp: %v", err)
+	}
+
+	output.setState(StateRunning)
+	output.logger.Info("RTSP server listening", "addr", listener.Addr().String(), "path", runner.path)
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			runner.closeClients()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept rtsp connection: %v", err)
+		}
+
+		runner.mu.Lock()
+		runner.clients[conn] = struct{}{}
+		runner.mu.Unlock()
+		output.update(func(status *Status) { status.Clients++ })
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer output.update(func(status *Status) { status.Clients-- })
+
+			runner.serveConn(conn, output)
+
+			runner.mu.Lock()
+			delete(runner.clients, conn)
+			runner.mu.Unlock()
+		}()
+	}
+}
+
+func (runner *rtspRunner) closeClients() {
+	runner.mu.Lock()
+	defer runner.mu.Unlock()
+
+	for conn := range runner.clients {
+		conn.Close()
+	}
+}
+
+type rtspRequest struct {
+	method string
+	uri    string
+	header textproto.MIMEHeader
+}
+
+// rtspConn 是一个 RTSP 客户端连接，RTSP 响应和 interleaved RTP 包共用同一个 TCP 连接
+type rtspConn struct {
+	net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+}
+
+func (conn *rtspConn) write(data []byte) error {
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(rtspWriteTimeout))
+	_, err := conn.Write(data)
+	return err
+}
+
+func (conn *rtspConn) respond(req *rtspRequest, code int, reason string, headers map[string]string, body string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "RTSP/1.0 %d %s\r\n", code, reason)
+	fmt.Fprintf(&sb, "CSeq: %s\r\n", req.header.Get("CSeq"))
+	for key, value := range headers {
+		fmt.Fprintf(&sb, "%s: %s\r\n", key, value)
+	}
+	if body != "" {
+		fmt.Fprintf(&sb, "Content-Length: %d\r\n", len(body))
+	}
+	sb.WriteString("\r\n")
+	sb.WriteString(body)
+
+	return conn.write([]byte(sb.String()))
+}
+
+// readRequest 读取下一个 RTSP 请求，跳过客户端发来的 interleaved RTCP 包
+func (conn *rtspConn) readRequest() (*rtspRequest, error) {
+	for {
+		b, err := conn.reader.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] != '$' {
+			break
+		}
+
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn.reader, header); err != nil {
+			return nil, err
+		}
+		if _, err := conn.reader.Discard(int(binary.BigEndian.Uint16(header[2:]))); err != nil {
+			return nil, err
+		}
+	}
+
+	tp := textproto.NewReader(conn.reader)
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Fields(line)
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "RTSP/") {
+		return nil, fmt.Errorf("malformed rtsp request line %q", line)
+	}
+
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	if length, _ := strconv.Atoi(header.Get("Content-Length")); length > 0 {
+		if _, err := conn.reader.Discard(length); err != nil {
+			return nil, err
+		}
+	}
+
+	return &rtspRequest{method: parts[0], uri: parts[1], header: header}, nil
+}
+
+func (runner *rtspRunner) serveConn(netConn net.Conn, output *Output) {
+	conn := &rtspConn{Conn: netConn, reader: bufio.NewReader(netConn)}
+	logger := output.logger.With("client", netConn.RemoteAddr().String())
+	defer conn.Close()
+
+	var sessionID string
+	var unsubscribe func()
+	nonce := newRTSPNonce()
+	defer func() {
+		if unsubscribe != nil {
+			unsubscribe()
+		}
+	}()
+
+	for {
+		req, err := conn.readRequest()
+		if err != nil {
+			if err != io.EOF {
+				logger.Debug("RTSP connection closed", "error", err)
+			}
+			return
+		}
+
+		switch req.method {
+		case "DESCRIBE", "SETUP", "PLAY":
+			if runner.auth != nil && !runner.auth.verify(req.method, req.header.Get("Authorization"), nonce) {
+				if req.header.Get("Authorization") != "" {
+					logger.Warn("RTSP authentication failed", "method", req.method)
+				}
+				err = conn.respond(req, 401, "Unauthorized", map[string]string{
+					"WWW-Authenticate": runner.auth.challenge(nonce),
+				}, "")
+				if err != nil {
+					logger.Debug("Failed to write RTSP response", "error", err)
+					return
+				}
+				continue
+			}
+		}
+
+		switch req.method {
+		case "OPTIONS", "GET_PARAMETER", "SET_PARAMETER":
+			err = conn.respond(req, 200, "OK", map[string]string{
+				"Public": "OPTIONS, DESCRIBE, SETUP, PLAY, TEARDOWN, GET_PARAMETER",
+			}, "")
+
+		case "DESCRIBE":
+			if !runner.matchPath(req.uri, false) {
+				err = conn.respond(req, 404, "Not Found", nil, "")
+				break
+			}
+
+			err = conn.respond(req, 200, "OK", map[string]string{
+				"Content-Type": "application/sdp",
+				"Content-Base": strings.TrimSuffix(req.uri, "/") + "/",
+			}, sessionDescription(runner.stream.Codec(), "0.0.0.0", 0, rtspTrackControl))
+
+		case "SETUP":
+			if !runner.matchPath(req.uri, true) {
+				err = conn.respond(req, 404, "Not Found", nil, "")
+				break
+			}
+			if !strings.Contains(req.header.Get("Transport"), "TCP") {
+				err = conn.respond(req, 461, "Unsupported Transport", nil, "")
+				break
+			}
+
+			if sessionID == "" {
+				sessionID = newRTSPSessionID()
+			}
+			err = conn.respond(req, 200, "OK", map[string]string{
+				"Transport": "RTP/AVP/TCP;unicast;interleaved=0-1",
+				"Session":   sessionID + ";timeout=60",
+			}, "")
+
+		case "PLAY":
+			if sessionID == "" {
+				err = conn.respond(req, 454, "Session Not Found", nil, "")
+				break
+			}
+
+			err = conn.respond(req, 200, "OK", map[string]string{"Session": sessionID}, "")
+			if err == nil && unsubscribe == nil {
+				var packets <-chan rtp.Packet
+				packets, unsubscribe = runner.stream.Subscribe()
+				go runner.forward(conn, packets, logger)
+			}
+
+		case "TEARDOWN":
+			_ = conn.respond(req, 200, "OK", map[string]string{"Session": sessionID}, "")
+			return
+
+		default:
+			err = conn.respond(req, 501, "Not Implemented", nil, "")
+		}
+
+		if err != nil {
+			logger.Debug("Failed to write RTSP response", "error", err)
+			return
+		}
+	}
+}
+
+// forward 以 interleaved 帧的形式把 RTP 包写入连接
+func (runner *rtspRunner) forward(conn *rtspConn, packets <-chan rtp.Packet, logger *yalog.Logger) {
+	for packet := range packets {
+		payload, err := packet.Marshal()
+		if err != nil {
+			continue
+		}
+
+		frame := make([]byte, 4+len(payload))
+		frame[0] = '$'
+		frame[1] = 0 // channel 0: RTP
+		binary.BigEndian.PutUint16(frame[2:], uint16(len(payload)))
+		copy(frame[4:], payload)
+
+		if err := conn.write(frame); err != nil {
+			logger.Debug("Failed to write RTP packet", "error", err)
+			conn.Close()
+			return
+		}
+	}
+}
+
+// matchPath 判断请求地址是否指向本服务器的路径，SETUP 请求可以带上轨道的 control 后缀
+func (runner *rtspRunner) matchPath(uri string, allowTrack bool) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+
+	p := strings.TrimSuffix(u.Path, "/")
+	if allowTrack {
+		p = strings.TrimSuffix(strings.TrimSuffix(p, rtspTrackControl), "/")
+	}
+	return p == runner.path
+}
+
+func newRTSPSessionID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
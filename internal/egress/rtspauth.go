@@ -0,0 +1,108 @@
+package egress
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const rtspRealm = "lindows"
+
+// rtspAuth 校验 RTSP 请求的凭据，凭据来自输出地址中的 user:pass。
+//
+// 服务器用 Digest（RFC 2617，不带 qop）质询，同时接受客户端主动发送的 Basic，
+// ffmpeg、VLC 和大多数 NVR 都支持这两种方式。
+type rtspAuth struct {
+	username string
+	password string
+}
+
+// newRTSPAuth 返回地址中的凭据，地址中没有用户时返回 nil，表示不需要认证
+func newRTSPAuth(u *url.URL) *rtspAuth {
+	if u.User == nil {
+		return nil
+	}
+
+	password, _ := u.User.Password()
+	return &rtspAuth{username: u.User.Username(), password: password}
+}
+
+// challenge 返回 401 响应的 WWW-Authenticate，nonce 在一个连接内保持不变
+func (auth *rtspAuth) challenge(nonce string) string {
+	return fmt.Sprintf(`Digest realm="%s", nonce="%s"`, rtspRealm, nonce)
+}
+
+// verify 判断 Authorization 头是否包含正确的凭据，nonce 必须是发给这个连接的
+func (auth *rtspAuth) verify(method, authorization, nonce string) bool {
+	scheme, credentials, _ := strings.Cut(authorization, " ")
+
+	switch {
+	case strings.EqualFold(scheme, "Basic"):
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+		if err != nil {
+			return false
+		}
+		username, password, _ := strings.Cut(string(decoded), ":")
+		return equal(username, auth.username) && equal(password, auth.password)
+
+	case strings.EqualFold(scheme, "Digest"):
+		params := parseAuthParams(credentials)
+		if !equal(params["username"], auth.username) || params["realm"] != rtspRealm || !equal(params["nonce"], nonce) {
+			return false
+		}
+
+		ha1 := md5Hex(auth.username + ":" + rtspRealm + ":" + auth.password)
+		ha2 := md5Hex(method + ":" + params["uri"])
+		return equal(params["response"], md5Hex(ha1+":"+nonce+":"+ha2))
+	}
+
+	return false
+}
+
+// parseAuthParams 解析 `key="value", key=value` 形式的参数，引号中的逗号不分隔参数
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimLeft(s, ", ") {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				break
+			}
+			value, s = rest[1:end+1], rest[end+2:]
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+		params[key] = value
+	}
+
+	return params
+}
+
+func newRTSPNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
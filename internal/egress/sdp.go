@@ -0,0 +1,42 @@
+package egress
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m4n5ter/lindows/internal/types/codec"
+)
+
+// sessionDescription 生成只包含一个媒体流的 SDP。
+//
+// ffmpeg 读取本地 RTP 时 port 为实际端口；RTSP DESCRIBE 时 port 为 0，并带上 control 属性。
+func sessionDescription(c codec.RTPCodec, address string, port int, control string) string {
+	_, encoding, _ := strings.Cut(c.Capability.MimeType, "/")
+	media := "video"
+	if c.IsAudio() {
+		media = "audio"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("v=0\r\n")
+	fmt.Fprintf(&sb, "o=- 0 0 IN IP4 %s\r\n", address)
+	sb.WriteString("s=lindows\r\n")
+	fmt.Fprintf(&sb, "c=IN IP4 %s\r\n", address)
+	sb.WriteString("t=0 0\r\n")
+	fmt.Fprintf(&sb, "m=%s %d RTP/AVP %d\r\n", media, port, c.PayloadType)
+
+	rtpmap := fmt.Sprintf("%s/%d", encoding, c.Capability.ClockRate)
+	if c.Capability.Channels > 0 {
+		rtpmap += fmt.Sprintf("/%d", c.Capability.Channels)
+	}
+	fmt.Fprintf(&sb, "a=rtpmap:%d %s\r\n", c.PayloadType, rtpmap)
+
+	if c.Capability.SDPFmtpLine != "" {
+		fmt.Fprintf(&sb, "a=fmtp:%d %s\r\n", c.PayloadType, c.Capability.SDPFmtpLine)
+	}
+	if control != "" {
+		fmt.Fprintf(&sb, "a=control:%s\r\n", control)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,106 @@
+package hls
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+const (
+	// 编码器启动后等待第一个播放列表的最长时间
+	startTimeout = 15 * time.Second
+	pollInterval = 50 * time.Millisecond
+)
+
+var fileName = regexp.MustCompile(`^(index\.m3u8|init\.mp4|seg[0-9]+\.m4s)$`)
+
+// Handler 返回 `GET /hls/{file}` 的处理器。
+//
+// 这是普通的 HLS，不是 LL-HLS：ffmpeg 不生成部分分片，所以不声明阻塞式刷新，
+// 延迟取决于分片时长 hls_segment_duration。
+func (manager *Manager) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /hls/{file}", manager.serveFile)
+	return mux
+}
+
+func (manager *Manager) serveFile(w http.ResponseWriter, r *http.Request) {
+	if !manager.config.Enabled {
+		http.Error(w, "hls is disabled", http.StatusNotFound)
+		return
+	}
+
+	name := r.PathValue("file")
+	if !fileName.MatchString(name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	manager.touch()
+
+	if name == playlistName {
+		manager.servePlaylist(w, r)
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(manager.dir, name))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if name == initName {
+		w.Header().Set("Content-Type", "video/mp4")
+	} else {
+		w.Header().Set("Content-Type", "video/iso.segment")
+	}
+	w.Header().Set("Cache-Control", "max-age=60")
+	_, _ = w.Write(data)
+}
+
+func (manager *Manager) servePlaylist(w http.ResponseWriter, r *http.Request) {
+	playlist, err := manager.waitPlaylist(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	var query string
+	if token := r.URL.Query().Get("token"); token != "" {
+		query = url.Values{"token": {token}}.Encode()
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(rewritePlaylist(playlist, query))
+}
+
+var errPlaylistNotReady = errors.New("playlist is not ready")
+
+// waitPlaylist 等待编码器生成第一个播放列表
+func (manager *Manager) waitPlaylist(r *http.Request) ([]byte, error) {
+	deadline := time.Now().Add(startTimeout)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if data, err := os.ReadFile(filepath.Join(manager.dir, playlistName)); err == nil {
+			return data, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errPlaylistNotReady
+		}
+
+		select {
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package hls
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+)
+
+const testPlaylist = `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-TARGETDURATION:1
+#EXT-X-MEDIA-SEQUENCE:4
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXT-X-MAP:URI="init.mp4"
+#EXTINF:1.000000,
+seg4.m4s
+#EXTINF:1.000000,
+seg5.m4s
+`
+
+func TestRewritePlaylist(t *testing.T) {
+	got := string(rewritePlaylist([]byte(testPlaylist), "token=a%2Bb"))
+
+	for _, line := range []string{
+		`#EXT-X-MAP:URI="init.mp4?token=a%2Bb"`,
+		"seg4.m4s?token=a%2Bb\n",
+		"seg5.m4s?token=a%2Bb\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("playlist does not contain %q:\n%s", line, got)
+		}
+	}
+
+	got = string(rewritePlaylist([]byte(testPlaylist), ""))
+	if strings.Contains(got, "?") {
+		t.Errorf("unexpected query in playlist:\n%s", got)
+	}
+}
+
+func newTestManager(t *testing.T) (*Manager, *atomic.Int32) {
+	t.Helper()
+
+	manager := New(nil, &config.HLS{
+		Enabled:         true,
+		SegmentDuration: 200 * time.Millisecond,
+		ListSize:        3,
+		IdleTimeout:     300 * time.Millisecond,
+	})
+
+	var running atomic.Int32
+	manager.run = func(ctx context.Context, dir string) error {
+		running.Add(1)
+		defer running.Add(-1)
+
+		files := map[string]string{
+			playlistName: testPlaylist,
+			initName:     "init",
+			"seg5.m4s":   "segment",
+		}
+		for name, data := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+				return err
+			}
+		}
+
+		<-ctx.Done()
+		return nil
+	}
+
+	manager.Start()
+	t.Cleanup(manager.Shutdown)
+
+	return manager, &running
+}
+
+func TestHandler(t *testing.T) {
+	manager, running := newTestManager(t)
+	handler := manager.Handler()
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	if w := get("/hls/secret.txt"); w.Code != http.StatusNotFound {
+		t.Errorf("invalid file: got %d, want 404", w.Code)
+	}
+	if running.Load() != 0 {
+		t.Fatal("encoder started by an invalid request")
+	}
+
+	w := get(PlaylistPath + "?token=secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("playlist: got %d %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/vnd.apple.mpegurl" {
+		t.Errorf("playlist content type = %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "seg5.m4s?token=secret") {
+		t.Errorf("token was not propagated:\n%s", w.Body)
+	}
+	// 没有部分分片，不能声明 LL-HLS 的阻塞式刷新
+	if strings.Contains(w.Body.String(), "#EXT-X-SERVER-CONTROL") {
+		t.Errorf("playlist advertises blocking reload:\n%s", w.Body)
+	}
+
+	if w := get("/hls/seg5.m4s"); w.Code != http.StatusOK || w.Body.String() != "segment" {
+		t.Errorf("segment: got %d %q", w.Code, w.Body)
+	}
+	if w := get("/hls/seg9.m4s"); w.Code != http.StatusNotFound {
+		t.Errorf("missing segment: got %d, want 404", w.Code)
+	}
+
+	// 编码器退出之后才会删除旧的分片
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := os.Stat(filepath.Join(manager.dir, playlistName))
+		if running.Load() == 0 && os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("encoder was not stopped after the idle timeout, running %d, playlist %v", running.Load(), err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestHandlerDisabled(t *testing.T) {
+	manager := New(nil, &config.HLS{})
+
+	w := httptest.NewRecorder()
+	manager.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, PlaylistPath, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("got %d, want 404", w.Code)
+	}
+}
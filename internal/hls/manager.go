@@ -0,0 +1,201 @@
+package hls
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/egress"
+	"github.com/m4n5ter/lindows/pkg/ffmpeg"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+// PlaylistPath 是 HLS 播放列表的 HTTP 路径
+const PlaylistPath = "/hls/" + playlistName
+
+const (
+	playlistName = "index.m3u8"
+	initName     = "init.mp4"
+)
+
+// Manager 按需把视频流编码为 fMP4 分片的 HLS。
+//
+// 第一个请求到达时启动 ffmpeg，超过 IdleTimeout 没有请求后停止，避免没有观众时浪费 CPU。
+type Manager struct {
+	logger *yalog.Logger
+	config *config.HLS
+	stream egress.Stream
+	dir    string
+
+	// run 把视频流编码到 dir，直到 ctx 被取消，测试中会被替换
+	run func(ctx context.Context, dir string) error
+
+	mu         sync.Mutex
+	cancel     context.CancelFunc
+	done       chan struct{}
+	lastAccess time.Time
+
+	shutdown chan struct{}
+	watcher  chan struct{}
+}
+
+func New(stream egress.Stream, cfg *config.HLS) *Manager {
+	manager := &Manager{
+		logger: yalog.Default().With("module", "hls"),
+		config: cfg,
+		stream: stream,
+	}
+	manager.run = manager.ffmpeg
+
+	return manager
+}
+
+func (manager *Manager) Enabled() bool {
+	return manager.config.Enabled
+}
+
+func (manager *Manager) Start() {
+	if !manager.config.Enabled {
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "lindows-hls")
+	if err != nil {
+		manager.logger.Fatal("Failed to create HLS directory", "error", err)
+	}
+	manager.dir = dir
+
+	manager.shutdown = make(chan struct{})
+	manager.watcher = make(chan struct{})
+	go manager.watchIdle()
+
+	manager.logger.Info("HLS manager started", "playlist", PlaylistPath, "segment_duration", manager.config.SegmentDuration)
+}
+
+func (manager *Manager) Shutdown() {
+	if manager.shutdown == nil {
+		return
+	}
+
+	close(manager.shutdown)
+	<-manager.watcher
+
+	manager.stopEncoder()
+	if err := os.RemoveAll(manager.dir); err != nil {
+		manager.logger.Warn("Failed to remove HLS directory", "error", err)
+	}
+}
+
+// touch 记录一次观众请求，必要时启动编码
+func (manager *Manager) touch() {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	manager.lastAccess = time.Now()
+	if manager.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	manager.cancel = cancel
+	manager.done = make(chan struct{})
+
+	go manager.loop(ctx, manager.done)
+	manager.logger.Info("HLS encoder started")
+}
+
+func (manager *Manager) stopEncoder() {
+	manager.mu.Lock()
+	cancel, done := manager.cancel, manager.done
+	manager.cancel, manager.done = nil, nil
+	manager.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-done
+
+	// 删除旧的分片，下次启动时不会把过期的播放列表发给观众
+	entries, _ := os.ReadDir(manager.dir)
+	for _, entry := range entries {
+		_ = os.Remove(filepath.Join(manager.dir, entry.Name()))
+	}
+
+	manager.logger.Info("HLS encoder stopped")
+}
+
+func (manager *Manager) watchIdle() {
+	defer close(manager.watcher)
+
+	interval := max(manager.config.IdleTimeout/3, 100*time.Millisecond)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-manager.shutdown:
+			return
+		case <-ticker.C:
+		}
+
+		manager.mu.Lock()
+		idle := manager.cancel != nil && time.Since(manager.lastAccess) > manager.config.IdleTimeout
+		manager.mu.Unlock()
+
+		if idle {
+			manager.logger.Debug("No HLS viewers, stopping encoder")
+			manager.stopEncoder()
+		}
+	}
+}
+
+func (manager *Manager) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for {
+		err := manager.run(ctx, manager.dir)
+		if ctx.Err() != nil {
+			return
+		}
+
+		manager.logger.Warn("HLS encoder exited, restarting", "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// ffmpeg 把视频流转码为 H.264，关键帧间隔与分片时长一致，这样每个分片都可以独立解码
+func (manager *Manager) ffmpeg(ctx context.Context, dir string) error {
+	segment := strconv.FormatFloat(manager.config.SegmentDuration.Seconds(), 'f', -1, 64)
+
+	return egress.RunFFmpeg(ctx, manager.stream, ffmpeg.Options{
+		OutputArgs: []string{
+			"-an",
+			"-c:v", "libx264",
+			"-preset", "veryfast",
+			"-tune", "zerolatency",
+			"-pix_fmt", "yuv420p",
+			"-sc_threshold", "0",
+			"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%s)", segment),
+			"-hls_time", segment,
+			"-hls_list_size", strconv.Itoa(manager.config.ListSize),
+			"-hls_segment_type", "fmp4",
+			"-hls_fmp4_init_filename", initName,
+			"-hls_segment_filename", filepath.Join(dir, "seg%d.m4s"),
+			"-hls_flags", "delete_segments+independent_segments+omit_endlist+temp_file+program_date_time",
+		},
+		OutputFormat: "hls",
+		Output:       filepath.Join(dir, playlistName),
+		Logger:       manager.logger.With("module", "ffmpeg"),
+	})
+}
@@ -0,0 +1,43 @@
+package hls
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// rewritePlaylist 把 query 追加到播放列表中的所有 URI 上，
+// 这样只能通过 `?token=` 认证的播放器在请求分片时也能带上令牌
+func rewritePlaylist(playlist []byte, query string) []byte {
+	var buf bytes.Buffer
+
+	scanner := bufio.NewScanner(bytes.NewReader(playlist))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			continue
+
+		case strings.HasPrefix(line, "#EXT-X-MAP:"):
+			if query != "" {
+				if start := strings.Index(line, `URI="`); start >= 0 {
+					start += len(`URI="`)
+					if end := strings.IndexByte(line[start:], '"'); end >= 0 {
+						line = line[:start+end] + "?" + query + line[start+end:]
+					}
+				}
+			}
+
+		case !strings.HasPrefix(line, "#"):
+			if query != "" {
+				line += "?" + query
+			}
+		}
+
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,224 @@
+package playback
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/egress"
+	"github.com/m4n5ter/lindows/internal/types/codec"
+	"github.com/m4n5ter/lindows/pkg/ffmpeg"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+	"github.com/pion/rtp"
+)
+
+// MuteState 是某个会话看到的静音状态，任意一端静音都不会播放
+type MuteState struct {
+	Host   bool `json:"host"`
+	Client bool `json:"client"`
+}
+
+func (state MuteState) Muted() bool {
+	return state.Host || state.Client
+}
+
+// Manager 把会话的 Opus 音频解码后写入输出设备。
+//
+// 多个声音混在一起没有意义，所以只播放当前控制者的音频。
+type Manager struct {
+	logger *yalog.Logger
+	config *config.Playback
+	sink   Sink
+
+	// decode 把 Opus RTP 包解码为 PCM 写入 w，直到 packets 关闭或 ctx 被取消，测试中会被替换
+	decode func(ctx context.Context, packets <-chan rtp.Packet, w io.Writer) error
+
+	mu          sync.Mutex
+	hostMuted   bool
+	clientMuted map[string]bool
+
+	listenersMu sync.RWMutex
+	onChanged   []func(sessionID string)
+}
+
+func New(cfg *config.Playback) *Manager {
+	manager := &Manager{
+		logger:      yalog.Default().With("module", "playback"),
+		config:      cfg,
+		clientMuted: make(map[string]bool),
+	}
+	manager.decode = manager.ffmpeg
+
+	return manager
+}
+
+func (manager *Manager) Start() {
+	if !manager.config.Enabled {
+		return
+	}
+
+	sink, err := OpenSink(manager.config.Device)
+	if err != nil {
+		manager.logger.Error("Failed to open playback device, playback disabled", "device", manager.config.Device, "error", err)
+		return
+	}
+
+	manager.sink = sink
+	manager.hostMuted = manager.config.Muted
+
+	manager.logger.Info("Playback manager started", "device", manager.config.Device, "muted", manager.hostMuted)
+}
+
+func (manager *Manager) Shutdown() {
+	if manager.sink == nil {
+		return
+	}
+
+	if err := manager.sink.Close(); err != nil {
+		manager.logger.Error("Failed to close playback device", "error", err)
+	}
+}
+
+// Enabled 判断是否可以接收观众的音频
+func (manager *Manager) Enabled() bool {
+	return manager.sink != nil
+}
+
+// Play 播放一个会话的音频，直到 packets 关闭或 ctx 被取消。
+//
+// active 返回 false 时音频会被丢弃，用于只播放控制者的声音。
+func (manager *Manager) Play(ctx context.Context, sessionID string, packets <-chan rtp.Packet, active func() bool) error {
+	defer func() {
+		manager.mu.Lock()
+		delete(manager.clientMuted, sessionID)
+		manager.mu.Unlock()
+	}()
+
+	return manager.decode(ctx, packets, &sessionWriter{
+		manager:   manager,
+		sessionID: sessionID,
+		active:    active,
+	})
+}
+
+func (manager *Manager) State(sessionID string) MuteState {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	return MuteState{Host: manager.hostMuted, Client: manager.clientMuted[sessionID]}
+}
+
+// SetHostMuted 在主机端静音或取消静音所有会话
+func (manager *Manager) SetHostMuted(muted bool) {
+	manager.mu.Lock()
+	manager.hostMuted = muted
+	manager.mu.Unlock()
+
+	manager.logger.Info("Host mute changed", "muted", muted)
+	manager.emit("")
+}
+
+// SetClientMuted 由观众静音或取消静音自己的麦克风
+func (manager *Manager) SetClientMuted(sessionID string, muted bool) {
+	manager.mu.Lock()
+	manager.clientMuted[sessionID] = muted
+	manager.mu.Unlock()
+
+	manager.logger.Debug("Client mute changed", "session_id", sessionID, "muted", muted)
+	manager.emit(sessionID)
+}
+
+// OnMuteChanged 注册静音状态变化的回调。sessionID 是状态变化的会话，
+// 主机端静音影响所有会话，此时 sessionID 为空。设置为相同的值也会回调，这样请求方总能收到当前状态
+func (manager *Manager) OnMuteChanged(listener func(sessionID string)) {
+	manager.listenersMu.Lock()
+	defer manager.listenersMu.Unlock()
+
+	manager.onChanged = append(manager.onChanged, listener)
+}
+
+func (manager *Manager) emit(sessionID string) {
+	manager.listenersMu.RLock()
+	listeners := manager.onChanged
+	manager.listenersMu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(sessionID)
+	}
+}
+
+// Handler 返回主机端的静音接口:
+//
+//	GET  /playback         查询状态
+//	POST /playback/mute    静音
+//	POST /playback/unmute  取消静音
+func (manager *Manager) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	status := func(w http.ResponseWriter) {
+		manager.mu.Lock()
+		body := map[string]bool{"enabled": manager.Enabled(), "muted": manager.hostMuted}
+		manager.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	}
+
+	mux.HandleFunc("GET /playback", func(w http.ResponseWriter, r *http.Request) {
+		status(w)
+	})
+
+	for action, muted := range map[string]bool{"mute": true, "unmute": false} {
+		mux.HandleFunc("POST /playback/"+action, func(w http.ResponseWriter, r *http.Request) {
+			manager.SetHostMuted(muted)
+			status(w)
+		})
+	}
+
+	return mux
+}
+
+// sessionWriter 在静音或会话不是控制者时丢弃数据
+type sessionWriter struct {
+	manager   *Manager
+	sessionID string
+	active    func() bool
+}
+
+func (writer *sessionWriter) Write(pcm []byte) (int, error) {
+	if writer.manager.State(writer.sessionID).Muted() || !writer.active() {
+		return len(pcm), nil
+	}
+
+	return writer.manager.sink.Write(pcm)
+}
+
+// packetStream 把一个会话的音频包包装成 egress.Stream，以便复用 RTP 到 ffmpeg 的转发
+type packetStream struct {
+	packets <-chan rtp.Packet
+}
+
+func (stream packetStream) Codec() codec.RTPCodec {
+	return codec.Opus()
+}
+
+func (stream packetStream) Subscribe() (<-chan rtp.Packet, func()) {
+	return stream.packets, func() {}
+}
+
+func (manager *Manager) ffmpeg(ctx context.Context, packets <-chan rtp.Packet, w io.Writer) error {
+	return egress.RunFFmpeg(ctx, packetStream{packets: packets}, ffmpeg.Options{
+		OutputArgs: []string{
+			"-ar", strconv.Itoa(SampleRate),
+			"-ac", strconv.Itoa(Channels),
+			"-flush_packets", "1",
+		},
+		OutputFormat: "s16le",
+		Stdout:       w,
+		Logger:       manager.logger.With("module", "ffmpeg"),
+	})
+}
@@ -0,0 +1,136 @@
+package playback
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/pion/rtp"
+)
+
+// newTestManager 返回一个使用 Fake 输出、把 RTP 负载原样当作 PCM 的 Manager，
+// 每个包处理完后会向 written 发送一个信号
+func newTestManager() (*Manager, *Fake, chan struct{}) {
+	sink := &Fake{}
+	written := make(chan struct{}, 1)
+
+	manager := New(&config.Playback{Enabled: true})
+	manager.sink = sink
+	manager.decode = func(ctx context.Context, packets <-chan rtp.Packet, w io.Writer) error {
+		for packet := range packets {
+			if _, err := w.Write(packet.Payload); err != nil {
+				return err
+			}
+			written <- struct{}{}
+		}
+		return nil
+	}
+
+	return manager, sink, written
+}
+
+func TestPlay(t *testing.T) {
+	manager, sink, written := newTestManager()
+
+	var controller atomic.Bool
+	controller.Store(true)
+
+	packets := make(chan rtp.Packet)
+	done := make(chan error)
+	go func() {
+		done <- manager.Play(context.Background(), "s1", packets, controller.Load)
+	}()
+
+	send := func(payload string) {
+		packets <- rtp.Packet{Payload: []byte(payload)}
+		<-written
+	}
+
+	send("a")
+
+	manager.SetClientMuted("s1", true)
+	send("b")
+	if state := manager.State("s1"); !state.Client || state.Host || !state.Muted() {
+		t.Errorf("state = %+v, want client muted", state)
+	}
+
+	manager.SetClientMuted("s1", false)
+	manager.SetHostMuted(true)
+	send("c")
+
+	manager.SetHostMuted(false)
+	controller.Store(false)
+	send("d")
+
+	controller.Store(true)
+	send("e")
+
+	close(packets)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(sink.Bytes()); got != "ae" {
+		t.Errorf("played %q, want %q", got, "ae")
+	}
+
+	// 会话结束后不再保留它的静音状态
+	manager.SetClientMuted("s1", true)
+	go func() { done <- manager.Play(context.Background(), "s1", closedPackets(), controller.Load) }()
+	<-done
+	if manager.State("s1").Client {
+		t.Error("client mute was not cleared after playback ended")
+	}
+}
+
+func closedPackets() <-chan rtp.Packet {
+	packets := make(chan rtp.Packet)
+	close(packets)
+	return packets
+}
+
+func TestHandler(t *testing.T) {
+	manager, _, _ := newTestManager()
+	handler := manager.Handler()
+
+	var changed []string
+	manager.OnMuteChanged(func(sessionID string) { changed = append(changed, sessionID) })
+
+	do := func(method, target string) map[string]bool {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s %s: got %d", method, target, w.Code)
+		}
+
+		var body map[string]bool
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	if body := do(http.MethodGet, "/playback"); !body["enabled"] || body["muted"] {
+		t.Errorf("GET /playback = %v", body)
+	}
+	if body := do(http.MethodPost, "/playback/mute"); !body["muted"] || !manager.State("any").Host {
+		t.Errorf("POST /playback/mute = %v", body)
+	}
+	if body := do(http.MethodPost, "/playback/unmute"); body["muted"] || manager.State("any").Host {
+		t.Errorf("POST /playback/unmute = %v", body)
+	}
+
+	// 主机端的变化通知所有会话，观众的变化只通知它自己
+	manager.SetClientMuted("a", true)
+	if want := []string{"", "", "a"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("mute changes = %q, want %q", changed, want)
+	}
+}
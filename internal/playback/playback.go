@@ -0,0 +1,56 @@
+// Package playback 在主机上播放观众的麦克风音频
+package playback
+
+import (
+	"errors"
+	"sync"
+)
+
+// 解码后的 PCM 格式：48kHz、双声道、16 位小端
+const (
+	SampleRate     = 48000
+	Channels       = 2
+	BytesPerSample = 2
+)
+
+var ErrUnsupported = errors.New("audio playback is not supported on this platform")
+
+// Sink 播放 PCM 数据
+type Sink interface {
+	Write(pcm []byte) (int, error)
+	Close() error
+}
+
+// Fake 记录写入的 PCM 数据，用于测试
+type Fake struct {
+	mu     sync.Mutex
+	data   []byte
+	closed bool
+}
+
+func (fake *Fake) Write(pcm []byte) (int, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if fake.closed {
+		return 0, errors.New("sink closed")
+	}
+	fake.data = append(fake.data, pcm...)
+	return len(pcm), nil
+}
+
+func (fake *Fake) Close() error {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.closed = true
+	return nil
+}
+
+// Bytes 返回目前写入的所有数据
+func (fake *Fake) Bytes() []byte {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return append([]byte(nil), fake.data...)
+}
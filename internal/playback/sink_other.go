@@ -0,0 +1,8 @@
+//go:build !windows
+
+package playback
+
+// OpenSink 打开名称包含 device 的输出设备，device 为空时使用默认设备
+func OpenSink(device string) (Sink, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,151 @@
+package playback
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/m4n5ter/lindows/winapi"
+)
+
+const (
+	// 每个缓冲区 20ms，最多排队 8 个，即 160ms 的延迟
+	blockSize   = SampleRate * Channels * BytesPerSample / 50
+	blockCount  = 8
+	pollTimeout = 2 * time.Millisecond
+)
+
+// waveSink 通过 waveOut 播放 PCM，缓冲区在设备播放完之前不会被复用
+type waveSink struct {
+	mu      sync.Mutex
+	hwo     winapi.HWAVEOUT
+	headers [blockCount]winapi.WAVEHDR
+	buffers [blockCount][]byte
+	next    int
+	pending []byte
+	closed  bool
+}
+
+// OpenSink 打开名称包含 device 的输出设备，device 为空时使用默认设备
+func OpenSink(device string) (Sink, error) {
+	deviceID, err := findDevice(device)
+	if err != nil {
+		return nil, err
+	}
+
+	format := winapi.WAVEFORMATEX{
+		WFormatTag:      winapi.WaveFormatPCM,
+		NChannels:       Channels,
+		NSamplesPerSec:  SampleRate,
+		NAvgBytesPerSec: SampleRate * Channels * BytesPerSample,
+		NBlockAlign:     Channels * BytesPerSample,
+		WBitsPerSample:  BytesPerSample * 8,
+	}
+
+	hwo, err := winapi.WaveOutOpen(deviceID, &format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wave out device: %v", err)
+	}
+
+	sink := &waveSink{hwo: hwo}
+	for i := range sink.buffers {
+		sink.buffers[i] = make([]byte, blockSize)
+		// 新的缓冲区视为已经播放完毕，可以直接使用
+		sink.headers[i].DwFlags = winapi.WHDRDone
+	}
+
+	return sink, nil
+}
+
+func findDevice(name string) (uint32, error) {
+	if name == "" {
+		return winapi.WaveMapper, nil
+	}
+
+	var names []string
+	for id := uint32(0); id < winapi.WaveOutGetNumDevs(); id++ {
+		caps, err := winapi.WaveOutGetDevCaps(id)
+		if err != nil {
+			continue
+		}
+
+		deviceName := syscall.UTF16ToString(caps.SzPname[:])
+		if strings.Contains(strings.ToLower(deviceName), strings.ToLower(name)) {
+			return id, nil
+		}
+		names = append(names, deviceName)
+	}
+
+	return 0, fmt.Errorf("output device %q not found, available devices: %q", name, names)
+}
+
+func (sink *waveSink) Write(pcm []byte) (int, error) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	if sink.closed {
+		return 0, errors.New("sink closed")
+	}
+
+	sink.pending = append(sink.pending, pcm...)
+	for len(sink.pending) >= blockSize {
+		if err := sink.submit(sink.pending[:blockSize]); err != nil {
+			return 0, err
+		}
+		sink.pending = sink.pending[blockSize:]
+	}
+
+	// 避免 pending 的底层数组无限增长
+	sink.pending = append(sink.pending[:0:0], sink.pending...)
+	return len(pcm), nil
+}
+
+// submit 等待下一个缓冲区播放完毕，然后把 block 写入设备
+func (sink *waveSink) submit(block []byte) error {
+	hdr := &sink.headers[sink.next]
+	for hdr.DwFlags&winapi.WHDRDone == 0 {
+		time.Sleep(pollTimeout)
+	}
+
+	if hdr.DwFlags&winapi.WHDRPrepared != 0 {
+		if err := winapi.WaveOutUnprepareHeader(sink.hwo, hdr); err != nil {
+			return fmt.Errorf("failed to unprepare wave header: %v", err)
+		}
+	}
+
+	buf := sink.buffers[sink.next]
+	copy(buf, block)
+	*hdr = winapi.WAVEHDR{LpData: &buf[0], DwBufferLength: uint32(len(block))}
+
+	if err := winapi.WaveOutPrepareHeader(sink.hwo, hdr); err != nil {
+		return fmt.Errorf("failed to prepare wave header: %v", err)
+	}
+	if err := winapi.WaveOutWrite(sink.hwo, hdr); err != nil {
+		return fmt.Errorf("failed to write wave data: %v", err)
+	}
+
+	sink.next = (sink.next + 1) % blockCount
+	return nil
+}
+
+func (sink *waveSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	if sink.closed {
+		return nil
+	}
+	sink.closed = true
+
+	_ = winapi.WaveOutReset(sink.hwo)
+	for i := range sink.headers {
+		if sink.headers[i].DwFlags&winapi.WHDRPrepared != 0 {
+			_ = winapi.WaveOutUnprepareHeader(sink.hwo, &sink.headers[i])
+		}
+	}
+
+	return winapi.WaveOutClose(sink.hwo)
+}
@@ -0,0 +1,176 @@
+package recorder
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/egress"
+	"github.com/m4n5ter/lindows/internal/session"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+const retentionInterval = time.Hour
+
+// Manager 在会话开始时自动录制采集管道的视频流，并执行录像的保留策略
+type Manager struct {
+	logger   *yalog.Logger
+	config   *config.Recorder
+	sessions *session.Manager
+	stream   egress.Stream
+
+	mu         sync.Mutex
+	recordings map[string]*recording
+	retention  sync.Mutex
+
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+}
+
+func New(sessions *session.Manager, stream egress.Stream, cfg *config.Recorder) *Manager {
+	return &Manager{
+		logger:     yalog.Default().With("module", "recorder"),
+		config:     cfg,
+		sessions:   sessions,
+		stream:     stream,
+		recordings: make(map[string]*recording),
+		shutdown:   make(chan struct{}),
+	}
+}
+
+func (manager *Manager) Start() {
+	if !manager.config.Enabled {
+		manager.logger.Info("Session recording disabled")
+		return
+	}
+
+	if err := os.MkdirAll(manager.config.Dir, 0o750); err != nil {
+		manager.logger.Error("Failed to create recording directory, session recording disabled", "dir", manager.config.Dir, "error", err)
+		return
+	}
+
+	manager.sessions.OnCreated(manager.startRecording)
+	manager.sessions.OnDestroyed(manager.stopRecording)
+	manager.sessions.OnControllerChanged(manager.controllerChanged)
+
+	manager.enforceRetention()
+
+	manager.wg.Add(1)
+	go func() {
+		defer manager.wg.Done()
+
+		ticker := time.NewTicker(retentionInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				manager.enforceRetention()
+			case <-manager.shutdown:
+				return
+			}
+		}
+	}()
+
+	manager.logger.Info("Session recording enabled",
+		"dir", manager.config.Dir,
+		"max_file_size", manager.config.MaxFileSize,
+		"max_file_duration", manager.config.MaxFileDuration,
+		"max_age", manager.config.MaxAge,
+		"max_total", manager.config.MaxTotal,
+	)
+}
+
+// Shutdown 结束所有录制并等待文件写入完成
+func (manager *Manager) Shutdown() {
+	if !manager.config.Enabled {
+		return
+	}
+
+	close(manager.shutdown)
+
+	manager.mu.Lock()
+	for _, rec := range manager.recordings {
+		rec.cancel()
+	}
+	manager.mu.Unlock()
+
+	manager.wg.Wait()
+}
+
+func (manager *Manager) startRecording(session *session.Session) {
+	select {
+	case <-manager.shutdown:
+		return
+	default:
+	}
+
+	rec := newRecording(session, manager.stream, manager.config, manager.logger)
+
+	var ctx context.Context
+	ctx, rec.cancel = context.WithCancel(context.Background())
+
+	manager.mu.Lock()
+	manager.recordings[session.ID()] = rec
+	manager.mu.Unlock()
+
+	manager.wg.Add(1)
+	go func() {
+		defer manager.wg.Done()
+		rec.run(ctx, manager.enforceRetention)
+
+		manager.mu.Lock()
+		if manager.recordings[session.ID()] == rec {
+			delete(manager.recordings, session.ID())
+		}
+		manager.mu.Unlock()
+	}()
+}
+
+func (manager *Manager) stopRecording(session *session.Session) {
+	manager.mu.Lock()
+	rec, ok := manager.recordings[session.ID()]
+	manager.mu.Unlock()
+
+	if ok {
+		rec.stop()
+	}
+}
+
+func (manager *Manager) controllerChanged(controller *session.Session) {
+	change := ControllerChange{At: time.Now()}
+	if controller != nil {
+		change.SessionID = controller.ID()
+		change.User = controller.User()
+	}
+
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	for _, rec := range manager.recordings {
+		rec.addControllerChange(change)
+	}
+}
+
+func (manager *Manager) isRecording(id string) bool {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	_, ok := manager.recordings[id]
+	return ok
+}
+
+func (manager *Manager) enforceRetention() {
+	manager.retention.Lock()
+	defer manager.retention.Unlock()
+
+	removed, err := enforceRetention(manager.config.Dir, manager.config.MaxAge, manager.config.MaxTotal, manager.isRecording, time.Now())
+	if err != nil {
+		manager.logger.Error("Failed to enforce retention policy", "error", err)
+	}
+	if len(removed) > 0 {
+		manager.logger.Info("Removed old recordings", "sessions", removed)
+	}
+}
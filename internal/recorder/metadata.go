@@ -0,0 +1,50 @@
+package recorder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Metadata 是与录像一起保存的 JSON 附属文件
+type Metadata struct {
+	SessionID         string             `json:"session_id"`
+	PeerAddress       string             `json:"peer_address"`
+	User              string             `json:"user"`
+	StartedAt         time.Time          `json:"started_at"`
+	EndedAt           *time.Time         `json:"ended_at,omitempty"`
+	Files             []string           `json:"files"`
+	ControllerChanges []ControllerChange `json:"controller_changes"`
+}
+
+// ControllerChange 记录一次控制权变化，控制权被释放时 SessionID 为空
+type ControllerChange struct {
+	At        time.Time `json:"at"`
+	SessionID string    `json:"session_id"`
+	User      string    `json:"user,omitempty"`
+}
+
+// writeMetadata 先写入临时文件再重命名，避免进程退出时留下不完整的 JSON
+func writeMetadata(path string, metadata *Metadata) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,197 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/egress"
+	"github.com/m4n5ter/lindows/internal/session"
+	"github.com/m4n5ter/lindows/internal/types/codec"
+	"github.com/m4n5ter/lindows/pkg/ffmpeg"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+const (
+	// 比这更短的分段说明 ffmpeg 无法录制，无论它是否报错
+	minPartDuration = 5 * time.Second
+	// 连续这么多个分段都很短时不再重试
+	maxShortParts = 5
+)
+
+// recording 是一个会话的录像，按大小和时长切分为多个文件
+type recording struct {
+	logger *yalog.Logger
+	config *config.Recorder
+	format string
+	ext    string
+
+	// record 把视频流录制到 path，直到 ctx 被取消或分段结束，测试中会被替换
+	record func(ctx context.Context, path string) error
+	// backoff 是第一个过短的分段之后的等待时间，之后每次加倍
+	backoff time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu           sync.Mutex
+	metadata     Metadata
+	metadataPath string
+}
+
+func newRecording(session *session.Session, stream egress.Stream, cfg *config.Recorder, logger *yalog.Logger) *recording {
+	format, ext := container(stream.Codec())
+
+	rec := &recording{
+		logger:  logger.With("session_id", session.ID()),
+		config:  cfg,
+		format:  format,
+		ext:     ext,
+		backoff: time.Second,
+		done:    make(chan struct{}),
+		metadata: Metadata{
+			SessionID:         session.ID(),
+			PeerAddress:       session.PeerAddress(),
+			User:              session.User(),
+			StartedAt:         time.Now(),
+			Files:             []string{},
+			ControllerChanges: []ControllerChange{},
+		},
+		metadataPath: filepath.Join(cfg.Dir, session.ID()+metadataExt),
+	}
+	rec.record = func(ctx context.Context, path string) error {
+		return rec.ffmpeg(ctx, stream, path)
+	}
+
+	return rec
+}
+
+// container 返回复制 c 编码的视频时使用的封装格式和扩展名，webm 不能封装 H.264
+func container(c codec.RTPCodec) (format, ext string) {
+	if c.Name == codec.H264().Name {
+		return "matroska", matroskaExt
+	}
+	return "webm", webmExt
+}
+
+// run 循环录制分段，直到 ctx 被取消或连续的分段都过短。onPart 在每个分段结束后调用。
+func (rec *recording) run(ctx context.Context, onPart func()) {
+	defer close(rec.done)
+	defer rec.finish()
+
+	rec.saveMetadata()
+
+	short := 0
+	for part := 0; ctx.Err() == nil; part++ {
+		name := fmt.Sprintf("%s-%03d%s", rec.metadata.SessionID, part, rec.ext)
+		started := time.Now()
+
+		err := rec.recordPart(ctx, filepath.Join(rec.config.Dir, name))
+		onPart()
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			rec.logger.Error("Recording part failed", "file", name, "error", err)
+		}
+
+		// ffmpeg 正常退出时分段也可能是空的，例如视频流被关闭，所以不论是否出错都检查时长，
+		// 并在下一个分段之前等待，避免不停地创建空文件
+		if time.Since(started) >= minPartDuration {
+			short = 0
+			continue
+		}
+		short++
+		if short >= maxShortParts {
+			rec.logger.Error("Recording stopped because ffmpeg keeps exiting", "parts", short)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(rec.backoff << (short - 1)):
+		}
+	}
+}
+
+// recordPart 录制一个分段并把它记录到附属文件中，ffmpeg 没有创建文件时不记录
+func (rec *recording) recordPart(ctx context.Context, path string) error {
+	rec.mu.Lock()
+	rec.metadata.Files = append(rec.metadata.Files, filepath.Base(path))
+	rec.mu.Unlock()
+	rec.saveMetadata()
+
+	rec.logger.Info("Recording to file", "file", path)
+	err := rec.record(ctx, path)
+
+	if _, statErr := os.Stat(path); statErr != nil {
+		rec.mu.Lock()
+		if files := rec.metadata.Files; len(files) > 0 && files[len(files)-1] == filepath.Base(path) {
+			rec.metadata.Files = files[:len(files)-1]
+		}
+		rec.mu.Unlock()
+		rec.saveMetadata()
+	}
+
+	return err
+}
+
+// ffmpeg 把采集管道的视频流直接封装到 path，不重新编码，所有会话共用同一个屏幕采集。
+// 分段从流中间开始，第一个关键帧之前的画面无法解码，采集管道会定期插入关键帧
+func (rec *recording) ffmpeg(ctx context.Context, stream egress.Stream, path string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	return egress.RunFFmpeg(ctx, stream, ffmpeg.Options{
+		Duration:     rec.config.MaxFileDuration,
+		OutputArgs:   []string{"-c", "copy"},
+		OutputFormat: rec.format,
+		Output:       path,
+		OnProgress: func(progress ffmpeg.Progress) {
+			// 超过大小限制时结束当前分段，run 会开始下一个分段
+			if rec.config.MaxFileSize > 0 && progress.TotalSize >= rec.config.MaxFileSize {
+				cancel()
+			}
+		},
+		Logger: rec.logger.With("module", "ffmpeg"),
+	})
+}
+
+func (rec *recording) addControllerChange(change ControllerChange) {
+	rec.mu.Lock()
+	rec.metadata.ControllerChanges = append(rec.metadata.ControllerChanges, change)
+	rec.mu.Unlock()
+
+	rec.saveMetadata()
+}
+
+func (rec *recording) finish() {
+	now := time.Now()
+	rec.mu.Lock()
+	rec.metadata.EndedAt = &now
+	rec.mu.Unlock()
+
+	rec.saveMetadata()
+	rec.logger.Info("Recording finished")
+}
+
+// stop 结束录制并等待最后一个分段写入完成
+func (rec *recording) stop() {
+	rec.cancel()
+	<-rec.done
+}
+
+func (rec *recording) saveMetadata() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if err := writeMetadata(rec.metadataPath, &rec.metadata); err != nil {
+		rec.logger.Error("Failed to write recording metadata", "error", err)
+	}
+}
@@ -0,0 +1,134 @@
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/session"
+	"github.com/m4n5ter/lindows/internal/types/codec"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+	"github.com/pion/rtp"
+)
+
+type fakeStream struct {
+	codec codec.RTPCodec
+}
+
+func (stream fakeStream) Codec() codec.RTPCodec { return stream.codec }
+
+func (fakeStream) Subscribe() (<-chan rtp.Packet, func()) {
+	packets := make(chan rtp.Packet)
+	return packets, func() { close(packets) }
+}
+
+func newTestRecording(t *testing.T, c codec.RTPCodec) (*recording, *config.Recorder) {
+	t.Helper()
+
+	cfg := &config.Recorder{Dir: t.TempDir()}
+	peer := session.New(&config.Session{}).Create("127.0.0.1:1", "alice")
+	rec := newRecording(peer, fakeStream{codec: c}, cfg, yalog.Default())
+	rec.backoff = time.Millisecond
+	return rec, cfg
+}
+
+func TestRecordingShortParts(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		write bool
+		err   error
+	}{
+		// ffmpeg 正常退出但没有录到内容，也不能无限地开始新的分段
+		{"exit without error", true, nil},
+		{"exit with error", false, os.ErrInvalid},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, cfg := newTestRecording(t, codec.VP8())
+
+			var paths []string
+			rec.record = func(_ context.Context, path string) error {
+				paths = append(paths, path)
+				if tt.write {
+					return os.WriteFile(path, nil, 0o600)
+				}
+				return tt.err
+			}
+
+			parts := 0
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				rec.run(context.Background(), func() { parts++ })
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("run did not stop after repeated short parts")
+			}
+			if parts != maxShortParts || len(paths) != maxShortParts {
+				t.Errorf("parts = %d, paths = %v, want %d", parts, paths, maxShortParts)
+			}
+
+			data, err := os.ReadFile(rec.metadataPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var metadata Metadata
+			if err := json.Unmarshal(data, &metadata); err != nil {
+				t.Fatal(err)
+			}
+
+			// 只有 ffmpeg 创建了的文件记录在附属文件中
+			want := []string{}
+			if tt.write {
+				for _, path := range paths {
+					want = append(want, filepath.Base(path))
+				}
+			}
+			if !reflect.DeepEqual(metadata.Files, want) {
+				t.Errorf("metadata files = %v, want %v", metadata.Files, want)
+			}
+			if metadata.EndedAt == nil || filepath.Dir(paths[0]) != cfg.Dir {
+				t.Errorf("metadata = %+v, paths = %v", metadata, paths)
+			}
+		})
+	}
+}
+
+func TestRecordingStop(t *testing.T) {
+	rec, _ := newTestRecording(t, codec.H264())
+	rec.backoff = time.Hour
+
+	var ctx context.Context
+	ctx, rec.cancel = context.WithCancel(context.Background())
+
+	started := make(chan string, 1)
+	rec.record = func(ctx context.Context, path string) error {
+		started <- path
+		return nil
+	}
+	go rec.run(ctx, func() {})
+
+	// H.264 不能封装到 webm
+	if path := <-started; filepath.Ext(path) != matroskaExt {
+		t.Errorf("path = %s, want %s", path, matroskaExt)
+	}
+
+	// 等待重试时也能立即停止
+	stopped := make(chan struct{})
+	go func() {
+		rec.stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop blocked during backoff")
+	}
+}
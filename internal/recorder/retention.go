@@ -0,0 +1,106 @@
+package recorder
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	webmExt     = ".webm"
+	matroskaExt = ".mkv"
+	metadataExt = ".json"
+)
+
+// sessionFiles 是属于同一个会话的所有录像文件和附属文件
+type sessionFiles struct {
+	id     string
+	paths  []string
+	size   int64
+	latest time.Time
+}
+
+// sessionIDOf 从文件名中解析会话 ID，文件名格式为 `<id>-<part>.webm`、`<id>-<part>.mkv` 或 `<id>.json`
+func sessionIDOf(name string) (string, bool) {
+	switch ext := filepath.Ext(name); ext {
+	case webmExt, matroskaExt:
+		id, _, ok := strings.Cut(strings.TrimSuffix(name, ext), "-")
+		return id, ok && id != ""
+	case metadataExt:
+		id := strings.TrimSuffix(name, metadataExt)
+		return id, id != ""
+	default:
+		return "", false
+	}
+}
+
+// enforceRetention 以会话为单位删除超过 maxAge 的录像，然后从最旧的会话开始删除，直到总大小不超过 maxTotal。
+//
+// active 返回 true 的会话正在录制，不会被删除。maxAge 或 maxTotal 为 0 表示不限制。
+func enforceRetention(dir string, maxAge time.Duration, maxTotal int64, active func(id string) bool, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*sessionFiles)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		id, ok := sessionIDOf(entry.Name())
+		if !ok {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		group, ok := groups[id]
+		if !ok {
+			group = &sessionFiles{id: id}
+			groups[id] = group
+		}
+		group.paths = append(group.paths, filepath.Join(dir, entry.Name()))
+		group.size += info.Size()
+		if info.ModTime().After(group.latest) {
+			group.latest = info.ModTime()
+		}
+	}
+
+	sorted := make([]*sessionFiles, 0, len(groups))
+	var total int64
+	for _, group := range groups {
+		sorted = append(sorted, group)
+		total += group.size
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].latest.Before(sorted[j].latest) })
+
+	var removed []string
+	for _, group := range sorted {
+		if active(group.id) {
+			continue
+		}
+
+		expired := maxAge > 0 && now.Sub(group.latest) > maxAge
+		oversize := maxTotal > 0 && total > maxTotal
+		if !expired && !oversize {
+			continue
+		}
+
+		for _, path := range group.paths {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return removed, err
+			}
+		}
+		total -= group.size
+		removed = append(removed, group.id)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,87 @@
+package recorder
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestEnforceRetention(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	files := []struct {
+		name string
+		size int
+		age  time.Duration
+	}{
+		{"old-000.webm", 10, 48 * time.Hour},
+		{"old.json", 1, 48 * time.Hour},
+		{"mid-000.webm", 30, 3 * time.Hour},
+		{"mid-001.webm", 30, 2 * time.Hour},
+		{"mid.json", 1, 2 * time.Hour},
+		{"new-000.webm", 30, time.Hour},
+		{"new.json", 1, time.Hour},
+		{"live-000.webm", 100, 72 * time.Hour},
+		{"notes.txt", 1000, 72 * time.Hour},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, make([]byte, f.size), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(-f.age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	active := func(id string) bool { return id == "live" }
+
+	// old 过期；剩余 mid(61) + new(31) + live(100) = 192 > 150，删除最旧且未在录制的 mid
+	removed, err := enforceRetention(dir, 24*time.Hour, 150, active, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(removed)
+	if want := []string{"mid", "old"}; len(removed) != len(want) || removed[0] != want[0] || removed[1] != want[1] {
+		t.Fatalf("removed %v, want %v", removed, want)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, entry := range entries {
+		left = append(left, entry.Name())
+	}
+	want := []string{"live-000.webm", "new-000.webm", "new.json", "notes.txt"}
+	if len(left) != len(want) {
+		t.Fatalf("left %v, want %v", left, want)
+	}
+	for i := range want {
+		if left[i] != want[i] {
+			t.Fatalf("left %v, want %v", left, want)
+		}
+	}
+}
+
+func TestEnforceRetentionUnlimited(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "abc-000.webm")
+	if err := os.WriteFile(path, make([]byte, 10), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-365 * 24 * time.Hour)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := enforceRetention(dir, 0, 0, func(string) bool { return false }, time.Now())
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("removed %v, err %v; want nothing removed", removed, err)
+	}
+}
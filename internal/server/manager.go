@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"image"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+// Desktop 是 HTTP 服务需要的桌面能力
+type Desktop interface {
+	Screenshot() (image.Image, error)
+}
+
+type Manager struct {
+	logger  *yalog.Logger
+	config  *config.Server
+	desktop Desktop
+	mux     *http.ServeMux
+	server  *http.Server
+}
+
+func New(desktop Desktop, cfg *config.Server) *Manager {
+	manager := &Manager{
+		logger:  yalog.Default().With("module", "server"),
+		config:  cfg,
+		desktop: desktop,
+		mux:     http.NewServeMux(),
+	}
+
+	manager.mux.Handle("GET /snapshot.png", manager.Authenticate(manager.snapshotHandler(formatPNG)))
+	manager.mux.Handle("GET /snapshot.jpg", manager.Authenticate(manager.snapshotHandler(formatJPEG)))
+
+	return manager
+}
+
+// Handle 注册额外的路由
+func (manager *Manager) Handle(pattern string, handler http.Handler) {
+	manager.mux.Handle(pattern, handler)
+}
+
+func (manager *Manager) Start() {
+	if manager.config.Token == "" {
+		manager.logger.Warn("No token configured, authenticated endpoints will reject all requests")
+	}
+
+	manager.server = &http.Server{
+		Addr:              manager.config.Bind,
+		Handler:           manager.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := manager.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			manager.logger.Fatal("HTTP server failed", "error", err)
+		}
+	}()
+
+	manager.logger.Info("HTTP server started", "bind", manager.config.Bind)
+}
+
+func (manager *Manager) Shutdown() error {
+	if manager.server == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return manager.server.Shutdown(ctx)
+}
+
+// Authenticate 校验请求中的令牌，支持 `Authorization: Bearer <token>` 和 `?token=<token>` 两种方式
+func (manager *Manager) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			token, _ = strings.CutPrefix(auth, "Bearer ")
+		}
+
+		expected := manager.config.Token
+		if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="lindows"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/m4n5ter/lindows/internal/desktop/screenshot"
+)
+
+const (
+	formatPNG  = "png"
+	formatJPEG = "jpg"
+
+	defaultJPEGQuality = 80
+)
+
+type snapshotOptions struct {
+	width   int
+	height  int
+	scale   float64
+	quality int
+}
+
+// parseSnapshotOptions 解析 width、height、scale 和 quality 查询参数
+func parseSnapshotOptions(query url.Values) (snapshotOptions, error) {
+	options := snapshotOptions{quality: defaultJPEGQuality}
+
+	for _, param := range []struct {
+		name     string
+		value    *int
+		min, max int
+	}{
+		{"width", &options.width, 1, 16384},
+		{"height", &options.height, 1, 16384},
+		{"quality", &options.quality, 1, 100},
+	} {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < param.min || v > param.max {
+			return options, fmt.Errorf("invalid %s %q, must be between %d and %d", param.name, raw, param.min, param.max)
+		}
+		*param.value = v
+	}
+
+	if raw := query.Get("scale"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 || v > 1 {
+			return options, fmt.Errorf("invalid scale %q, must be in (0, 1]", raw)
+		}
+		options.scale = v
+	}
+
+	return options, nil
+}
+
+// resize 按 width/height 或 scale 缩放图像，都未指定时返回原图
+func (options snapshotOptions) resize(img image.Image) image.Image {
+	bounds := img.Bounds()
+	width, height := options.width, options.height
+	if width == 0 && height == 0 && options.scale > 0 && options.scale < 1 {
+		width = max(int(float64(bounds.Dx())*options.scale), 1)
+	}
+	if width == 0 && height == 0 {
+		return img
+	}
+
+	width, height = screenshot.FitSize(bounds, width, height)
+	return screenshot.Scale(img, width, height)
+}
+
+func (manager *Manager) snapshotHandler(format string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		options, err := parseSnapshotOptions(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		img, err := manager.desktop.Screenshot()
+		if err != nil {
+			manager.logger.Error("Failed to take screenshot", "error", err)
+			http.Error(w, "failed to take screenshot", http.StatusInternalServerError)
+			return
+		}
+		img = options.resize(img)
+
+		var buf bytes.Buffer
+		contentType := "image/png"
+		if format == formatJPEG {
+			contentType = "image/jpeg"
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: options.quality})
+		} else {
+			err = png.Encode(&buf, img)
+		}
+		if err != nil {
+			manager.logger.Error("Failed to encode screenshot", "format", format, "error", err)
+			http.Error(w, "failed to encode screenshot", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(buf.Bytes())
+	})
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/desktop/screenshot"
+)
+
+type fakeDesktop struct {
+	screenshot.Fake
+}
+
+func (desktop fakeDesktop) Screenshot() (image.Image, error) {
+	return desktop.Grab()
+}
+
+func newTestManager() *Manager {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	img.SetRGBA(0, 0, color.RGBA{R: 0xFF, A: 0xFF})
+
+	return New(fakeDesktop{screenshot.Fake{Image: img}}, &config.Server{Token: "secret"})
+}
+
+func TestSnapshotRequiresToken(t *testing.T) {
+	manager := newTestManager()
+
+	for _, target := range []string{"/snapshot.png", "/snapshot.png?token=wrong"} {
+		rec := httptest.NewRecorder()
+		manager.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: got status %d, want %d", target, rec.Code, http.StatusUnauthorized)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/snapshot.png", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	manager.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	// 未配置 token 时拒绝所有请求
+	manager.config.Token = ""
+	rec = httptest.NewRecorder()
+	manager.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/snapshot.png?token=", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d with empty token, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestSnapshotScaling(t *testing.T) {
+	manager := newTestManager()
+
+	cases := []struct {
+		target        string
+		width, height int
+	}{
+		{"/snapshot.png?token=secret", 400, 200},
+		{"/snapshot.png?token=secret&width=100", 100, 50},
+		{"/snapshot.png?token=secret&height=50", 100, 50},
+		{"/snapshot.png?token=secret&width=40&height=40", 40, 40},
+		{"/snapshot.jpg?token=secret&scale=0.5&quality=50", 200, 100},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		manager.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d: %s", c.target, rec.Code, rec.Body.String())
+		}
+
+		var img image.Image
+		var err error
+		if rec.Header().Get("Content-Type") == "image/jpeg" {
+			img, err = jpeg.Decode(rec.Body)
+		} else {
+			img, err = png.Decode(rec.Body)
+		}
+		if err != nil {
+			t.Fatalf("%s: failed to decode: %v", c.target, err)
+		}
+
+		if got := img.Bounds().Size(); got != image.Pt(c.width, c.height) {
+			t.Errorf("%s: got size %v, want %dx%d", c.target, got, c.width, c.height)
+		}
+	}
+}
+
+func TestSnapshotInvalidOptions(t *testing.T) {
+	manager := newTestManager()
+
+	for _, query := range []string{"width=0", "height=abc", "scale=2", "quality=101"} {
+		rec := httptest.NewRecorder()
+		manager.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/snapshot.jpg?token=secret&"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// maxPermissionsSize 限制修改权限的请求体大小
+const maxPermissionsSize = 4 << 10 // 4 KB
+
+// Status 是会话在 HTTP 接口中的表示
+type Status struct {
+	ID          string    `json:"id"`
+	PeerAddress string    `json:"peer_address"`
+	User        string    `json:"user,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	Controller  bool      `json:"controller"`
+	Permissions string    `json:"permissions"`
+}
+
+func (session *Session) Status() Status {
+	return Status{
+		ID:          session.id,
+		PeerAddress: session.peerAddress,
+		User:        session.user,
+		CreatedAt:   session.createdAt,
+		Controller:  session.IsController(),
+		Permissions: session.Permissions().String(),
+	}
+}
+
+// Handler 返回会话的 HTTP 接口，主机端可以单独调整某个会话的权限:
+//
+//	GET /sessions                   列出所有会话
+//	PUT /sessions/{id}/permissions  设置会话的权限，请求体是权限名称的列表，例如 ["macro","clipboard"]
+func (manager *Manager) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /sessions", func(w http.ResponseWriter, r *http.Request) {
+		sessions := manager.List()
+		statuses := make([]Status, 0, len(sessions))
+		for _, session := range sessions {
+			statuses = append(statuses, session.Status())
+		}
+		writeJSON(w, http.StatusOK, statuses)
+	})
+
+	mux.HandleFunc("PUT /sessions/{id}/permissions", func(w http.ResponseWriter, r *http.Request) {
+		var names []string
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPermissionsSize)).Decode(&names); err != nil {
+			http.Error(w, "invalid permissions", http.StatusBadRequest)
+			return
+		}
+
+		permissions, err := ParsePermissions(names)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		session, ok := manager.Get(r.PathValue("id"))
+		if !ok || manager.SetPermissions(session.ID(), permissions) != nil {
+			http.Error(w, ErrSessionNotFound.Error(), http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, session.Status())
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/config"
+)
+
+func TestHandler(t *testing.T) {
+	manager := New(&config.Session{Permissions: []string{"clipboard"}})
+	handler := manager.Handler()
+
+	a := manager.Create("10.0.0.1:1000", "a")
+	manager.Create("10.0.0.2:2000", "b")
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := do(http.MethodGet, "/sessions", "")
+	var statuses []Status
+	if err := json.NewDecoder(w.Body).Decode(&statuses); err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("GET /sessions returned %d sessions, want 2", len(statuses))
+	}
+	for _, status := range statuses {
+		if status.Permissions != "clipboard" || status.Controller != (status.ID == a.ID()) {
+			t.Errorf("status = %+v", status)
+		}
+	}
+
+	w = do(http.MethodPut, "/sessions/"+a.ID()+"/permissions", `["system", "macro"]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT permissions: got %d %s", w.Code, w.Body)
+	}
+	var status Status
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Permissions != "system,macro" || !a.Can(PermissionSystem|PermissionMacro) || a.Can(PermissionClipboard) {
+		t.Errorf("permissions = %q, session has %s", status.Permissions, a.Permissions())
+	}
+
+	// 空列表收回所有权限
+	if w := do(http.MethodPut, "/sessions/"+a.ID()+"/permissions", `[]`); w.Code != http.StatusOK || a.Permissions() != 0 {
+		t.Errorf("PUT []: got %d, permissions %s", w.Code, a.Permissions())
+	}
+
+	for _, test := range []struct {
+		target, body string
+		code         int
+	}{
+		{"/sessions/" + a.ID() + "/permissions", `["admin"]`, http.StatusBadRequest},
+		{"/sessions/" + a.ID() + "/permissions", `"system"`, http.StatusBadRequest},
+		{"/sessions/unknown/permissions", `["system"]`, http.StatusNotFound},
+	} {
+		if w := do(http.MethodPut, test.target, test.body); w.Code != test.code {
+			t.Errorf("PUT %s %s: got %d, want %d", test.target, test.body, w.Code, test.code)
+		}
+	}
+	if a.Permissions() != 0 {
+		t.Errorf("rejected requests changed permissions to %s", a.Permissions())
+	}
+}
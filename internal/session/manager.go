@@ -0,0 +1,208 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type Manager struct {
+	logger *yalog.Logger
+
+	mu         sync.RWMutex
+	sessions   map[string]*Session
+	controller *Session
+	// permissions 是新会话默认拥有的权限
+	permissions Permission
+
+	listenersMu         sync.RWMutex
+	onCreated           []func(session *Session)
+	onDestroyed         []func(session *Session)
+	onControllerChanged []func(controller *Session)
+}
+
+func New(cfg *config.Session) *Manager {
+	manager := &Manager{
+		logger:   yalog.Default().With("module", "session"),
+		sessions: make(map[string]*Session),
+	}
+
+	permissions, err := ParsePermissions(cfg.Permissions)
+	if err != nil {
+		manager.logger.Error("Invalid session permissions", "permissions", cfg.Permissions, "error", err)
+	}
+	manager.permissions = permissions
+
+	return manager
+}
+
+// Create 创建一个新会话，如果当前没有控制者，新会话会自动获得控制权
+func (manager *Manager) Create(peerAddress, user string) *Session {
+	session := &Session{
+		id:          newID(),
+		peerAddress: peerAddress,
+		user:        user,
+		createdAt:   time.Now(),
+		manager:     manager,
+	}
+	session.permissions.Store(uint32(manager.permissions))
+
+	manager.mu.Lock()
+	manager.sessions[session.id] = session
+	takeControl := manager.controller == nil
+	if takeControl {
+		manager.controller = session
+	}
+	manager.mu.Unlock()
+
+	manager.logger.Info("Session created", "session_id", session.id, "peer_address", peerAddress, "user", user, "permissions", session.Permissions())
+	manager.emit(&manager.onCreated, session)
+	if takeControl {
+		manager.emit(&manager.onControllerChanged, session)
+	}
+
+	return session
+}
+
+// Destroy 销毁会话，如果它是控制者则同时释放控制权
+func (manager *Manager) Destroy(id string) {
+	manager.mu.Lock()
+	session, ok := manager.sessions[id]
+	if !ok {
+		manager.mu.Unlock()
+		return
+	}
+	delete(manager.sessions, id)
+	wasController := manager.controller == session
+	if wasController {
+		manager.controller = nil
+	}
+	manager.mu.Unlock()
+
+	if wasController {
+		manager.emit(&manager.onControllerChanged, nil)
+	}
+	manager.emit(&manager.onDestroyed, session)
+	manager.logger.Info("Session destroyed", "session_id", id)
+}
+
+func (manager *Manager) Get(id string) (*Session, bool) {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	session, ok := manager.sessions[id]
+	return session, ok
+}
+
+// List 返回所有会话
+func (manager *Manager) List() []*Session {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	sessions := make([]*Session, 0, len(manager.sessions))
+	for _, session := range manager.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// Controller 返回当前拥有控制权的会话，没有时返回 nil
+func (manager *Manager) Controller() *Session {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	return manager.controller
+}
+
+// SetController 把控制权交给指定会话
+func (manager *Manager) SetController(id string) error {
+	manager.mu.Lock()
+	session, ok := manager.sessions[id]
+	if !ok {
+		manager.mu.Unlock()
+		return ErrSessionNotFound
+	}
+	changed := manager.controller != session
+	manager.controller = session
+	manager.mu.Unlock()
+
+	if changed {
+		manager.logger.Info("Controller changed", "session_id", id)
+		manager.emit(&manager.onControllerChanged, session)
+	}
+	return nil
+}
+
+// ReleaseController 释放指定会话的控制权，如果它不是控制者则什么也不做
+func (manager *Manager) ReleaseController(id string) {
+	manager.mu.Lock()
+	released := manager.controller != nil && manager.controller.id == id
+	if released {
+		manager.controller = nil
+	}
+	manager.mu.Unlock()
+
+	if released {
+		manager.logger.Info("Controller released", "session_id", id)
+		manager.emit(&manager.onControllerChanged, nil)
+	}
+}
+
+// SetPermissions 修改指定会话的权限
+func (manager *Manager) SetPermissions(id string, permissions Permission) error {
+	session, ok := manager.Get(id)
+	if !ok {
+		return ErrSessionNotFound
+	}
+
+	session.permissions.Store(uint32(permissions))
+	manager.logger.Info("Session permissions changed", "session_id", id, "permissions", permissions)
+	return nil
+}
+
+// OnCreated 注册会话创建事件的回调
+func (manager *Manager) OnCreated(listener func(session *Session)) {
+	manager.listenersMu.Lock()
+	defer manager.listenersMu.Unlock()
+
+	manager.onCreated = append(manager.onCreated, listener)
+}
+
+// OnDestroyed 注册会话销毁事件的回调
+func (manager *Manager) OnDestroyed(listener func(session *Session)) {
+	manager.listenersMu.Lock()
+	defer manager.listenersMu.Unlock()
+
+	manager.onDestroyed = append(manager.onDestroyed, listener)
+}
+
+// OnControllerChanged 注册控制者变化事件的回调，控制权被释放时 controller 为 nil
+func (manager *Manager) OnControllerChanged(listener func(controller *Session)) {
+	manager.listenersMu.Lock()
+	defer manager.listenersMu.Unlock()
+
+	manager.onControllerChanged = append(manager.onControllerChanged, listener)
+}
+
+func (manager *Manager) emit(listeners *[]func(session *Session), session *Session) {
+	manager.listenersMu.RLock()
+	fns := *listeners
+	manager.listenersMu.RUnlock()
+
+	for _, fn := range fns {
+		fn(session)
+	}
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
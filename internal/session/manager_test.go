@@ -0,0 +1,100 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/config"
+)
+
+func TestManagerController(t *testing.T) {
+	manager := New(&config.Session{})
+
+	var events []string
+	record := func(event string) func(*Session) {
+		return func(session *Session) {
+			id := "nil"
+			if session != nil {
+				id = session.User()
+			}
+			events = append(events, event+":"+id)
+		}
+	}
+	manager.OnCreated(record("created"))
+	manager.OnDestroyed(record("destroyed"))
+	manager.OnControllerChanged(record("controller"))
+
+	// 第一个会话自动获得控制权，之后的会话不会
+	a := manager.Create("10.0.0.1:1000", "a")
+	b := manager.Create("10.0.0.2:2000", "b")
+	if !a.IsController() || b.IsController() {
+		t.Errorf("controller = %v, want a", manager.Controller())
+	}
+	if a.ID() == b.ID() || a.PeerAddress() != "10.0.0.1:1000" {
+		t.Errorf("sessions a = %+v, b = %+v", a, b)
+	}
+	if got, ok := manager.Get(b.ID()); !ok || got != b {
+		t.Errorf("Get(b) = %v, %t", got, ok)
+	}
+	if len(manager.List()) != 2 {
+		t.Errorf("List() = %v, want 2 sessions", manager.List())
+	}
+
+	// 交给当前控制者不会重复通知
+	if err := manager.SetController(a.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SetController(b.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SetController("missing"); err != ErrSessionNotFound {
+		t.Errorf("SetController(missing) = %v, want ErrSessionNotFound", err)
+	}
+
+	// 不是控制者的会话释放控制权什么也不做
+	manager.ReleaseController(a.ID())
+	manager.ReleaseController(b.ID())
+	if manager.Controller() != nil {
+		t.Errorf("controller = %v after release, want nil", manager.Controller())
+	}
+
+	// 销毁控制者时先通知控制权被释放，再通知销毁
+	_ = manager.SetController(a.ID())
+	manager.Destroy(a.ID())
+	manager.Destroy(a.ID())
+	manager.Destroy(b.ID())
+	if _, ok := manager.Get(a.ID()); ok || len(manager.List()) != 0 {
+		t.Errorf("sessions left after Destroy: %v", manager.List())
+	}
+
+	want := []string{
+		"created:a", "controller:a",
+		"created:b",
+		"controller:b",
+		"controller:nil",
+		"controller:a", "controller:nil", "destroyed:a",
+		"destroyed:b",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestManagerPermissions(t *testing.T) {
+	manager := New(&config.Session{Permissions: []string{"clipboard"}})
+
+	session := manager.Create("", "")
+	if session.Permissions() != PermissionClipboard || !session.Can(PermissionClipboard) || session.Can(PermissionSystem) {
+		t.Errorf("default permissions = %v, want clipboard", session.Permissions())
+	}
+
+	if err := manager.SetPermissions(session.ID(), PermissionSystem|PermissionMacro); err != nil {
+		t.Fatal(err)
+	}
+	if !session.Can(PermissionSystem|PermissionMacro) || session.Can(PermissionClipboard) {
+		t.Errorf("permissions = %v, want system,macro", session.Permissions())
+	}
+	if err := manager.SetPermissions("missing", 0); err != ErrSessionNotFound {
+		t.Errorf("SetPermissions(missing) = %v, want ErrSessionNotFound", err)
+	}
+}
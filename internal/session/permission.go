@@ -0,0 +1,66 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Permission 是会话除键盘鼠标之外可以执行的操作，按位组合
+type Permission uint32
+
+const (
+	// PermissionSystem 允许发送安全注意序列、锁定、注销等系统命令
+	PermissionSystem Permission = 1 << iota
+	// PermissionMacro 允许录制和回放输入宏
+	PermissionMacro
+	// PermissionClipboard 允许与主机双向同步剪切板
+	PermissionClipboard
+)
+
+type permissionName struct {
+	name       string
+	permission Permission
+}
+
+// permissionNames 按位的顺序列出权限的名称
+var permissionNames = []permissionName{
+	{"system", PermissionSystem},
+	{"macro", PermissionMacro},
+	{"clipboard", PermissionClipboard},
+}
+
+func (permission Permission) String() string {
+	var names []string
+	for _, p := range permissionNames {
+		if permission&p.permission != 0 {
+			names = append(names, p.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// ParsePermissions 解析权限名称列表，例如 system、macro、clipboard
+func ParsePermissions(names []string) (Permission, error) {
+	var permissions Permission
+
+next:
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		for _, p := range permissionNames {
+			if p.name == name {
+				permissions |= p.permission
+				continue next
+			}
+		}
+		return permissions, fmt.Errorf("unknown permission %q", name)
+	}
+
+	return permissions, nil
+}
@@ -0,0 +1,25 @@
+package session
+
+import "testing"
+
+func TestParsePermissions(t *testing.T) {
+	permissions, err := ParsePermissions([]string{" system", ""})
+	if err != nil || permissions != PermissionSystem {
+		t.Errorf("ParsePermissions = %v, %v, want system", permissions, err)
+	}
+	if permissions.String() != "system" {
+		t.Errorf("String() = %q", permissions.String())
+	}
+
+	permissions, err = ParsePermissions([]string{"macro", "system"})
+	if err != nil || permissions != PermissionSystem|PermissionMacro || permissions.String() != "system,macro" {
+		t.Errorf("ParsePermissions(macro, system) = %v, %v", permissions, err)
+	}
+
+	if permissions, err := ParsePermissions(nil); err != nil || permissions != 0 || permissions.String() != "none" {
+		t.Errorf("ParsePermissions(nil) = %v, %v", permissions, err)
+	}
+	if _, err := ParsePermissions([]string{"root"}); err == nil {
+		t.Error("ParsePermissions(root) succeeded")
+	}
+}
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Session 表示一个已连接的远程桌面会话
+type Session struct {
+	id          string
+	peerAddress string
+	user        string
+	createdAt   time.Time
+	permissions atomic.Uint32
+
+	manager *Manager
+}
+
+func (session *Session) ID() string {
+	return session.id
+}
+
+// PeerAddress 返回信令连接的远程地址
+func (session *Session) PeerAddress() string {
+	return session.peerAddress
+}
+
+// User 返回客户端声明的用户名，可能为空
+func (session *Session) User() string {
+	return session.user
+}
+
+func (session *Session) CreatedAt() time.Time {
+	return session.createdAt
+}
+
+// IsController 判断该会话当前是否拥有控制权
+func (session *Session) IsController() bool {
+	return session.manager.Controller() == session
+}
+
+// Permissions 返回会话拥有的权限
+func (session *Session) Permissions() Permission {
+	return Permission(session.permissions.Load())
+}
+
+// Can 判断会话是否拥有 permission 中的所有权限
+func (session *Session) Can(permission Permission) bool {
+	return session.Permissions()&permission == permission
+}
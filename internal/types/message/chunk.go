@@ -0,0 +1,98 @@
+package message
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// ChunkSize 是分块传输时每块的原始字节数，base64 编码后为 48KiB，
+// 加上消息的其他字段仍小于数据通道单条消息 64KiB 的限制
+const ChunkSize = 36 * 1024
+
+// Chunks 把 data 分为 event 事件的多条消息，id 区分不同的传输，空的 data 也有一块
+func Chunks(event uint8, id int32, data []byte) []Message {
+	total := max(1, (len(data)+ChunkSize-1)/ChunkSize)
+
+	msgs := make([]Message, total)
+	for i := range total {
+		part := data[i*ChunkSize : min(len(data), (i+1)*ChunkSize)]
+		msgs[i] = Message{
+			Event: event,
+			P1:    id,
+			P2:    int32(i),
+			P3:    int32(total),
+			P4:    base64.StdEncoding.EncodeToString(part),
+		}
+	}
+	return msgs
+}
+
+// Assembler 把 Chunks 分出的消息按顺序拼回原来的数据，数据通道是有序的，
+// 所以只接受下一块；新的编号会丢弃还没有完成的传输
+type Assembler struct {
+	maxSize int
+
+	id     int32
+	next   int32
+	total  int32
+	data   []byte
+	active bool
+}
+
+// NewAssembler 创建 Assembler，maxSize 是拼接后数据的最大字节数
+func NewAssembler(maxSize int) *Assembler {
+	return &Assembler{maxSize: maxSize}
+}
+
+// Add 加入一块，最后一块到达时返回完整的数据，否则返回 nil。出错时丢弃这次传输
+func (assembler *Assembler) Add(msg Message) ([]byte, error) {
+	data, err := assembler.add(msg)
+	if err != nil {
+		assembler.reset()
+	}
+	return data, err
+}
+
+func (assembler *Assembler) add(msg Message) ([]byte, error) {
+	id, index, total := msg.P1, msg.P2, msg.P3
+
+	maxChunks := int32(max(1, (assembler.maxSize+ChunkSize-1)/ChunkSize))
+	if total <= 0 || total > maxChunks {
+		return nil, fmt.Errorf("%w: %d chunks, limit %d", ErrInvalid, total, maxChunks)
+	}
+
+	if index == 0 {
+		// 第一块总是开始新的传输
+		assembler.reset()
+		assembler.id, assembler.total, assembler.active = id, total, true
+	}
+	if !assembler.active || id != assembler.id || index != assembler.next || total != assembler.total {
+		return nil, fmt.Errorf("%w: unexpected chunk %d/%d of transfer %d", ErrInvalid, index, total, id)
+	}
+
+	part, err := base64.StdEncoding.DecodeString(msg.P4)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if len(part) > ChunkSize || len(assembler.data)+len(part) > assembler.maxSize {
+		return nil, fmt.Errorf("%w: transfer %d exceeds %d bytes", ErrInvalid, id, assembler.maxSize)
+	}
+
+	assembler.data = append(assembler.data, part...)
+	assembler.next++
+	if assembler.next < assembler.total {
+		return nil, nil
+	}
+
+	data := assembler.data
+	assembler.reset()
+	if data == nil {
+		data = []byte{}
+	}
+	return data, nil
+}
+
+func (assembler *Assembler) reset() {
+	assembler.id, assembler.next, assembler.total = 0, 0, 0
+	assembler.data, assembler.active = nil, false
+}
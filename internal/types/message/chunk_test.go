@@ -0,0 +1,86 @@
+package message
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestChunks(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), ChunkSize/4)
+
+	msgs := Chunks(ClipboardImage, 7, data)
+	if len(msgs) != 3 {
+		t.Fatalf("Chunks returned %d messages, want 3", len(msgs))
+	}
+	for i, msg := range msgs {
+		if msg.Event != ClipboardImage || msg.P1 != 7 || msg.P2 != int32(i) || msg.P3 != 3 {
+			t.Errorf("chunk %d = %+v", i, msg)
+		}
+		// 每块编码后都要能放进一条数据通道消息
+		if size := len(Encode(msg)); size > 64*1024 {
+			t.Errorf("chunk %d encodes to %d bytes", i, size)
+		}
+	}
+
+	assembler := NewAssembler(len(data))
+	for i, msg := range msgs {
+		got, err := assembler.Add(msg)
+		if err != nil {
+			t.Fatalf("Add(chunk %d): %v", i, err)
+		}
+		if last := i == len(msgs)-1; last != (got != nil) {
+			t.Fatalf("Add(chunk %d) returned data = %t", i, got != nil)
+		}
+		if got != nil && !bytes.Equal(got, data) {
+			t.Error("assembled data differs")
+		}
+	}
+
+	// 空数据也是一次完整的传输
+	got, err := assembler.Add(Chunks(ClipboardImage, 8, nil)[0])
+	if err != nil || got == nil || len(got) != 0 {
+		t.Errorf("Add(empty) = %v, %v", got, err)
+	}
+}
+
+func TestAssemblerInvalid(t *testing.T) {
+	data := bytes.Repeat([]byte{1}, 2*ChunkSize+1)
+	msgs := Chunks(ClipboardImage, 1, data)
+
+	for name, tt := range map[string]struct {
+		maxSize int
+		msgs    []Message
+	}{
+		"too many chunks": {ChunkSize, msgs[:1]},
+		"missing first":   {len(data), msgs[1:]},
+		"skipped chunk":   {len(data), []Message{msgs[0], msgs[2]}},
+		"other transfer":  {len(data), []Message{msgs[0], {Event: ClipboardImage, P1: 2, P2: 1, P3: 3}}},
+		"bad base64":      {len(data), []Message{{Event: ClipboardImage, P1: 1, P3: 1, P4: "!!"}}},
+		"too large":       {ChunkSize - 1, Chunks(ClipboardImage, 1, data[:ChunkSize])},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assembler := NewAssembler(tt.maxSize)
+			var err error
+			for _, msg := range tt.msgs {
+				if _, err = assembler.Add(msg); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("Add = %v, want ErrInvalid", err)
+			}
+		})
+	}
+
+	// 出错后新的传输可以正常开始
+	assembler := NewAssembler(len(data))
+	_, _ = assembler.Add(msgs[1])
+	for _, msg := range msgs {
+		if got, err := assembler.Add(msg); err != nil {
+			t.Fatalf("Add after error: %v", err)
+		} else if got != nil && !bytes.Equal(got, data) {
+			t.Error("assembled data differs")
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Package message 编解码数据通道中的 flatbuffers 消息，与 lindows-client 的协议保持一致
+package message
+
+import (
+	"errors"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/m4n5ter/lindows/pkg/flat/lindowsmsg"
+)
+
+// KeyCount 是旧版客户端 Event 枚举中键盘事件的数量，见 input.LegacyKeys
+const KeyCount = 198
+
+// 客户端 Event 枚举中键盘事件之后的部分
+const (
+	MouseMove uint8 = KeyCount + iota
+	MouseLeftDown
+	MouseLeftUp
+	MouseRightDown
+	MouseRightUp
+	MouseMiddleDown
+	MouseMiddleUp
+	// MouseXDown 和 MouseXUp 的 p1 为 1 表示 XBUTTON1，2 表示 XBUTTON2
+	MouseXDown
+	MouseXUp
+	// MouseWheel 的 p1/p2/p3 是 WheelEvent 的 deltaX/deltaY/deltaZ，以像素为单位
+	MouseWheel
+	MouseHWheel
+	// MouseAbsolute 的 p1/p2 是客户端渲染的视频画面上的像素坐标，p3 是 PackSize 打包的画面尺寸
+	MouseAbsolute
+	Unidentified
+	// Clipboard 在 common 通道双向同步剪切板的文本：p4 为以 \n 换行的文本，
+	// 只有拥有 clipboard 权限的会话会收到主机剪切板的变化，它发来的文本也才会写入主机剪切板。
+	// p1 为 ClipboardFormatHTML 和 ClipboardFormatRTF 的组合，表示紧接在前面发送的富文本与这段文本是同一次复制的内容；
+	// 带有 HTML 时 p2/p3 为复制的片段在 HTML 文档中的起止字节偏移，无效时按片段标记查找
+	Clipboard
+
+	// CursorShape 由服务端在 common 通道发送：p1/p2 为热点坐标，p3 为光标序号，p4 为 base64 编码的 PNG。
+	// PNG 超过 ChunkSize 时 p4 为空，图像在紧接着前面的 CursorImage 中
+	CursorShape
+	// CursorPosition 由服务端在 common 通道发送：p1/p2 为位置占屏幕的比例乘以 10000，p3 为 1 表示光标可见
+	CursorPosition
+
+	// FocusLost 由客户端在 key 或 mouse 通道发送，表示页面失去焦点，服务端会释放该会话按住的所有按键
+	FocusLost
+
+	// PointerLock 由客户端在 mouse 通道发送：p1 为 1 表示进入 Pointer Lock，切换到相对指针模式，0 表示退出
+	PointerLock
+	// MouseRelative 由客户端在相对指针模式下发送：p1/p2 为 MouseEvent 的 movementX/movementY
+	MouseRelative
+
+	// Touch 由客户端在 mouse 通道发送，每个触点一条：p1/p2 为位置占画面的比例乘以 Ratio，
+	// p3 为 PointerDown/PointerMove/PointerUp/PointerCancel，p4 为 Pointer 的 JSON，ID 区分不同触点
+	Touch
+	// Pen 由客户端在 mouse 通道发送：p1/p2 与 Touch 相同，p3 为 PointerHover 到 PointerCancel，p4 为 Pointer 的 JSON
+	Pen
+
+	// TextInput 由客户端在 key 通道发送：p4 为客户端输入法提交的文本，会替换之前的 TextComposition 预览
+	TextInput
+	// TextComposition 由客户端在 key 通道发送：p4 为输入法正在组字的预览，为空表示组字被取消
+	TextComposition
+
+	// KeyCode 由客户端在 key 通道发送，取代按序号发送的键盘事件：p3 为 0 表示按下，1 表示释放，
+	// p1 为 KeyboardDefault/KeyboardPositional/KeyboardSymbolic，p4 为 Keyboard 的 JSON
+	KeyCode
+
+	// SystemCommand 由客户端在 common 通道发送：p4 为命令名称，例如 sas、lock、task_manager；
+	// 服务端用同样的事件回复执行结果，p1 为 CommandOK 到 CommandUnsupported，p4 为命令名称
+	SystemCommand
+
+	// Macro 由客户端在 common 通道发送：p1 为 MacroRecord 到 MacroCancel，p2 为回放速度的百分比，
+	// 为 0 时按原速，p4 为宏的名称；服务端用同样的事件回复，p1 为请求的操作，p3 为 CommandOK 到
+	// CommandUnsupported，回放在结束后才回复
+	Macro
+
+	// ClipboardImage 在 common 通道双向同步剪切板的图像，权限与 Clipboard 相同。PNG 按 ChunkSize 分块，
+	// 每块一条消息：p1 为一次传输的编号，p2 为块的序号，p3 为总块数，p4 为这一块的 base64
+	ClipboardImage
+
+	// ClipboardFormats 由客户端在 common 通道发送：p1 为 ClipboardFormatImage 到 ClipboardFormatRTF 的组合，
+	// 表示客户端可以写入本地剪切板的格式，纯文本总是支持。服务端只发送客户端支持的格式中最丰富的一种，
+	// 并按新的格式重新发送当前内容；没有发送这条消息的客户端只收到纯文本和图像
+	ClipboardFormats
+	// ClipboardHTML 和 ClipboardRTF 在 common 通道双向同步与文本一起复制的富文本，按 ChunkSize 分块，
+	// p1 到 p4 与 ClipboardImage 相同。HTML 是包含 <head> 中样式的完整文档，不带 CF_HTML 的描述头；
+	// 最后一块之后紧接着发送 p1 指明这种格式的 Clipboard 文本
+	ClipboardHTML
+	ClipboardRTF
+
+	// CursorImage 由服务端在 common 通道发送超过 ChunkSize 的光标 PNG，p1 到 p4 与 ClipboardImage 相同，
+	// p1 为光标序号；最后一块之后紧接着发送 p4 为空的 CursorShape
+	CursorImage
+)
+
+// ClipboardFormats 的 p1 和 Clipboard 的 p1
+const (
+	ClipboardFormatImage int32 = 1 << iota
+	ClipboardFormatHTML
+	ClipboardFormatRTF
+)
+
+// SystemCommand 回复的 p1 和 Macro 回复的 p3
+const (
+	CommandOK int32 = iota
+	// CommandDenied 表示会话没有控制权或没有 system、macro 权限
+	CommandDenied
+	CommandFailed
+	// CommandUnsupported 表示命令或宏不存在或平台不支持
+	CommandUnsupported
+)
+
+// Macro 消息的 p1
+const (
+	// MacroRecord 开始录制
+	MacroRecord int32 = iota
+	// MacroStop 停止录制并按 p4 的名称保存
+	MacroStop
+	// MacroPlay 回放按 p4 的名称保存的宏
+	MacroPlay
+	// MacroCancel 停止正在进行的回放
+	MacroCancel
+)
+
+// KeyCode 消息的 p1，选择按物理位置还是按字符翻译
+const (
+	// KeyboardDefault 使用服务端配置的方式
+	KeyboardDefault int32 = iota
+	KeyboardPositional
+	KeyboardSymbolic
+)
+
+// Keyboard 是 KeyCode 消息 p4 中的按键
+type Keyboard struct {
+	// Code 是 KeyboardEvent.code，表示按键的物理位置，例如 KeyA、IntlYen
+	Code string `json:"code"`
+	// Key 是 KeyboardEvent.key，表示客户端键盘布局下产生的字符或按键名，例如 a、ä、Enter、Dead
+	Key string `json:"key,omitempty"`
+}
+
+// Touch 和 Pen 消息的 p3
+const (
+	// PointerHover 只用于 Pen，表示笔尖在感应范围内但没有接触屏幕
+	PointerHover int32 = iota
+	PointerDown
+	PointerMove
+	PointerUp
+	// PointerCancel 对 Touch 表示触点被系统手势等取消，对 Pen 表示笔离开感应范围
+	PointerCancel
+)
+
+// Pointer 是 Touch 和 Pen 消息 p4 中的附加信息，与 PointerEvent 的属性对应，没有的字段可以省略
+type Pointer struct {
+	// ID 是 PointerEvent.pointerId
+	ID int32 `json:"id,omitempty"`
+	// Width 和 Height 是接触区域的尺寸，以占画面的比例乘以 Ratio 表示
+	Width  int32 `json:"width,omitempty"`
+	Height int32 `json:"height,omitempty"`
+	// Pressure 为 0~1
+	Pressure float64 `json:"pressure,omitempty"`
+	// TiltX 和 TiltY 为 -90~90 度
+	TiltX int32 `json:"tiltX,omitempty"`
+	TiltY int32 `json:"tiltY,omitempty"`
+	// Twist 为 0~359 度
+	Twist int32 `json:"twist,omitempty"`
+	// Eraser 表示使用笔的橡皮擦端
+	Eraser bool `json:"eraser,omitempty"`
+	// Barrel 表示按住了笔杆上的按钮
+	Barrel bool `json:"barrel,omitempty"`
+}
+
+// Ratio 是坐标比例的基数，客户端发送和接收的坐标都是占屏幕的比例乘以 Ratio
+const Ratio = 10000
+
+var ErrInvalid = errors.New("invalid message")
+
+// PackSize 把画面尺寸打包到一个 int32 中，宽高各占 16 位
+func PackSize(width, height int) int32 {
+	return int32(uint32(width&0xffff)<<16 | uint32(height&0xffff))
+}
+
+// UnpackSize 是 PackSize 的逆操作
+func UnpackSize(size int32) (width, height int) {
+	return int(uint32(size) >> 16), int(uint32(size) & 0xffff)
+}
+
+type Message struct {
+	Event uint8
+	P1    int32
+	P2    int32
+	P3    int32
+	P4    string
+}
+
+func Encode(msg Message) []byte {
+	builder := flatbuffers.NewBuilder(64 + len(msg.P4))
+
+	var p4 flatbuffers.UOffsetT
+	if msg.P4 != "" {
+		p4 = builder.CreateString(msg.P4)
+	}
+
+	lindowsmsg.PayloadStart(builder)
+	lindowsmsg.PayloadAddP1(builder, msg.P1)
+	lindowsmsg.PayloadAddP2(builder, msg.P2)
+	lindowsmsg.PayloadAddP3(builder, msg.P3)
+	if p4 != 0 {
+		lindowsmsg.PayloadAddP4(builder, p4)
+	}
+	payload := lindowsmsg.PayloadEnd(builder)
+
+	lindowsmsg.MessageStart(builder)
+	lindowsmsg.MessageAddEvent(builder, msg.Event)
+	lindowsmsg.MessageAddPayload(builder, payload)
+	lindowsmsg.FinishMessageBuffer(builder, lindowsmsg.MessageEnd(builder))
+
+	return builder.FinishedBytes()
+}
+
+// Decode 解析客户端发送的消息，数据不完整时返回 ErrInvalid 而不是 panic
+func Decode(data []byte) (msg Message, err error) {
+	if len(data) < 8 {
+		return Message{}, ErrInvalid
+	}
+
+	// flatbuffers 不校验偏移量，损坏的数据会导致越界
+	defer func() {
+		if recover() != nil {
+			msg, err = Message{}, ErrInvalid
+		}
+	}()
+
+	root := lindowsmsg.GetRootAsMessage(data, 0)
+	msg.Event = root.Event()
+
+	if payload := root.Payload(nil); payload != nil {
+		msg.P1 = payload.P1()
+		msg.P2 = payload.P2()
+		msg.P3 = payload.P3()
+		msg.P4 = string(payload.P4())
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,58 @@
+package message
+
+import "testing"
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []Message{
+		{Event: MouseMove, P1: 5000, P2: 2500},
+		{Event: CursorShape, P1: 3, P2: 4, P3: 7, P4: "iVBORw0KGgo="},
+		{Event: 56, P3: 1},
+		{Event: Clipboard, P4: "你好\r\nworld"},
+	}
+
+	for _, want := range tests {
+		got, err := Decode(Encode(want))
+		if err != nil {
+			t.Fatalf("Decode(Encode(%+v)): %v", want, err)
+		}
+		if got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestEventValues(t *testing.T) {
+	// 与 lindows-client 的 Event 枚举对应的值
+	for event, want := range map[uint8]uint8{
+		MouseMove:    198,
+		MouseXDown:   205,
+		MouseWheel:   207,
+		Unidentified: 210,
+		Clipboard:    211,
+	} {
+		if event != want {
+			t.Errorf("event = %d, want %d", event, want)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		{1, 2, 3},
+		{0xff, 0xff, 0xff, 0x7f, 0, 0, 0, 0, 0, 0, 0, 0},
+	} {
+		if _, err := Decode(data); err != ErrInvalid {
+			t.Errorf("Decode(%v) error = %v, want ErrInvalid", data, err)
+		}
+	}
+}
+
+func TestPackSize(t *testing.T) {
+	for _, size := range [][2]int{{0, 0}, {1920, 1080}, {65535, 65535}, {3840, 1}} {
+		width, height := UnpackSize(PackSize(size[0], size[1]))
+		if width != size[0] || height != size[1] {
+			t.Errorf("UnpackSize(PackSize(%d, %d)) = %d, %d", size[0], size[1], width, height)
+		}
+	}
+}
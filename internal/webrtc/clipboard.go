@@ -0,0 +1,267 @@
+package webrtc
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/m4n5ter/lindows/internal/desktop/clipboard"
+	"github.com/m4n5ter/lindows/internal/session"
+	"github.com/m4n5ter/lindows/internal/types/message"
+	"github.com/pion/webrtc/v4"
+)
+
+// defaultClipboardFormats 是没有发送 ClipboardFormats 的客户端支持的格式
+const defaultClipboardFormats = message.ClipboardFormatImage
+
+// clipboardTransfers 保存每个会话支持的剪切板格式和正在接收的内容
+type clipboardTransfers struct {
+	// send 保证一次变化的所有块连续发送，不与另一次变化交错
+	send   sync.Mutex
+	serial int32
+
+	mu    sync.Mutex
+	peers map[string]*clipboardPeer
+}
+
+type clipboardPeer struct {
+	formats    int32
+	assemblers map[uint8]*message.Assembler
+	// rich 是已经收到、等待与文本一起写入的 HTML 和 RTF
+	rich clipboard.Content
+}
+
+// peer 返回会话的状态，调用时需要持有 mu
+func (transfers *clipboardTransfers) peer(sessionID string) *clipboardPeer {
+	peer, ok := transfers.peers[sessionID]
+	if !ok {
+		peer = &clipboardPeer{
+			formats:    defaultClipboardFormats,
+			assemblers: make(map[uint8]*message.Assembler),
+		}
+		transfers.peers[sessionID] = peer
+	}
+	return peer
+}
+
+// formats 返回每个会话支持的格式，不在其中的会话使用 defaultClipboardFormats
+func (transfers *clipboardTransfers) formats() map[string]int32 {
+	transfers.mu.Lock()
+	defer transfers.mu.Unlock()
+
+	formats := make(map[string]int32, len(transfers.peers))
+	for sessionID, peer := range transfers.peers {
+		formats[sessionID] = peer.formats
+	}
+	return formats
+}
+
+// clipboardFormat 返回向支持 formats 的客户端发送 content 时使用的格式：图像、最丰富的富文本，
+// 或者只发送纯文本的 0。HTML 能保留表格、链接和样式，浏览器也能直接使用，所以优先于 RTF。
+// 客户端不支持图像时 ok 为 false
+func clipboardFormat(content clipboard.Content, formats int32) (format int32, ok bool) {
+	switch {
+	case len(content.Image) > 0:
+		return message.ClipboardFormatImage, formats&message.ClipboardFormatImage != 0
+	case content.HTML != "" && formats&message.ClipboardFormatHTML != 0:
+		return message.ClipboardFormatHTML, true
+	case content.RTF != "" && formats&message.ClipboardFormatRTF != 0:
+		return message.ClipboardFormatRTF, true
+	}
+	return 0, true
+}
+
+// watchClipboard 把主机剪切板的变化发送给拥有 clipboard 权限的会话，不发回给写入这份内容的会话
+func (manager *Manager) watchClipboard() {
+	syncer := manager.desktop.ClipboardSync()
+	if syncer == nil {
+		return
+	}
+
+	syncer.OnChange(func(change clipboard.Change) {
+		manager.clipboard.send.Lock()
+		defer manager.clipboard.send.Unlock()
+
+		formats := manager.clipboard.formats()
+		selected := func(sessionID string) (int32, bool) {
+			peerFormats, ok := formats[sessionID]
+			if !ok {
+				peerFormats = defaultClipboardFormats
+			}
+			return clipboardFormat(change.Content, peerFormats)
+		}
+
+		// 按客户端选择的格式分组发送，每种格式只编码一次
+		for _, format := range []int32{message.ClipboardFormatImage, message.ClipboardFormatHTML, message.ClipboardFormatRTF, 0} {
+			if chosen, _ := clipboardFormat(change.Content, format); chosen != format {
+				continue
+			}
+
+			for _, msg := range manager.clipboardMessages(change.Content, format) {
+				manager.broadcast(msg, func(sessionID string) bool {
+					if sessionID == change.Origin || !manager.canClipboard(sessionID) {
+						return true
+					}
+					chosen, ok := selected(sessionID)
+					return !ok || chosen != format
+				})
+			}
+		}
+	})
+}
+
+// sendClipboard 按会话支持的格式把当前的剪切板内容发送到通道
+func (manager *Manager) sendClipboard(requester *session.Session, dc *webrtc.DataChannel) {
+	syncer := manager.desktop.ClipboardSync()
+	if syncer == nil || !requester.Can(session.PermissionClipboard) {
+		return
+	}
+
+	content, ok := syncer.Content()
+	if !ok {
+		return
+	}
+
+	manager.clipboard.mu.Lock()
+	formats := manager.clipboard.peer(requester.ID()).formats
+	manager.clipboard.mu.Unlock()
+
+	format, ok := clipboardFormat(content, formats)
+	if !ok {
+		return
+	}
+
+	manager.clipboard.send.Lock()
+	defer manager.clipboard.send.Unlock()
+
+	for _, msg := range manager.clipboardMessages(content, format) {
+		if err := dc.Send(message.Encode(msg)); err != nil {
+			manager.logger.Debug("Failed to send clipboard", "session_id", requester.ID(), "error", err)
+			return
+		}
+	}
+}
+
+// clipboardMessages 按 format 把内容编码为消息，图像和富文本分块发送，调用时需要持有 send
+func (manager *Manager) clipboardMessages(content clipboard.Content, format int32) []message.Message {
+	var msgs []message.Message
+
+	switch format {
+	case message.ClipboardFormatImage:
+		manager.clipboard.serial++
+		return message.Chunks(message.ClipboardImage, manager.clipboard.serial, content.Image)
+	case message.ClipboardFormatHTML:
+		manager.clipboard.serial++
+		msgs = message.Chunks(message.ClipboardHTML, manager.clipboard.serial, []byte(content.HTML))
+	case message.ClipboardFormatRTF:
+		manager.clipboard.serial++
+		msgs = message.Chunks(message.ClipboardRTF, manager.clipboard.serial, []byte(content.RTF))
+	}
+
+	text := message.Message{Event: message.Clipboard, P1: format, P4: content.Text}
+	if format == message.ClipboardFormatHTML {
+		text.P2, text.P3 = int32(content.FragmentStart), int32(content.FragmentEnd)
+	}
+	return append(msgs, text)
+}
+
+// setClipboardFormats 记录客户端支持的格式，并按新的格式重新发送当前内容
+func (manager *Manager) setClipboardFormats(requester *session.Session, dc *webrtc.DataChannel, formats int32) {
+	manager.clipboard.mu.Lock()
+	manager.clipboard.peer(requester.ID()).formats = formats
+	manager.clipboard.mu.Unlock()
+
+	manager.sendClipboard(requester, dc)
+}
+
+// receiveClipboardChunk 接收会话发来的图像或富文本块。图像的最后一块到达后写入主机剪切板，
+// 富文本等到同一次复制的 Clipboard 文本到达后一起写入
+func (manager *Manager) receiveClipboardChunk(requester *session.Session, msg message.Message) {
+	syncer := manager.desktop.ClipboardSync()
+	if syncer == nil || !requester.Can(session.PermissionClipboard) {
+		return
+	}
+
+	limit := syncer.Limits().Rich
+	if msg.Event == message.ClipboardImage {
+		limit = syncer.Limits().Image
+	}
+
+	manager.clipboard.mu.Lock()
+	peer := manager.clipboard.peer(requester.ID())
+	assembler, ok := peer.assemblers[msg.Event]
+	if !ok {
+		assembler = message.NewAssembler(limit)
+		peer.assemblers[msg.Event] = assembler
+	}
+	data, err := assembler.Add(msg)
+	if err == nil && data != nil {
+		switch msg.Event {
+		case message.ClipboardHTML:
+			peer.rich.HTML = string(data)
+		case message.ClipboardRTF:
+			peer.rich.RTF = string(data)
+		}
+	}
+	manager.clipboard.mu.Unlock()
+
+	if err != nil {
+		manager.logger.Debug("Clipboard transfer dropped", "session_id", requester.ID(), "event", msg.Event, "error", err)
+		return
+	}
+	if data != nil && msg.Event == message.ClipboardImage {
+		manager.applyClipboard(requester, clipboard.Content{Image: data})
+	}
+}
+
+// receiveClipboardText 把会话发来的文本和 p1 指明的富文本一起写入主机剪切板，
+// 之前收到但不属于这段文本的富文本被丢弃
+func (manager *Manager) receiveClipboardText(requester *session.Session, msg message.Message) {
+	content := clipboard.Content{Text: msg.P4}
+
+	manager.clipboard.mu.Lock()
+	peer := manager.clipboard.peer(requester.ID())
+	if msg.P1&message.ClipboardFormatHTML != 0 {
+		content.HTML = peer.rich.HTML
+		content.FragmentStart, content.FragmentEnd = int(msg.P2), int(msg.P3)
+	}
+	if msg.P1&message.ClipboardFormatRTF != 0 {
+		content.RTF = peer.rich.RTF
+	}
+	peer.rich = clipboard.Content{}
+	manager.clipboard.mu.Unlock()
+
+	manager.applyClipboard(requester, content)
+}
+
+// removeClipboardPeer 丢弃会话的格式和还没有接收完的内容
+func (manager *Manager) removeClipboardPeer(sessionID string) {
+	manager.clipboard.mu.Lock()
+	defer manager.clipboard.mu.Unlock()
+
+	delete(manager.clipboard.peers, sessionID)
+}
+
+// applyClipboard 把会话发来的剪切板内容写入主机剪切板
+func (manager *Manager) applyClipboard(requester *session.Session, content clipboard.Content) {
+	syncer := manager.desktop.ClipboardSync()
+	if syncer == nil {
+		return
+	}
+	if !requester.Can(session.PermissionClipboard) {
+		manager.logger.Debug("Clipboard update denied", "session_id", requester.ID(), "permissions", requester.Permissions())
+		return
+	}
+
+	if err := syncer.Apply(requester.ID(), content); err != nil {
+		if errors.Is(err, clipboard.ErrTooLarge) || errors.Is(err, clipboard.ErrInvalidPNG) {
+			manager.logger.Debug("Clipboard update dropped", "session_id", requester.ID(), "error", err)
+			return
+		}
+		manager.logger.Warn("Failed to write clipboard", "session_id", requester.ID(), "error", err)
+	}
+}
+
+func (manager *Manager) canClipboard(sessionID string) bool {
+	peer, ok := manager.sessions.Get(sessionID)
+	return ok && peer.Can(session.PermissionClipboard)
+}
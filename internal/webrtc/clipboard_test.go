@@ -0,0 +1,36 @@
+package webrtc
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/clipboard"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+func TestClipboardMessagesHTML(t *testing.T) {
+	manager := newTestManager()
+
+	document := "<html><head><style>td{font-weight:700}</style></head><body><table><!--StartFragment--><tr><td>a</td></tr><!--EndFragment--></table></body></html>"
+	content := clipboard.Content{Text: "a", HTML: document, FragmentStart: 86, FragmentEnd: 105, RTF: `{\rtf1 a}`}
+
+	msgs := manager.clipboardMessages(content, message.ClipboardFormatHTML)
+	if len(msgs) != 2 || msgs[0].Event != message.ClipboardHTML || msgs[1].Event != message.Clipboard {
+		t.Fatalf("messages = %+v", msgs)
+	}
+
+	// 发送完整的文档，片段的范围跟着文本一起发送
+	if data, _ := base64.StdEncoding.DecodeString(msgs[0].P4); string(data) != document {
+		t.Errorf("HTML = %q, want the whole document", data)
+	}
+	text := msgs[1]
+	if text.P1 != message.ClipboardFormatHTML || text.P4 != "a" || document[text.P2:text.P3] != "<tr><td>a</td></tr>" {
+		t.Errorf("text message = %+v", text)
+	}
+
+	// 只发送 RTF 时不带片段的范围
+	msgs = manager.clipboardMessages(content, message.ClipboardFormatRTF)
+	if text := msgs[len(msgs)-1]; text.P2 != 0 || text.P3 != 0 {
+		t.Errorf("RTF text message = %+v", text)
+	}
+}
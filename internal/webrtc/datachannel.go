@@ -0,0 +1,331 @@
+package webrtc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/m4n5ter/lindows/internal/desktop"
+	"github.com/m4n5ter/lindows/internal/desktop/command"
+	"github.com/m4n5ter/lindows/internal/desktop/cursor"
+	"github.com/m4n5ter/lindows/internal/desktop/macro"
+	"github.com/m4n5ter/lindows/internal/session"
+	"github.com/m4n5ter/lindows/internal/types/message"
+	"github.com/pion/webrtc/v4"
+)
+
+// 数据通道的标签，与 lindows-client 保持一致
+const (
+	labelKey    = "key"
+	labelMouse  = "mouse"
+	labelCommon = "common"
+)
+
+// channels 记录每个会话已经打开的 common 通道，用于向客户端广播
+type channels struct {
+	mu     sync.Mutex
+	common map[string]*webrtc.DataChannel
+}
+
+// macroPlaybacks 记录通过 common 通道发起的宏回放属于哪个会话
+type macroPlaybacks struct {
+	mu      sync.Mutex
+	running map[*macroPlayback]struct{}
+}
+
+type macroPlayback struct {
+	sessionID string
+	cancel    context.CancelFunc
+}
+
+// macroContext 返回会话发起的回放使用的 context，回放结束后需要调用返回的函数
+func (manager *Manager) macroContext(sessionID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	playback := &macroPlayback{sessionID: sessionID, cancel: cancel}
+
+	manager.macros.mu.Lock()
+	manager.macros.running[playback] = struct{}{}
+	manager.macros.mu.Unlock()
+
+	return ctx, func() {
+		manager.macros.mu.Lock()
+		delete(manager.macros.running, playback)
+		manager.macros.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancelMacros 停止 match 返回 true 的会话发起的回放
+func (manager *Manager) cancelMacros(match func(sessionID string) bool) {
+	manager.macros.mu.Lock()
+	defer manager.macros.mu.Unlock()
+
+	for playback := range manager.macros.running {
+		if match(playback.sessionID) {
+			manager.logger.Info("Cancelling macro playback", "session_id", playback.sessionID)
+			playback.cancel()
+		}
+	}
+}
+
+func (manager *Manager) handleDataChannel(session *session.Session, dc *webrtc.DataChannel) {
+	logger := manager.logger.With("session_id", session.ID(), "label", dc.Label())
+
+	switch dc.Label() {
+	case labelCommon:
+		dc.OnOpen(func() {
+			manager.channels.mu.Lock()
+			manager.channels.common[session.ID()] = dc
+			manager.channels.mu.Unlock()
+
+			manager.sendCursor(dc)
+			manager.sendClipboard(session, dc)
+		})
+		dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+			manager.handleCommon(session, dc, msg.Data)
+		})
+		dc.OnClose(func() {
+			manager.removeCommon(session.ID(), dc)
+		})
+
+	case labelKey, labelMouse:
+		dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+			manager.handleInput(session, msg.Data)
+		})
+
+	default:
+		logger.Debug("Unhandled data channel")
+	}
+}
+
+// handleInput 注入 key 和 mouse 通道的消息，只有拥有控制权的会话可以操作
+func (manager *Manager) handleInput(session *session.Session, data []byte) {
+	if !session.IsController() {
+		return
+	}
+
+	msg, err := message.Decode(data)
+	if err != nil {
+		manager.logger.Debug("Invalid input message", "session_id", session.ID(), "error", err)
+		return
+	}
+
+	manager.desktop.DispatchInput(session.ID(), msg)
+
+	if msg.Event == message.PointerLock {
+		manager.pointerLockChanged(session.ID(), msg.P1 != 0)
+	}
+}
+
+// handleCommon 处理客户端在 common 通道发送的消息
+func (manager *Manager) handleCommon(session *session.Session, dc *webrtc.DataChannel, data []byte) {
+	msg, err := message.Decode(data)
+	if err != nil {
+		manager.logger.Debug("Invalid common message", "session_id", session.ID(), "error", err)
+		return
+	}
+
+	switch msg.Event {
+	case message.SystemCommand:
+		manager.systemCommand(session, dc, msg.P4)
+	case message.Macro:
+		manager.macro(session, dc, msg)
+	case message.Clipboard:
+		manager.receiveClipboardText(session, msg)
+	case message.ClipboardImage, message.ClipboardHTML, message.ClipboardRTF:
+		manager.receiveClipboardChunk(session, msg)
+	case message.ClipboardFormats:
+		manager.setClipboardFormats(session, dc, msg.P1)
+	default:
+		manager.logger.Debug("Unhandled common event", "session_id", session.ID(), "event", msg.Event)
+	}
+}
+
+// systemCommand 执行系统命令并把结果回复给请求的会话
+func (manager *Manager) systemCommand(session *session.Session, dc *webrtc.DataChannel, name string) {
+	result := message.CommandOK
+
+	if err := manager.desktop.RunCommand(session, name); err != nil {
+		switch {
+		case errors.Is(err, desktop.ErrPermissionDenied):
+			result = message.CommandDenied
+		case errors.Is(err, command.ErrUnknown), errors.Is(err, command.ErrUnsupported):
+			result = message.CommandUnsupported
+		default:
+			result = message.CommandFailed
+		}
+		manager.logger.Warn("System command failed", "session_id", session.ID(), "command", name, "error", err)
+	}
+
+	_ = dc.Send(message.Encode(message.Message{Event: message.SystemCommand, P1: result, P4: name}))
+}
+
+// macro 录制或回放宏并把结果回复给请求的会话，回放在后台进行，结束后才回复
+func (manager *Manager) macro(session *session.Session, dc *webrtc.DataChannel, msg message.Message) {
+	reply := func(err error) {
+		result := message.CommandOK
+		if err != nil {
+			switch {
+			case errors.Is(err, desktop.ErrPermissionDenied):
+				result = message.CommandDenied
+			case errors.Is(err, macro.ErrNotFound):
+				result = message.CommandUnsupported
+			default:
+				result = message.CommandFailed
+			}
+			manager.logger.Warn("Macro failed", "session_id", session.ID(), "action", msg.P1, "name", msg.P4, "error", err)
+		}
+
+		_ = dc.Send(message.Encode(message.Message{Event: message.Macro, P1: msg.P1, P3: result, P4: msg.P4}))
+	}
+
+	if err := manager.desktop.AuthorizeMacro(session); err != nil {
+		reply(err)
+		return
+	}
+
+	switch msg.P1 {
+	case message.MacroRecord:
+		reply(manager.desktop.StartRecording())
+	case message.MacroStop:
+		_, err := manager.desktop.StopRecording(msg.P4)
+		reply(err)
+	case message.MacroPlay:
+		recorded, err := manager.desktop.Macro(msg.P4)
+		if err != nil {
+			reply(err)
+			return
+		}
+
+		speed := 1.0
+		if msg.P2 > 0 {
+			speed = float64(msg.P2) / 100
+		}
+		// 回放跟随会话，会话关闭或者失去控制权时停止
+		ctx, done := manager.macroContext(session.ID())
+		go func() {
+			defer done()
+			reply(manager.desktop.PlayMacro(ctx, recorded, speed))
+		}()
+	case message.MacroCancel:
+		reply(manager.desktop.CancelMacro())
+	default:
+		reply(fmt.Errorf("%w: unknown macro action %d", message.ErrInvalid, msg.P1))
+	}
+}
+
+// pointerLockChanged 在相对指针模式下隐藏该会话的远程光标，退出时立即恢复
+func (manager *Manager) pointerLockChanged(sessionID string, locked bool) {
+	watcher := manager.desktop.Cursor()
+	if watcher == nil {
+		return
+	}
+
+	manager.channels.mu.Lock()
+	dc, ok := manager.channels.common[sessionID]
+	manager.channels.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	position := watcher.Position()
+	if locked {
+		position.Visible = false
+	}
+	_ = dc.Send(message.Encode(cursorPositionMessage(position)))
+}
+
+func (manager *Manager) removeCommon(sessionID string, dc *webrtc.DataChannel) {
+	manager.channels.mu.Lock()
+	defer manager.channels.mu.Unlock()
+
+	if current, ok := manager.channels.common[sessionID]; ok && (dc == nil || current == dc) {
+		delete(manager.channels.common, sessionID)
+		manager.removeClipboardPeer(sessionID)
+	}
+}
+
+// broadcast 向所有会话的 common 通道发送消息，skip 不为 nil 时跳过它返回 true 的会话
+func (manager *Manager) broadcast(msg message.Message, skip func(sessionID string) bool) {
+	data := message.Encode(msg)
+
+	manager.channels.mu.Lock()
+	defer manager.channels.mu.Unlock()
+
+	for sessionID, dc := range manager.channels.common {
+		if skip != nil && skip(sessionID) {
+			continue
+		}
+		if err := dc.Send(data); err != nil {
+			manager.logger.Debug("Failed to send message", "session_id", sessionID, "event", msg.Event, "error", err)
+		}
+	}
+}
+
+// watchCursor 在光标形状或位置变化时广播给所有客户端
+func (manager *Manager) watchCursor() {
+	watcher := manager.desktop.Cursor()
+	if watcher == nil {
+		return
+	}
+
+	// 形状照常发送，客户端退出相对指针模式时就能直接使用最新的形状
+	watcher.OnShape(func(shape cursor.Shape) {
+		for _, msg := range cursorShapeMessages(shape) {
+			manager.broadcast(msg, nil)
+		}
+	})
+	watcher.OnPosition(func(position cursor.Position) {
+		manager.broadcast(cursorPositionMessage(position), manager.desktop.PointerLocked)
+	})
+}
+
+// sendCursor 把当前的光标发送给新打开的通道
+func (manager *Manager) sendCursor(dc *webrtc.DataChannel) {
+	watcher := manager.desktop.Cursor()
+	if watcher == nil {
+		return
+	}
+
+	if shape, ok := watcher.Shape(); ok {
+		for _, msg := range cursorShapeMessages(shape) {
+			_ = dc.Send(message.Encode(msg))
+		}
+	}
+	_ = dc.Send(message.Encode(cursorPositionMessage(watcher.Position())))
+}
+
+// cursorShapeMessages 把光标形状编码为消息。高 DPI 下的大光标可能超过数据通道单条消息的限制，
+// 这时 PNG 按 ChunkSize 分块放在 CursorImage 中，CursorShape 只带热点和序号
+func cursorShapeMessages(shape cursor.Shape) []message.Message {
+	msg := message.Message{
+		Event: message.CursorShape,
+		P1:    int32(shape.Hotspot.X),
+		P2:    int32(shape.Hotspot.Y),
+		P3:    int32(shape.Serial),
+	}
+	if len(shape.PNG) <= message.ChunkSize {
+		msg.P4 = base64.StdEncoding.EncodeToString(shape.PNG)
+		return []message.Message{msg}
+	}
+
+	return append(message.Chunks(message.CursorImage, msg.P3, shape.PNG), msg)
+}
+
+func cursorPositionMessage(position cursor.Position) message.Message {
+	x, y := position.Ratio(message.Ratio)
+
+	var visible int32
+	if position.Visible {
+		visible = 1
+	}
+
+	return message.Message{
+		Event: message.CursorPosition,
+		P1:    int32(x),
+		P2:    int32(y),
+		P3:    visible,
+	}
+}
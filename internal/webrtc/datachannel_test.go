@@ -0,0 +1,84 @@
+package webrtc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"testing"
+
+	"github.com/m4n5ter/lindows/internal/desktop/cursor"
+	"github.com/m4n5ter/lindows/internal/types/message"
+)
+
+func TestCancelMacros(t *testing.T) {
+	manager := newTestManager()
+
+	a, doneA := manager.macroContext("a")
+	b, doneB := manager.macroContext("b")
+	defer doneB()
+
+	// 控制权交给 b 时停止其它会话的回放
+	manager.cancelMacros(func(sessionID string) bool { return sessionID != "b" })
+	if a.Err() != context.Canceled {
+		t.Error("playback of a was not cancelled")
+	}
+	if b.Err() != nil {
+		t.Error("playback of the controller was cancelled")
+	}
+
+	// 结束的回放不再被记录
+	doneA()
+	manager.macros.mu.Lock()
+	running := len(manager.macros.running)
+	manager.macros.mu.Unlock()
+	if running != 1 {
+		t.Errorf("running = %d, want 1", running)
+	}
+
+	// b 关闭时停止它的回放
+	manager.cancelMacros(func(sessionID string) bool { return sessionID == "b" })
+	if b.Err() != context.Canceled {
+		t.Error("playback of b was not cancelled")
+	}
+}
+
+func TestCursorShapeMessages(t *testing.T) {
+	// 小的光标直接放在 CursorShape 中
+	small := cursor.Shape{PNG: []byte("png"), Hotspot: image.Pt(3, 4), Serial: 7}
+	msgs := cursorShapeMessages(small)
+	if len(msgs) != 1 || msgs[0] != (message.Message{Event: message.CursorShape, P1: 3, P2: 4, P3: 7, P4: base64.StdEncoding.EncodeToString(small.PNG)}) {
+		t.Errorf("small shape = %+v", msgs)
+	}
+
+	// 大的光标分块发送，每条消息都不超过数据通道的限制
+	large := cursor.Shape{PNG: bytes.Repeat([]byte{0xAB}, 2*message.ChunkSize+1), Hotspot: image.Pt(128, 128), Serial: 8}
+	msgs = cursorShapeMessages(large)
+	if len(msgs) != 4 {
+		t.Fatalf("large shape is sent in %d messages, want 4", len(msgs))
+	}
+
+	assembler := message.NewAssembler(len(large.PNG))
+	var png []byte
+	for _, msg := range msgs[:3] {
+		if msg.Event != message.CursorImage || msg.P1 != 8 {
+			t.Errorf("chunk = %+v", msg)
+		}
+		if size := len(message.Encode(msg)); size > 64*1024 {
+			t.Errorf("chunk %d is %d bytes", msg.P2, size)
+		}
+		data, err := assembler.Add(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data != nil {
+			png = data
+		}
+	}
+	if !bytes.Equal(png, large.PNG) {
+		t.Error("assembled PNG differs from the shape")
+	}
+	if last := msgs[3]; last != (message.Message{Event: message.CursorShape, P1: 128, P2: 128, P3: 8}) {
+		t.Errorf("shape message = %+v", last)
+	}
+}
@@ -0,0 +1,95 @@
+package webrtc
+
+import (
+	"strings"
+
+	"github.com/m4n5ter/lindows/internal/session"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+	"github.com/pion/webrtc/v4"
+)
+
+// newPeerConnection 为会话创建 PeerConnection，并添加共享的音视频轨道
+func (manager *Manager) newPeerConnection(session *session.Session, signal *signalConn) (*webrtc.PeerConnection, error) {
+	logger := manager.logger.With("session_id", session.ID())
+
+	peer, err := webrtc.NewPeerConnection(webrtc.Configuration{
+		ICEServers: manager.config.ICEServers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, track := range []*webrtc.TrackLocalStaticRTP{manager.videoTrack, manager.audioTrack} {
+		if track == nil {
+			continue
+		}
+
+		sender, err := peer.AddTrack(track)
+		if err != nil {
+			_ = peer.Close()
+			return nil, err
+		}
+
+		// 读取 RTCP 包，否则 interceptor 不会工作
+		go func() {
+			buf := make([]byte, 1500)
+			for {
+				if _, _, err := sender.Read(buf); err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	peer.OnTrack(func(track *webrtc.TrackRemote, _ *webrtc.RTPReceiver) {
+		if track.Kind() != webrtc.RTPCodecTypeAudio {
+			return
+		}
+
+		if !manager.playback.Enabled() || !strings.EqualFold(track.Codec().MimeType, webrtc.MimeTypeOpus) {
+			logger.Debug("Ignoring inbound audio track", "codec", track.Codec().MimeType)
+			return
+		}
+
+		manager.playTrack(session, track)
+	})
+
+	peer.OnDataChannel(func(dc *webrtc.DataChannel) {
+		manager.handleDataChannel(session, dc)
+	})
+
+	peer.OnICECandidate(func(candidate *webrtc.ICECandidate) {
+		if candidate == nil {
+			return
+		}
+
+		if err := signal.send(eventCandidate, candidate.ToJSON().Candidate); err != nil {
+			logger.Error("Failed to send candidate", "error", err)
+		}
+	})
+
+	peer.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		logger.Debug("Peer connection state changed", "state", state)
+
+		switch state {
+		case webrtc.PeerConnectionStateFailed:
+			if manager.fallback != "" {
+				logger.Info("Peer connection failed, advertising fallback", "url", manager.fallback)
+				if err := signal.send(eventFallback, manager.fallback); err != nil {
+					logger.Error("Failed to send fallback", "error", err)
+				}
+			}
+			signal.close()
+		case webrtc.PeerConnectionStateClosed:
+			signal.close()
+		}
+	})
+
+	return peer, nil
+}
+
+func closePeer(peer *webrtc.PeerConnection, logger *yalog.Logger) {
+	if err := peer.Close(); err != nil {
+		logger.Error("Failed to close peer connection", "error", err)
+	}
+}
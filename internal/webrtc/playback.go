@@ -0,0 +1,39 @@
+package webrtc
+
+import (
+	"context"
+
+	"github.com/m4n5ter/lindows/internal/session"
+	"github.com/m4n5ter/lindows/internal/types/codec"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+)
+
+// playTrack 把观众的麦克风音频交给 playback 播放，直到轨道结束
+func (manager *Manager) playTrack(session *session.Session, track *webrtc.TrackRemote) {
+	logger := manager.logger.With("session_id", session.ID())
+	logger.Info("Receiving microphone audio", "ssrc", track.SSRC())
+
+	packets := make(chan rtp.Packet, 64)
+	go func() {
+		defer close(packets)
+
+		// 客户端协商的 payload type 可能不同，统一为 playback 解码时使用的值
+		payloadType := codec.Opus().PayloadType
+		for {
+			packet, _, err := track.ReadRTP()
+			if err != nil {
+				return
+			}
+
+			packet.PayloadType = uint8(payloadType)
+			select {
+			case packets <- *packet:
+			default:
+			}
+		}
+	}()
+
+	err := manager.playback.Play(context.Background(), session.ID(), packets, session.IsController)
+	logger.Debug("Microphone audio ended", "error", err)
+}
@@ -0,0 +1,201 @@
+package webrtc
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/pion/webrtc/v4"
+	"golang.org/x/net/websocket"
+)
+
+// 信令事件，与 lindows-client 的 WSMessage 保持一致
+const (
+	eventOffer     = "offer"
+	eventAnswer    = "answer"
+	eventCandidate = "candidate"
+	eventPing      = "ping"
+	eventPong      = "pong"
+	// eventMute 由客户端发送 "true"/"false" 静音自己的麦克风；服务端以 JSON 推送当前的静音状态，
+	// 连接时、客户端请求后以及主机端通过 /playback 改变静音时都会推送
+	eventMute = "mute"
+	// eventFallback 在 ICE 失败时发送，payload 为只读的 HLS 播放地址
+	eventFallback = "fallback"
+)
+
+type signalMessage struct {
+	Event   string `json:"event"`
+	Payload string `json:"payload"`
+}
+
+// signalConn 是一个可以并发写入的信令连接
+type signalConn struct {
+	ws        *websocket.Conn
+	mu        sync.Mutex
+	closeOnce sync.Once
+}
+
+func (conn *signalConn) send(event, payload string) error {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	return websocket.JSON.Send(conn.ws, signalMessage{Event: event, Payload: payload})
+}
+
+// signals 保存每个会话的信令连接，用于主动推送事件
+type signals struct {
+	mu    sync.Mutex
+	conns map[string]*signalConn
+}
+
+func (conn *signalConn) close() {
+	conn.closeOnce.Do(func() {
+		_ = conn.ws.Close()
+	})
+}
+
+// SignalHandler 返回 WebSocket 信令处理器，每个连接对应一个会话。
+//
+// 客户端可以通过 `?user=<name>` 声明用户名。
+func (manager *Manager) SignalHandler() http.Handler {
+	return websocket.Server{
+		// 不检查 Origin，认证由外层的 HTTP 中间件负责
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler:   manager.handleSignal,
+	}
+}
+
+func (manager *Manager) handleSignal(ws *websocket.Conn) {
+	request := ws.Request()
+	signal := &signalConn{ws: ws}
+	defer signal.close()
+
+	session := manager.sessions.Create(request.RemoteAddr, request.URL.Query().Get("user"))
+	defer manager.sessions.Destroy(session.ID())
+	defer manager.removeCommon(session.ID(), nil)
+
+	manager.signals.mu.Lock()
+	manager.signals.conns[session.ID()] = signal
+	manager.signals.mu.Unlock()
+	defer func() {
+		manager.signals.mu.Lock()
+		delete(manager.signals.conns, session.ID())
+		manager.signals.mu.Unlock()
+	}()
+
+	logger := manager.logger.With("session_id", session.ID())
+
+	if manager.playback.Enabled() {
+		if err := manager.sendMuteState(signal, session.ID()); err != nil {
+			logger.Debug("Failed to send mute state", "error", err)
+			return
+		}
+	}
+
+	peer, err := manager.newPeerConnection(session, signal)
+	if err != nil {
+		logger.Error("Failed to create peer connection", "error", err)
+		return
+	}
+	defer closePeer(peer, logger)
+
+	for {
+		var msg signalMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			logger.Debug("Signal connection closed", "error", err)
+			return
+		}
+
+		switch msg.Event {
+		case eventOffer:
+			answer, err := manager.answer(peer, msg.Payload)
+			if err != nil {
+				logger.Error("Failed to answer offer", "error", err)
+				return
+			}
+
+			if err := signal.send(eventAnswer, answer); err != nil {
+				logger.Error("Failed to send answer", "error", err)
+				return
+			}
+
+		case eventCandidate:
+			sdpMid, sdpMLineIndex := "", uint16(0)
+			err := peer.AddICECandidate(webrtc.ICECandidateInit{
+				Candidate:     msg.Payload,
+				SDPMid:        &sdpMid,
+				SDPMLineIndex: &sdpMLineIndex,
+			})
+			if err != nil {
+				logger.Warn("Failed to add ICE candidate", "error", err)
+			}
+
+		case eventMute:
+			muted, err := strconv.ParseBool(msg.Payload)
+			if err != nil {
+				logger.Warn("Invalid mute payload", "payload", msg.Payload)
+				continue
+			}
+
+			// 新的状态由 pushMuteState 推送
+			manager.playback.SetClientMuted(session.ID(), muted)
+
+		case eventPing:
+			if err := signal.send(eventPong, ""); err != nil {
+				return
+			}
+
+		default:
+			logger.Warn("Unknown signal event", "event", msg.Event)
+		}
+	}
+}
+
+func (manager *Manager) answer(peer *webrtc.PeerConnection, sdp string) (string, error) {
+	err := peer.SetRemoteDescription(webrtc.SessionDescription{
+		Type: webrtc.SDPTypeOffer,
+		SDP:  sdp,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	answer, err := peer.CreateAnswer(nil)
+	if err != nil {
+		return "", err
+	}
+
+	if err := peer.SetLocalDescription(answer); err != nil {
+		return "", err
+	}
+
+	return answer.SDP, nil
+}
+
+// pushMuteState 把静音状态推送给会话的信令连接，sessionID 为空时推送给所有会话
+func (manager *Manager) pushMuteState(sessionID string) {
+	manager.signals.mu.Lock()
+	conns := make(map[string]*signalConn)
+	for id, conn := range manager.signals.conns {
+		if sessionID == "" || id == sessionID {
+			conns[id] = conn
+		}
+	}
+	manager.signals.mu.Unlock()
+
+	for id, conn := range conns {
+		if err := manager.sendMuteState(conn, id); err != nil {
+			manager.logger.Debug("Failed to send mute state", "session_id", id, "error", err)
+		}
+	}
+}
+
+func (manager *Manager) sendMuteState(signal *signalConn, sessionID string) error {
+	state, err := json.Marshal(manager.playback.State(sessionID))
+	if err != nil {
+		return err
+	}
+
+	return signal.send(eventMute, string(state))
+}
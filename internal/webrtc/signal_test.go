@@ -0,0 +1,193 @@
+package webrtc
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/playback"
+	"github.com/m4n5ter/lindows/internal/session"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+	"github.com/pion/webrtc/v4"
+	"golang.org/x/net/websocket"
+)
+
+func newTestManager() *Manager {
+	return &Manager{
+		logger:    yalog.Default().With("module", "webrtc"),
+		sessions:  session.New(&config.Session{}),
+		config:    &config.WebRTC{},
+		playback:  playback.New(&config.Playback{}),
+		channels:  channels{common: make(map[string]*webrtc.DataChannel)},
+		signals:   signals{conns: make(map[string]*signalConn)},
+		clipboard: clipboardTransfers{peers: make(map[string]*clipboardPeer)},
+		macros:    macroPlaybacks{running: make(map[*macroPlayback]struct{})},
+	}
+}
+
+// waitFor 等待 condition 成立，会话的创建和销毁发生在服务端的协程中
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSignalSession(t *testing.T) {
+	manager := newTestManager()
+	server := httptest.NewServer(manager.SignalHandler())
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?user=alice"
+	ws, err := websocket.Dial(url, "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	// 每个信令连接对应一个会话，第一个会话获得控制权
+	waitFor(t, "session", func() bool { return len(manager.sessions.List()) == 1 })
+	created := manager.sessions.List()[0]
+	if created.User() != "alice" || !created.IsController() {
+		t.Errorf("session user = %q, controller = %t", created.User(), created.IsController())
+	}
+
+	receive := func(event string) signalMessage {
+		t.Helper()
+		for {
+			var msg signalMessage
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				t.Fatalf("receive %s: %v", event, err)
+			}
+			// 候选可能在任何时候到达
+			if msg.Event == event {
+				return msg
+			}
+		}
+	}
+
+	if err := websocket.JSON.Send(ws, signalMessage{Event: eventPing}); err != nil {
+		t.Fatal(err)
+	}
+	receive(eventPong)
+
+	client, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err := client.CreateDataChannel(labelCommon, nil); err != nil {
+		t.Fatal(err)
+	}
+	offer, err := client.CreateOffer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetLocalDescription(offer); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := websocket.JSON.Send(ws, signalMessage{Event: eventOffer, Payload: offer.SDP}); err != nil {
+		t.Fatal(err)
+	}
+	answer := receive(eventAnswer)
+	if err := client.SetRemoteDescription(webrtc.SessionDescription{Type: webrtc.SDPTypeAnswer, SDP: answer.Payload}); err != nil {
+		t.Errorf("answer is not a valid SDP: %v", err)
+	}
+
+	// 断开信令连接时销毁会话
+	_ = ws.Close()
+	waitFor(t, "session destroyed", func() bool { return len(manager.sessions.List()) == 0 })
+}
+
+func TestSignalInvalidOffer(t *testing.T) {
+	manager := newTestManager()
+	server := httptest.NewServer(manager.SignalHandler())
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	// 无法应答的 offer 会关闭连接并销毁会话
+	if err := websocket.JSON.Send(ws, signalMessage{Event: eventOffer, Payload: "not sdp"}); err != nil {
+		t.Fatal(err)
+	}
+	var msg signalMessage
+	for websocket.JSON.Receive(ws, &msg) == nil {
+	}
+	waitFor(t, "session destroyed", func() bool { return len(manager.sessions.List()) == 0 })
+}
+
+func TestSignalMutePush(t *testing.T) {
+	manager := newTestManager()
+	manager.playback.OnMuteChanged(manager.pushMuteState)
+	server := httptest.NewServer(manager.SignalHandler())
+	defer server.Close()
+
+	dial := func() *websocket.Conn {
+		t.Helper()
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { ws.Close() })
+		return ws
+	}
+	receiveMute := func(ws *websocket.Conn) playback.MuteState {
+		t.Helper()
+		_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			var msg signalMessage
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				t.Fatalf("receive mute: %v", err)
+			}
+			if msg.Event != eventMute {
+				continue
+			}
+			var state playback.MuteState
+			if err := json.Unmarshal([]byte(msg.Payload), &state); err != nil {
+				t.Fatal(err)
+			}
+			return state
+		}
+	}
+
+	a, b := dial(), dial()
+	waitFor(t, "sessions", func() bool {
+		manager.signals.mu.Lock()
+		defer manager.signals.mu.Unlock()
+		return len(manager.signals.conns) == 2
+	})
+
+	// 主机端通过 /playback 静音时推送给所有会话
+	manager.playback.SetHostMuted(true)
+	for _, ws := range []*websocket.Conn{a, b} {
+		if state := receiveMute(ws); !state.Host || state.Client {
+			t.Errorf("pushed state = %+v, want host muted", state)
+		}
+	}
+
+	// 观众静音自己时只推送给它自己
+	if err := websocket.JSON.Send(a, signalMessage{Event: eventMute, Payload: "true"}); err != nil {
+		t.Fatal(err)
+	}
+	if state := receiveMute(a); !state.Host || !state.Client {
+		t.Errorf("state after client mute = %+v", state)
+	}
+
+	manager.playback.SetHostMuted(false)
+	if state := receiveMute(b); state.Host || state.Client {
+		t.Errorf("b state = %+v, want unmuted", state)
+	}
+}
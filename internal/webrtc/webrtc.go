@@ -3,10 +3,15 @@ package webrtc
 import (
 	"errors"
 	"io"
+	"sync"
 
 	"github.com/m4n5ter/lindows/internal/capture"
 	"github.com/m4n5ter/lindows/internal/config"
+	"github.com/m4n5ter/lindows/internal/desktop"
+	"github.com/m4n5ter/lindows/internal/playback"
+	"github.com/m4n5ter/lindows/internal/session"
 	"github.com/m4n5ter/lindows/pkg/yalog"
+	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v4"
 )
 
@@ -14,16 +19,44 @@ type Manager struct {
 	logger     *yalog.Logger
 	videoTrack *webrtc.TrackLocalStaticRTP
 	audioTrack *webrtc.TrackLocalStaticRTP
-	capture    capture.Manager
+	capture    *capture.Manager
+	desktop    *desktop.Manager
+	playback   *playback.Manager
+	sessions   *session.Manager
 	config     *config.WebRTC
+	fallback   string
+	channels   channels
+	signals    signals
+	clipboard  clipboardTransfers
+	macros     macroPlaybacks
+
+	streamsMu   sync.Mutex
+	streamUsers int
+	unsubscribe []func()
 }
 
-func New(cfg *config.WebRTC) *Manager {
+func New(sessions *session.Manager, desktop *desktop.Manager, capture *capture.Manager, playback *playback.Manager, cfg *config.WebRTC) *Manager {
 	return &Manager{
-		logger: yalog.Default().With("module", "webrtc"),
+		logger:   yalog.Default().With("module", "webrtc"),
+		capture:  capture,
+		desktop:  desktop,
+		playback: playback,
+		sessions: sessions,
+		config:   cfg,
+		channels: channels{common: make(map[string]*webrtc.DataChannel)},
+		signals:  signals{conns: make(map[string]*signalConn)},
+		clipboard: clipboardTransfers{
+			peers: make(map[string]*clipboardPeer),
+		},
+		macros: macroPlaybacks{running: make(map[*macroPlayback]struct{})},
 	}
 }
 
+// SetFallback 设置 WebRTC 连接失败时通过信令告知客户端的回退播放地址
+func (manager *Manager) SetFallback(url string) {
+	manager.fallback = url
+}
+
 func (manager *Manager) Start() {
 	var err error
 
@@ -34,21 +67,6 @@ func (manager *Manager) Start() {
 		manager.logger.Fatal("Failed to create video track", "error", err)
 	}
 
-	go func() {
-		for {
-			packet, ok := <-manager.capture.Video().GetRTPChannel()
-			if !ok {
-				manager.logger.Debug("Video sample channel closed")
-				continue
-			}
-
-			if err := manager.videoTrack.WriteRTP(&packet); err != nil && errors.Is(err, io.ErrClosedPipe) {
-				manager.logger.Error("Video track closed", "error", err)
-			}
-
-		}
-	}()
-
 	// Audio
 	audioCodec := manager.capture.Audio().Codec()
 	manager.audioTrack, err = webrtc.NewTrackLocalStaticRTP(audioCodec.Capability, "audio", "stream")
@@ -56,21 +74,75 @@ func (manager *Manager) Start() {
 		manager.logger.Fatal("Failed to create audio track", "error", err)
 	}
 
-	go func() {
-		for {
-			packet, ok := <-manager.capture.Audio().GetRTPChannel()
-			if !ok {
-				manager.logger.Debug("Audio sample channel closed")
-				continue
-			}
-
-			if err := manager.audioTrack.WriteRTP(&packet); err != nil && errors.Is(err, io.ErrClosedPipe) {
-				manager.logger.Error("Audio track closed", "error", err)
-			}
+	// 只在有会话时订阅采集流，这样没有观众时采集管道可以停止
+	manager.sessions.OnCreated(func(*session.Session) { manager.acquireStreams() })
+	manager.sessions.OnDestroyed(func(*session.Session) { manager.releaseStreams() })
+
+	// 会话关闭或者失去控制权时释放它按住的按键，避免被控端卡在按下状态，同时停止它发起的宏回放
+	manager.sessions.OnDestroyed(func(session *session.Session) {
+		manager.desktop.ReleaseInput(session.ID())
+		manager.cancelMacros(func(sessionID string) bool { return sessionID == session.ID() })
+	})
+	manager.sessions.OnControllerChanged(func(controller *session.Session) {
+		var id string
+		if controller != nil {
+			id = controller.ID()
 		}
-	}()
+		manager.desktop.ReleaseInputExcept(id)
+		manager.cancelMacros(func(sessionID string) bool { return sessionID != id })
+	})
+
+	manager.playback.OnMuteChanged(manager.pushMuteState)
+
+	manager.watchCursor()
+	manager.watchClipboard()
 
 	manager.logger.Info("WebRTC manager started",
 		"ice_servers", manager.config.ICEServers,
 	)
 }
+
+// acquireStreams 在第一个会话创建时订阅音视频流
+func (manager *Manager) acquireStreams() {
+	manager.streamsMu.Lock()
+	defer manager.streamsMu.Unlock()
+
+	manager.streamUsers++
+	if manager.streamUsers > 1 {
+		return
+	}
+
+	videoPackets, unsubscribeVideo := manager.capture.Video().Subscribe()
+	go manager.writeTrack(manager.videoTrack, videoPackets, "video")
+
+	audioPackets, unsubscribeAudio := manager.capture.Audio().Subscribe()
+	go manager.writeTrack(manager.audioTrack, audioPackets, "audio")
+
+	manager.unsubscribe = []func(){unsubscribeVideo, unsubscribeAudio}
+}
+
+// releaseStreams 在最后一个会话销毁时取消订阅
+func (manager *Manager) releaseStreams() {
+	manager.streamsMu.Lock()
+	defer manager.streamsMu.Unlock()
+
+	manager.streamUsers--
+	if manager.streamUsers > 0 {
+		return
+	}
+
+	for _, unsubscribe := range manager.unsubscribe {
+		unsubscribe()
+	}
+	manager.unsubscribe = nil
+}
+
+func (manager *Manager) writeTrack(track *webrtc.TrackLocalStaticRTP, packets <-chan rtp.Packet, kind string) {
+	for packet := range packets {
+		if err := track.WriteRTP(&packet); err != nil && errors.Is(err, io.ErrClosedPipe) {
+			manager.logger.Error("Track closed", "kind", kind, "error", err)
+		}
+	}
+
+	manager.logger.Debug("Sample channel closed", "kind", kind)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m4n5ter/lindows/internal/desktop/macro"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+	"github.com/spf13/cobra"
+)
+
+var macroCmd = &cobra.Command{
+	Use:   "macro",
+	Short: "Record and play input macros on a running Lindows service",
+}
+
+var macroRecordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record the input injected by remote sessions until interrupted",
+	Args:  cobra.NoArgs,
+	Run:   macroRecordCommand,
+}
+
+var macroPlayCmd = &cobra.Command{
+	Use:   "play [file]",
+	Short: "Play a macro file or a macro saved on the service",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   macroPlayCommand,
+}
+
+func init() {
+	macroCmd.PersistentFlags().String("server", "http://127.0.0.1:11111", "Lindows 服务的地址")
+	macroCmd.PersistentFlags().String("token", "", "访问 HTTP 接口的令牌")
+
+	macroRecordCmd.Flags().StringP("output", "o", "recording"+macro.Extension, "输出文件, 为空时不保存到本地")
+	macroRecordCmd.Flags().String("name", "", "同时按名称保存在服务端, 可以在客户端回放")
+	macroRecordCmd.Flags().Duration("duration", 0, "录制时长, 0 表示直到按下 Ctrl+C")
+
+	macroPlayCmd.Flags().Float64("speed", 1, "回放速度, 2 表示两倍速")
+	macroPlayCmd.Flags().String("name", "", "回放服务端按名称保存的宏, 此时不需要文件")
+
+	macroCmd.AddCommand(macroRecordCmd, macroPlayCmd)
+}
+
+func macroRecordCommand(cmd *cobra.Command, _ []string) {
+	output, _ := cmd.Flags().GetString("output")
+	name, _ := cmd.Flags().GetString("name")
+	duration, _ := cmd.Flags().GetDuration("duration")
+
+	if _, err := macroRequest(context.Background(), cmd, "/macros/record", nil, nil); err != nil {
+		yalog.Fatal("Failed to start recording", "error", err)
+	}
+	yalog.Info("Recording, press Ctrl+C to stop", "duration", duration)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+	<-ctx.Done()
+	stop()
+
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+	body, err := macroRequest(context.Background(), cmd, "/macros/stop", query, nil)
+	if err != nil {
+		yalog.Fatal("Failed to stop recording", "error", err)
+	}
+
+	recorded, err := macro.Decode(bytes.NewReader(body))
+	if err != nil {
+		yalog.Fatal("Invalid macro from server", "error", err)
+	}
+
+	if output != "" {
+		if err := os.WriteFile(output, body, 0o644); err != nil {
+			yalog.Fatal("Failed to write macro", "error", err)
+		}
+	}
+
+	yalog.Info("Macro recorded", "output", output, "name", name, "events", len(recorded.Events), "duration", recorded.Duration())
+}
+
+func macroPlayCommand(cmd *cobra.Command, args []string) {
+	speed, _ := cmd.Flags().GetFloat64("speed")
+	name, _ := cmd.Flags().GetString("name")
+
+	if speed <= 0 {
+		yalog.Fatal("Invalid speed", "speed", speed)
+	}
+	if (name == "") == (len(args) == 0) {
+		yalog.Fatal("Specify either a macro file or --name")
+	}
+
+	query := url.Values{"speed": {strconv.FormatFloat(speed, 'f', -1, 64)}}
+	var body io.Reader
+	if name != "" {
+		query.Set("name", name)
+	} else {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			yalog.Fatal("Failed to read macro", "error", err)
+		}
+		// 先在本地检查，避免把无效的文件发给服务端
+		recorded, err := macro.Decode(bytes.NewReader(data))
+		if err != nil {
+			yalog.Fatal("Invalid macro file", "file", args[0], "error", err)
+		}
+		yalog.Info("Playing macro", "file", args[0], "events", len(recorded.Events), "duration", time.Duration(float64(recorded.Duration())/speed))
+		body = bytes.NewReader(data)
+	}
+
+	// 中断时断开请求，服务端随之停止回放并释放按住的按键
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if _, err := macroRequest(ctx, cmd, "/macros/play", query, body); err != nil {
+		yalog.Fatal("Failed to play macro", "error", err)
+	}
+	yalog.Info("Macro played")
+}
+
+// macroRequest 向服务端的宏接口发送 POST 请求并返回响应体
+func macroRequest(ctx context.Context, cmd *cobra.Command, path string, query url.Values, body io.Reader) ([]byte, error) {
+	server, _ := cmd.Flags().GetString("server")
+	token, _ := cmd.Flags().GetString("token")
+
+	endpoint := strings.TrimSuffix(server, "/") + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
@@ -9,6 +9,12 @@ import (
 	"github.com/m4n5ter/lindows/internal/capture"
 	"github.com/m4n5ter/lindows/internal/config"
 	"github.com/m4n5ter/lindows/internal/desktop"
+	"github.com/m4n5ter/lindows/internal/egress"
+	"github.com/m4n5ter/lindows/internal/hls"
+	"github.com/m4n5ter/lindows/internal/playback"
+	"github.com/m4n5ter/lindows/internal/recorder"
+	"github.com/m4n5ter/lindows/internal/server"
+	"github.com/m4n5ter/lindows/internal/session"
 	"github.com/m4n5ter/lindows/internal/webrtc"
 	"github.com/m4n5ter/lindows/pkg/yalog"
 	"github.com/spf13/cobra"
@@ -21,20 +27,38 @@ var serve = &cobra.Command{
 }
 
 var service = &Lindows{
-	Capture: &config.Capture{},
-	Desktop: &config.Desktop{},
-	WebRTC:  &config.WebRTC{},
+	Capture:  &config.Capture{},
+	Desktop:  &config.Desktop{},
+	Egress:   &config.Egress{},
+	HLS:      &config.HLS{},
+	Playback: &config.Playback{},
+	Recorder: &config.Recorder{},
+	Server:   &config.Server{},
+	Session:  &config.Session{},
+	WebRTC:   &config.WebRTC{},
 }
 
 type Lindows struct {
-	Capture *config.Capture
-	Desktop *config.Desktop
-	WebRTC  *config.WebRTC
-
-	logger         *yalog.Logger
-	captureManager *capture.Manager
-	desktopManager *desktop.Manager
-	webRTCManager  *webrtc.Manager
+	Capture  *config.Capture
+	Desktop  *config.Desktop
+	Egress   *config.Egress
+	HLS      *config.HLS
+	Playback *config.Playback
+	Recorder *config.Recorder
+	Server   *config.Server
+	Session  *config.Session
+	WebRTC   *config.WebRTC
+
+	logger          *yalog.Logger
+	captureManager  *capture.Manager
+	desktopManager  *desktop.Manager
+	egressManager   *egress.Manager
+	hlsManager      *hls.Manager
+	playbackManager *playback.Manager
+	recorderManager *recorder.Manager
+	serverManager   *server.Manager
+	sessionManager  *session.Manager
+	webRTCManager   *webrtc.Manager
 }
 
 func (lindows *Lindows) ServeCommand(cmd *cobra.Command, args []string) {
@@ -52,21 +76,69 @@ func (lindows *Lindows) ServeCommand(cmd *cobra.Command, args []string) {
 }
 
 func (lindows *Lindows) Start() {
+	sessionManager := session.New(lindows.Session)
+
 	desktopManager := desktop.New(lindows.Desktop)
 	desktopManager.Start()
 
 	captureManager := capture.New(*desktopManager, lindows.Capture)
 	captureManager.Start()
 
-	webRTCManager := webrtc.New(lindows.WebRTC)
+	egressManager := egress.New(captureManager.Video(), lindows.Egress)
+	egressManager.Start()
+
+	hlsManager := hls.New(captureManager.Video(), lindows.HLS)
+	hlsManager.Start()
+
+	recorderManager := recorder.New(sessionManager, captureManager.Video(), lindows.Recorder)
+	recorderManager.Start()
+
+	playbackManager := playback.New(lindows.Playback)
+	playbackManager.Start()
+
+	webRTCManager := webrtc.New(sessionManager, desktopManager, captureManager, playbackManager, lindows.WebRTC)
+	if hlsManager.Enabled() {
+		webRTCManager.SetFallback(hls.PlaylistPath)
+	}
 	webRTCManager.Start()
 
+	serverManager := server.New(desktopManager, lindows.Server)
+	serverManager.Handle("GET /ws", serverManager.Authenticate(webRTCManager.SignalHandler()))
+	serverManager.Handle("/sessions", serverManager.Authenticate(sessionManager.Handler()))
+	serverManager.Handle("/sessions/", serverManager.Authenticate(sessionManager.Handler()))
+	serverManager.Handle("/outputs", serverManager.Authenticate(egressManager.Handler()))
+	serverManager.Handle("/outputs/", serverManager.Authenticate(egressManager.Handler()))
+	serverManager.Handle("GET /hls/", serverManager.Authenticate(hlsManager.Handler()))
+	serverManager.Handle("/playback", serverManager.Authenticate(playbackManager.Handler()))
+	serverManager.Handle("/playback/", serverManager.Authenticate(playbackManager.Handler()))
+	serverManager.Handle("GET /input/", serverManager.Authenticate(desktopManager.InputHandler()))
+	serverManager.Handle("/macros", serverManager.Authenticate(desktopManager.MacroHandler()))
+	serverManager.Handle("/macros/", serverManager.Authenticate(desktopManager.MacroHandler()))
+	serverManager.Start()
+
+	lindows.sessionManager = sessionManager
 	lindows.desktopManager = desktopManager
 	lindows.captureManager = captureManager
+	lindows.egressManager = egressManager
+	lindows.hlsManager = hlsManager
+	lindows.playbackManager = playbackManager
+	lindows.recorderManager = recorderManager
 	lindows.webRTCManager = webRTCManager
+	lindows.serverManager = serverManager
 }
 
-func (lindows *Lindows) Stop() {}
+func (lindows *Lindows) Stop() {
+	if err := lindows.serverManager.Shutdown(); err != nil {
+		lindows.logger.Error("Failed to shutdown HTTP server", "error", err)
+	}
+
+	lindows.recorderManager.Shutdown()
+	lindows.egressManager.Shutdown()
+	lindows.hlsManager.Shutdown()
+	lindows.playbackManager.Shutdown()
+	lindows.captureManager.Shutdown()
+	lindows.desktopManager.Shutdown()
+}
 
 func main() {
 	service.logger = yalog.Default().With("service", "lindows")
@@ -74,6 +146,12 @@ func main() {
 	configs := []config.Config{
 		service.Capture,
 		service.Desktop,
+		service.Egress,
+		service.HLS,
+		service.Playback,
+		service.Recorder,
+		service.Server,
+		service.Session,
 		service.WebRTC,
 	}
 
@@ -89,7 +167,7 @@ func main() {
 		}
 	}
 
-	root.AddCommand(serve)
+	root.AddCommand(serve, screenshotCmd, macroCmd)
 
 	Execute()
 }
@@ -1 +1,67 @@
-package ffmpeg_test
+package ffmpeg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+func TestProgressSet(t *testing.T) {
+	block := []struct{ key, value string }{
+		{"frame", "150"},
+		{"fps", "29.97"},
+		{"stream_0_0_q", "10.0"},
+		{"bitrate", "1024.5kbits/s"},
+		{"total_size", "655360"},
+		{"out_time_us", "5000000"},
+		{"dup_frames", "1"},
+		{"drop_frames", "3"},
+		{"speed", "1.01x"},
+	}
+
+	var p Progress
+	for _, kv := range block {
+		if p.set(kv.key, kv.value) {
+			t.Fatalf("block completed early at %s", kv.key)
+		}
+	}
+	if !p.set("progress", "end") {
+		t.Fatal("progress=end should complete the block")
+	}
+
+	want := Progress{
+		Frame:       150,
+		FPS:         29.97,
+		BitrateKbps: 1024.5,
+		TotalSize:   655360,
+		OutTime:     5 * time.Second,
+		DupFrames:   1,
+		DropFrames:  3,
+		Speed:       1.01,
+		Done:        true,
+	}
+	if p != want {
+		t.Fatalf("got %+v, want %+v", p, want)
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	cases := []struct {
+		line  string
+		level yalog.Level
+		msg   string
+	}{
+		{"[info] Input #0, gdigrab, from 'desktop':", yalog.LevelInfo, "Input #0, gdigrab, from 'desktop':"},
+		{"[libvpx @ 0000020] [warning] v1.13.1", yalog.LevelWarn, "[libvpx @ 0000020] v1.13.1"},
+		{"[error] desktop: I/O error", yalog.LevelError, "desktop: I/O error"},
+		{"no level here", yalog.LevelInfo, "no level here"},
+	}
+
+	for _, c := range cases {
+		level, msg := parseLogLine(c.line)
+		if level != c.level || msg != c.msg {
+			t.Errorf("parseLogLine(%q) = %v, %q; want %v, %q", c.line, level, msg, c.level, c.msg)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+var ErrNotStarted = errors.New("ffmpeg job not started")
+
+// Job is a running ffmpeg process.
+//
+// A Job is started with Start, stopped gracefully by cancelling the context or calling Stop,
+// and its exit status is returned by Wait.
+type Job struct {
+	options Options
+	logger  *yalog.Logger
+
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	mu       sync.Mutex
+	progress Progress
+
+	started  bool
+	stopOnce sync.Once
+	done     chan struct{}
+	err      error
+}
+
+func NewJob(options Options) *Job {
+	logger := options.Logger
+	if logger == nil {
+		logger = yalog.Default().With("module", "ffmpeg")
+	}
+	if options.StopTimeout <= 0 {
+		options.StopTimeout = defaultStopTimeout
+	}
+
+	return &Job{
+		options: options,
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+}
+
+// Run starts a job and waits for it to finish.
+func Run(ctx context.Context, options Options) error {
+	job := NewJob(options)
+	if err := job.Start(ctx); err != nil {
+		return err
+	}
+	return job.Wait()
+}
+
+// Start starts ffmpeg. The job is stopped gracefully when ctx is done.
+func (job *Job) Start(ctx context.Context) error {
+	if job.started {
+		return errors.New("ffmpeg job already started")
+	}
+
+	binary, cleanup := job.options.Binary, func() {}
+	if binary == "" {
+		var err error
+		binary, cleanup, err = TempFFmpeg()
+		if err != nil {
+			return fmt.Errorf("failed to create temp ffmpeg: %v", err)
+		}
+	}
+
+	args := job.options.args()
+	job.cmd = exec.Command(binary, args...)
+	job.cmd.Stdout = job.options.Stdout
+
+	var err error
+	job.stdin, err = job.cmd.StdinPipe()
+	if err != nil {
+		cleanup()
+		return fmt.Errorf("failed to get stdin pipe: %v", err)
+	}
+
+	stderr, err := job.cmd.StderrPipe()
+	if err != nil {
+		cleanup()
+		return fmt.Errorf("failed to get stderr pipe: %v", err)
+	}
+
+	if err := job.cmd.Start(); err != nil {
+		cleanup()
+		return fmt.Errorf("failed to start ffmpeg command: %v", err)
+	}
+	job.started = true
+
+	job.logger.Debug("ffmpeg started", "pid", job.cmd.Process.Pid, "args", strings.Join(args, " "))
+
+	stderrDone := make(chan struct{})
+	go func() {
+		defer close(stderrDone)
+		job.readStderr(stderr)
+	}()
+
+	go func() {
+		// stderr must be drained before calling Wait, see exec.Cmd.StderrPipe
+		<-stderrDone
+		err := job.cmd.Wait()
+		cleanup()
+
+		if err != nil {
+			job.err = fmt.Errorf("ffmpeg command failed: %v", err)
+		}
+		close(job.done)
+	}()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = job.Stop()
+		case <-job.done:
+		}
+	}()
+
+	return nil
+}
+
+// Wait waits for ffmpeg to exit and returns its exit error, if any.
+func (job *Job) Wait() error {
+	if !job.started {
+		return ErrNotStarted
+	}
+
+	<-job.done
+	return job.err
+}
+
+// Done returns a channel that is closed when ffmpeg exits.
+func (job *Job) Done() <-chan struct{} {
+	return job.done
+}
+
+// Progress returns the latest progress reported by ffmpeg.
+func (job *Job) Progress() Progress {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	return job.progress
+}
+
+// Stop asks ffmpeg to quit by writing `q` to its stdin, so that the output is finalized properly,
+// and kills it if it is still running after Options.StopTimeout.
+func (job *Job) Stop() error {
+	if !job.started {
+		return ErrNotStarted
+	}
+
+	var err error
+	job.stopOnce.Do(func() {
+		if _, werr := io.WriteString(job.stdin, "q"); werr != nil {
+			job.logger.Debug("failed to send q to ffmpeg", "error", werr)
+		}
+		_ = job.stdin.Close()
+
+		timer := time.NewTimer(job.options.StopTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-job.done:
+		case <-timer.C:
+			job.logger.Warn("ffmpeg did not quit in time, killing it", "timeout", job.options.StopTimeout)
+			err = job.cmd.Process.Kill()
+			<-job.done
+		}
+	})
+
+	return err
+}
+
+func (job *Job) readStderr(stderr io.Reader) {
+	var progress Progress
+
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		// ffmpeg log lines always start with "[" because of `-loglevel level+...`,
+		// everything else of the form key=value is written by `-progress`.
+		if !strings.HasPrefix(line, "[") {
+			if key, value, ok := strings.Cut(line, "="); ok && isProgressKey(key) {
+				if progress.set(key, value) {
+					job.setProgress(progress)
+				}
+				continue
+			}
+		}
+
+		level, msg := parseLogLine(line)
+		job.logger.Log(context.Background(), level, msg)
+	}
+
+	if err := scanner.Err(); err != nil {
+		job.logger.Debug("failed to read ffmpeg stderr", "error", err)
+	}
+}
+
+func (job *Job) setProgress(progress Progress) {
+	job.mu.Lock()
+	job.progress = progress
+	job.mu.Unlock()
+
+	if job.options.OnProgress != nil {
+		job.options.OnProgress(progress)
+	}
+}
@@ -0,0 +1,86 @@
+package ffmpeg
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+const defaultStopTimeout = 5 * time.Second
+
+// Options describes a single ffmpeg invocation.
+//
+// The generated command line looks like:
+//
+//	ffmpeg -hide_banner -loglevel level+info -nostats -progress pipe:2 -y \
+//		[-f InputFormat] [-framerate FrameRate] [InputArgs...] -i Input \
+//		[-t Duration] [OutputArgs...] [-f OutputFormat] Output
+type Options struct {
+	// Binary is the path of the ffmpeg executable. The embedded ffmpeg is extracted to a temp file when it is empty.
+	Binary string
+
+	// InputFormat is passed as `-f` before the input, e.g. "gdigrab".
+	InputFormat string
+	// Input is passed as `-i`, e.g. "desktop".
+	Input string
+	// FrameRate is passed as `-framerate` before the input, 0 means ffmpeg's default.
+	FrameRate int
+	// InputArgs are extra arguments placed before `-i`.
+	InputArgs []string
+
+	// Duration is passed as `-t`, 0 means record until the job is stopped.
+	Duration time.Duration
+	// OutputArgs are extra arguments placed before the output, e.g. codec settings.
+	OutputArgs []string
+	// OutputFormat is passed as `-f` before the output, e.g. "webm".
+	OutputFormat string
+	// Output is the output file or url, defaults to "pipe:1".
+	Output string
+
+	// Stdout receives everything ffmpeg writes to its stdout, e.g. when Output is "pipe:1".
+	Stdout io.Writer
+
+	// OnProgress is called from the stderr reader every time ffmpeg reports a progress block.
+	OnProgress func(Progress)
+
+	// StopTimeout is how long Stop waits after sending `q` before killing ffmpeg, defaults to 5s.
+	StopTimeout time.Duration
+
+	// Logger receives ffmpeg's stderr, defaults to yalog.Default() with module "ffmpeg".
+	Logger *yalog.Logger
+}
+
+func (options *Options) args() []string {
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "level+info",
+		"-nostats",
+		"-progress", "pipe:2",
+		"-y",
+	}
+
+	if options.InputFormat != "" {
+		args = append(args, "-f", options.InputFormat)
+	}
+	if options.FrameRate > 0 {
+		args = append(args, "-framerate", strconv.Itoa(options.FrameRate))
+	}
+	args = append(args, options.InputArgs...)
+	args = append(args, "-i", options.Input)
+
+	if options.Duration > 0 {
+		args = append(args, "-t", strconv.FormatFloat(options.Duration.Seconds(), 'f', -1, 64))
+	}
+	args = append(args, options.OutputArgs...)
+	if options.OutputFormat != "" {
+		args = append(args, "-f", options.OutputFormat)
+	}
+
+	output := options.Output
+	if output == "" {
+		output = "pipe:1"
+	}
+	return append(args, output)
+}
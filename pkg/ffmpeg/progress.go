@@ -0,0 +1,89 @@
+package ffmpeg
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m4n5ter/lindows/pkg/yalog"
+)
+
+// Progress is a snapshot of the key=value block ffmpeg writes for `-progress`.
+type Progress struct {
+	Frame       int64
+	FPS         float64
+	BitrateKbps float64
+	TotalSize   int64
+	OutTime     time.Duration
+	DupFrames   int64
+	DropFrames  int64
+	// Speed is the encoding speed relative to realtime, 1.0 means realtime.
+	Speed float64
+	// Done is true for the last block ffmpeg writes before exiting.
+	Done bool
+}
+
+// set applies one key=value pair and reports whether the block is complete.
+func (p *Progress) set(key, value string) (complete bool) {
+	switch key {
+	case "frame":
+		p.Frame, _ = strconv.ParseInt(value, 10, 64)
+	case "fps":
+		p.FPS, _ = strconv.ParseFloat(value, 64)
+	case "bitrate":
+		p.BitrateKbps, _ = strconv.ParseFloat(strings.TrimSuffix(value, "kbits/s"), 64)
+	case "total_size":
+		p.TotalSize, _ = strconv.ParseInt(value, 10, 64)
+	case "out_time_us":
+		us, _ := strconv.ParseInt(value, 10, 64)
+		p.OutTime = time.Duration(us) * time.Microsecond
+	case "dup_frames":
+		p.DupFrames, _ = strconv.ParseInt(value, 10, 64)
+	case "drop_frames":
+		p.DropFrames, _ = strconv.ParseInt(value, 10, 64)
+	case "speed":
+		p.Speed, _ = strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "x")), 64)
+	case "progress":
+		p.Done = value == "end"
+		return true
+	}
+	return false
+}
+
+// isProgressKey reports whether key looks like a `-progress` key, e.g. "frame" or "stream_0_0_q".
+func isProgressKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// ffmpeg prints "[level]" in every line when started with `-loglevel level+...`.
+var logLevels = []struct {
+	tag   string
+	level yalog.Level
+}{
+	{"[panic] ", yalog.LevelError},
+	{"[fatal] ", yalog.LevelError},
+	{"[error] ", yalog.LevelError},
+	{"[warning] ", yalog.LevelWarn},
+	{"[info] ", yalog.LevelInfo},
+	{"[verbose] ", yalog.LevelDebug},
+	{"[debug] ", yalog.LevelDebug},
+	{"[trace] ", yalog.LevelDebug},
+}
+
+// parseLogLine strips the "[level]" tag from an ffmpeg log line and maps it to a yalog level.
+func parseLogLine(line string) (yalog.Level, string) {
+	for _, l := range logLevels {
+		if i := strings.Index(line, l.tag); i >= 0 {
+			return l.level, line[:i] + line[i+len(l.tag):]
+		}
+	}
+	return yalog.LevelInfo, line
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m4n5ter/lindows/internal/desktop/screenshot"
+	"github.com/m4n5ter/lindows/pkg/yalog"
+	"github.com/spf13/cobra"
+)
+
+var screenshotCmd = &cobra.Command{
+	Use:   "screenshot",
+	Short: "Take a screenshot of the whole desktop",
+	Args:  cobra.NoArgs,
+	Run:   screenshotCommand,
+}
+
+func init() {
+	screenshotCmd.Flags().StringP("output", "o", "screenshot.png", "输出文件, 根据扩展名选择 png 或 jpg 格式")
+	screenshotCmd.Flags().Int("width", 0, "缩放后的宽度, 0 表示按高度等比缩放")
+	screenshotCmd.Flags().Int("height", 0, "缩放后的高度, 0 表示按宽度等比缩放")
+	screenshotCmd.Flags().Int("quality", 80, "jpg 质量, 1-100")
+}
+
+func screenshotCommand(cmd *cobra.Command, _ []string) {
+	output, _ := cmd.Flags().GetString("output")
+	width, _ := cmd.Flags().GetInt("width")
+	height, _ := cmd.Flags().GetInt("height")
+	quality, _ := cmd.Flags().GetInt("quality")
+
+	img, err := screenshot.New().Grab()
+	if err != nil {
+		yalog.Fatal("Failed to take screenshot", "error", err)
+	}
+
+	if width > 0 || height > 0 {
+		width, height = screenshot.FitSize(img.Bounds(), width, height)
+		img = screenshot.Scale(img, width, height)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		yalog.Fatal("Failed to create output file", "error", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".jpg", ".jpeg":
+		err = jpeg.Encode(file, img, &jpeg.Options{Quality: quality})
+	default:
+		err = png.Encode(file, img)
+	}
+	if err != nil {
+		yalog.Fatal("Failed to encode screenshot", "error", err)
+	}
+
+	yalog.Info("Screenshot saved", "output", output, "size", img.Bounds().Size())
+}
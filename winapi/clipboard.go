@@ -1,5 +1,10 @@
 package winapi
 
+import (
+	"syscall"
+	"unsafe"
+)
+
 var (
 	procOpenClipboard          = user32.MustFindProc("OpenClipboard")
 	procCloseClipboard         = user32.MustFindProc("CloseClipboard")
@@ -7,6 +12,10 @@ var (
 	procSetClipboardData       = user32.MustFindProc("SetClipboardData")
 	procGetClipboardData       = user32.MustFindProc("GetClipboardData")
 	procGetOpenClipboardWindow = user32.MustFindProc("GetOpenClipboardWindow")
+
+	procIsClipboardFormatAvailable = user32.MustFindProc("IsClipboardFormatAvailable")
+	procGetClipboardSequenceNumber = user32.MustFindProc("GetClipboardSequenceNumber")
+	procRegisterClipboardFormat    = user32.MustFindProc("RegisterClipboardFormatW")
 )
 
 // OpenClipboard 打开剪切板
@@ -115,3 +124,49 @@ func GetClipboardOwner() HWND {
 
 	return HWND(r1)
 }
+
+// IsClipboardFormatAvailable 判断剪切板中是否有 uFormat 格式的数据，不需要打开剪切板
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-isclipboardformatavailable
+//
+//	BOOL IsClipboardFormatAvailable(
+//		[in] UINT format
+//	);
+func IsClipboardFormatAvailable(uFormat uint32) bool {
+	r1, _, _ := procIsClipboardFormatAvailable.Call(uintptr(uFormat))
+	return r1 != 0
+}
+
+// GetClipboardSequenceNumber 返回当前窗口站剪切板的序号，剪切板的内容每次变化时序号都会增加
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-getclipboardsequencenumber
+//
+//	DWORD GetClipboardSequenceNumber();
+func GetClipboardSequenceNumber() uint32 {
+	r1, _, _ := procGetClipboardSequenceNumber.Call()
+	return uint32(r1)
+}
+
+// RegisterClipboardFormat 注册名为 name 的剪切板格式并返回格式编号，同名的格式总是返回相同的编号
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-registerclipboardformatw
+//
+//	UINT RegisterClipboardFormatW(
+//		[in] LPCWSTR lpszFormat
+//	);
+func RegisterClipboardFormat(name string) (uint32, error) {
+	p, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+
+	r1, _, err := procRegisterClipboardFormat.Call(uintptr(unsafe.Pointer(p)))
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return 0, syscall.EINVAL
+		}
+
+		return 0, err
+	}
+	return uint32(r1), nil
+}
@@ -16,6 +16,23 @@ var (
 	procSelectObject           = gdi32.MustFindProc("SelectObject")
 	procGetDIBits              = gdi32.MustFindProc("GetDIBits")
 	procGetObject              = gdi32.MustFindProc("GetObjectW")
+	procGetDeviceCaps          = gdi32.MustFindProc("GetDeviceCaps")
+)
+
+// 光栅操作码和 DIB 相关常量
+const (
+	SrcCopy      = 0x00CC0020 // 将源矩形区域直接复制到目标矩形区域。
+	CaptureBlt   = 0x40000000 // 包括在窗口顶部分层的任何窗口。
+	DIBRGBColors = 0          // 颜色表包含 RGB 值。
+	BIRGB        = 0          // 未压缩的格式。
+)
+
+// GetDeviceCaps 的参数
+const (
+	HorzRes        = 8   // 屏幕的宽度，以像素为单位，未感知 DPI 的程序得到的是缩放后的值。
+	VertRes        = 10  // 屏幕的高度，以像素为单位，未感知 DPI 的程序得到的是缩放后的值。
+	DesktopVertRes = 117 // 屏幕的实际高度，以物理像素为单位。
+	DesktopHorzRes = 118 // 屏幕的实际宽度，以物理像素为单位。
 )
 
 type BITMAPINFO struct {
@@ -72,7 +89,7 @@ type Bitmap struct {
 func CreateCompatibleDC(hdc HDC) (HDC, error) {
 	r1, _, err := procCreateCompatibleDC.Call(uintptr(hdc))
 	memDC := HDC(r1)
-	if hdc == 0 {
+	if memDC == 0 {
 		if err.(syscall.Errno) == 0 {
 			return 0, syscall.EINVAL
 		}
@@ -268,3 +285,16 @@ func GetObjectW(hgdiobj HGDIOBJ, c uint32, pv uintptr) uint32 {
 	}
 	return c
 }
+
+// GetDeviceCaps 检索指定设备的特定信息。
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/wingdi/nf-wingdi-getdevicecaps
+//
+//	int GetDeviceCaps(
+//		[in] HDC hdc,
+//		[in] int index
+//	);
+func GetDeviceCaps(hdc HDC, index int32) int32 {
+	r1, _, _ := procGetDeviceCaps.Call(uintptr(hdc), uintptr(index))
+	return int32(r1)
+}
@@ -1,6 +1,9 @@
 package winapi
 
-import "syscall"
+import (
+	"syscall"
+	"unsafe"
+)
 
 // https://learn.microsoft.com/zh-cn/windows/win32/api/winbase
 var (
@@ -9,6 +12,11 @@ var (
 	procGlobalFree   = kernel32.MustFindProc("GlobalFree")
 	procGlobalLock   = kernel32.MustFindProc("GlobalLock")
 	procGlobalUnlock = kernel32.MustFindProc("GlobalUnlock")
+	procGlobalSize   = kernel32.MustFindProc("GlobalSize")
+
+	procMultiByteToWideChar = kernel32.MustFindProc("MultiByteToWideChar")
+
+	procProcessIdToSessionId = kernel32.MustFindProc("ProcessIdToSessionId")
 )
 
 // GlobalAlloc 获取内存
@@ -79,3 +87,87 @@ func GlobalUnlock(hMem HGLOBAL) bool {
 	r1, _, _ := procGlobalUnlock.Call(uintptr(hMem))
 	return r1 != 0
 }
+
+// GlobalSize 返回全局内存对象的字节数，可能大于分配时请求的大小
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winbase/nf-winbase-globalsize
+//
+//	SIZE_T GlobalSize(
+//	    [in] HGLOBAL hMem
+//	);
+func GlobalSize(hMem HGLOBAL) (uintptr, error) {
+	r1, _, err := procGlobalSize.Call(uintptr(hMem))
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return 0, syscall.EINVAL
+		}
+
+		return 0, err
+	}
+	return r1, nil
+}
+
+// CpACP 是系统默认的 ANSI 代码页
+const CpACP = 0
+
+// MultiByteToWideChar 把代码页 codePage 编码的字符串转换为 UTF-16，src 不需要以 0 结尾
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/stringapiset/nf-stringapiset-multibytetowidechar
+//
+//	int MultiByteToWideChar(
+//		[in]            UINT                              CodePage,
+//		[in]            DWORD                             dwFlags,
+//		[in]            _In_NLS_string_(cbMultiByte)LPCCH lpMultiByteStr,
+//		[in]            int                               cbMultiByte,
+//		[out, optional] LPWSTR                            lpWideCharStr,
+//		[in]            int                               cchWideChar
+//	);
+func MultiByteToWideChar(codePage uint32, src []byte) ([]uint16, error) {
+	if len(src) == 0 {
+		return nil, nil
+	}
+
+	// 第一次调用获取需要的长度
+	n, _, err := procMultiByteToWideChar.Call(uintptr(codePage), 0, uintptr(unsafe.Pointer(&src[0])), uintptr(len(src)), 0, 0)
+	if n == 0 {
+		if err.(syscall.Errno) == 0 {
+			return nil, syscall.EINVAL
+		}
+
+		return nil, err
+	}
+
+	dst := make([]uint16, n)
+	n, _, err = procMultiByteToWideChar.Call(uintptr(codePage), 0, uintptr(unsafe.Pointer(&src[0])), uintptr(len(src)),
+		uintptr(unsafe.Pointer(&dst[0])), uintptr(len(dst)))
+	if n == 0 {
+		if err.(syscall.Errno) == 0 {
+			return nil, syscall.EINVAL
+		}
+
+		return nil, err
+	}
+	return dst[:n], nil
+}
+
+// ProcessIdToSessionId 返回进程所在的远程桌面服务会话，服务运行在会话 0 中
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/processthreadsapi/nf-processthreadsapi-processidtosessionid
+//
+//	BOOL ProcessIdToSessionId(
+//		[in]  DWORD dwProcessId,
+//		[out] DWORD *pSessionId
+//	);
+func ProcessIdToSessionId(processID uint32) (uint32, error) {
+	var sessionID uint32
+
+	r1, _, err := procProcessIdToSessionId.Call(uintptr(processID), uintptr(unsafe.Pointer(&sessionID)))
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return 0, syscall.EINVAL
+		}
+
+		return 0, err
+	}
+	return sessionID, nil
+}
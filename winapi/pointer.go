@@ -0,0 +1,240 @@
+package winapi
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// 触摸注入和合成指针的接口，见 https://learn.microsoft.com/zh-cn/windows/win32/input_touchinjection/touch-injection-portal
+var (
+	procInitializeTouchInjection = user32.MustFindProc("InitializeTouchInjection")
+	procInjectTouchInput         = user32.MustFindProc("InjectTouchInput")
+
+	// 合成指针从 Windows 10 1809 开始才有，不能在初始化时要求它存在
+	lazyUser32                        = syscall.NewLazyDLL("user32.dll")
+	procCreateSyntheticPointerDevice  = lazyUser32.NewProc("CreateSyntheticPointerDevice")
+	procInjectSyntheticPointerInput   = lazyUser32.NewProc("InjectSyntheticPointerInput")
+	procDestroySyntheticPointerDevice = lazyUser32.NewProc("DestroySyntheticPointerDevice")
+)
+
+type HSYNTHETICPOINTERDEVICE HANDLE
+
+// POINTER_INPUT_TYPE
+const (
+	PTPointer  uint32 = 1 // 通用指针类型。
+	PTTouch    uint32 = 2 // 触摸指针类型。
+	PTPen      uint32 = 3 // 笔指针类型。
+	PTMouse    uint32 = 4 // 鼠标指针类型。
+	PTTouchpad uint32 = 5 // 触摸板指针类型。
+)
+
+// POINTER_FLAGS
+const (
+	PointerFlagNone         uint32 = 0x00000000 // 默认值。
+	PointerFlagNew          uint32 = 0x00000001 // 表示新指针的到来。
+	PointerFlagInRange      uint32 = 0x00000002 // 指针在感应范围内。
+	PointerFlagInContact    uint32 = 0x00000004 // 指针与数字化器表面接触。
+	PointerFlagFirstButton  uint32 = 0x00000010 // 主要操作，对笔来说是笔尖接触。
+	PointerFlagSecondButton uint32 = 0x00000020 // 次要操作，对笔来说是按住笔杆按钮。
+	PointerFlagPrimary      uint32 = 0x00002000 // 该指针是主指针。
+	PointerFlagConfidence   uint32 = 0x00004000 // 指针不是意外触碰。
+	PointerFlagCanceled     uint32 = 0x00008000 // 指针以异常方式离开，例如被系统手势取消。
+	PointerFlagDown         uint32 = 0x00010000 // 指针转换为按下状态。
+	PointerFlagUpdate       uint32 = 0x00020000 // 简单的更新，不包含状态变化。
+	PointerFlagUp           uint32 = 0x00040000 // 指针转换为抬起状态。
+)
+
+// TOUCH_MASK
+const (
+	TouchMaskNone        uint32 = 0x00000000 // 默认值，没有可选字段有效。
+	TouchMaskContactArea uint32 = 0x00000001 // rcContact 有效。
+	TouchMaskOrientation uint32 = 0x00000002 // orientation 有效。
+	TouchMaskPressure    uint32 = 0x00000004 // pressure 有效。
+)
+
+// PEN_FLAGS
+const (
+	PenFlagNone     uint32 = 0x00000000 // 没有按下笔的按钮。
+	PenFlagBarrel   uint32 = 0x00000001 // 按下了笔杆按钮。
+	PenFlagInverted uint32 = 0x00000002 // 笔倒置，橡皮擦端朝向屏幕。
+	PenFlagEraser   uint32 = 0x00000004 // 橡皮擦端接触屏幕。
+)
+
+// PEN_MASK
+const (
+	PenMaskNone     uint32 = 0x00000000 // 默认值，没有可选字段有效。
+	PenMaskPressure uint32 = 0x00000001 // pressure 有效。
+	PenMaskRotation uint32 = 0x00000002 // rotation 有效。
+	PenMaskTiltX    uint32 = 0x00000004 // tiltX 有效。
+	PenMaskTiltY    uint32 = 0x00000008 // tiltY 有效。
+)
+
+// InitializeTouchInjection 和 CreateSyntheticPointerDevice 的反馈模式
+const (
+	TouchFeedbackDefault  uint32 = 0x1 // 显示系统默认的触摸反馈。
+	TouchFeedbackIndirect uint32 = 0x2 // 显示间接触摸反馈。
+	TouchFeedbackNone     uint32 = 0x3 // 不显示触摸反馈。
+)
+
+// MaxTouchCount 是 InitializeTouchInjection 允许的最大触点数
+const MaxTouchCount = 256
+
+// POINTER_INFO
+//
+// PerformanceCount 之后显式补齐 4 字节，使 32 位和 64 位下的大小都与 C 的 8 字节对齐一致。
+type POINTER_INFO struct {
+	PointerType           uint32
+	PointerId             uint32
+	FrameId               uint32
+	PointerFlags          uint32
+	SourceDevice          HANDLE
+	HwndTarget            HWND
+	PtPixelLocation       POINT
+	PtHimetricLocation    POINT
+	PtPixelLocationRaw    POINT
+	PtHimetricLocationRaw POINT
+	DwTime                uint32
+	HistoryCount          uint32
+	InputData             int32
+	DwKeyStates           uint32
+	PerformanceCount      uint64
+	ButtonChangeType      int32
+	_                     uint32
+}
+
+// POINTER_TOUCH_INFO
+type POINTER_TOUCH_INFO struct {
+	PointerInfo  POINTER_INFO
+	TouchFlags   uint32
+	TouchMask    uint32
+	RcContact    RECT
+	RcContactRaw RECT
+	Orientation  uint32
+	Pressure     uint32
+}
+
+// POINTER_PEN_INFO
+type POINTER_PEN_INFO struct {
+	PointerInfo POINTER_INFO
+	PenFlags    uint32
+	PenMask     uint32
+	Pressure    uint32
+	Rotation    uint32
+	TiltX       int32
+	TiltY       int32
+}
+
+// POINTER_TYPE_INFO 中联合的是 POINTER_PEN_INFO 时的布局。
+//
+// 联合按 8 字节对齐，Type 之后显式补齐；联合的大小取决于更大的 POINTER_TOUCH_INFO，末尾同样补齐。
+type POINTER_TYPE_INFO_PEN struct {
+	Type    uint32
+	_       uint32
+	PenInfo POINTER_PEN_INFO
+	_       [unsafe.Sizeof(POINTER_TOUCH_INFO{}) - unsafe.Sizeof(POINTER_PEN_INFO{})]byte
+}
+
+// InitializeTouchInjection 配置当前进程的触摸注入，maxCount 最大为 MaxTouchCount
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-initializetouchinjection
+//
+//	BOOL InitializeTouchInjection(
+//		[in] UINT32 maxCount,
+//		[in] DWORD  dwMode
+//	);
+func InitializeTouchInjection(maxCount, mode uint32) error {
+	r1, _, err := procInitializeTouchInjection.Call(uintptr(maxCount), uintptr(mode))
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return syscall.EINVAL
+		}
+
+		return err
+	}
+	return nil
+}
+
+// InjectTouchInput 注入一帧触摸，contacts 必须包含所有活动的触点
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-injecttouchinput
+//
+//	BOOL InjectTouchInput(
+//		[in] UINT32                   count,
+//		[in] const POINTER_TOUCH_INFO *contacts
+//	);
+func InjectTouchInput(contacts []POINTER_TOUCH_INFO) error {
+	if len(contacts) == 0 {
+		return nil
+	}
+
+	r1, _, err := procInjectTouchInput.Call(uintptr(len(contacts)), uintptr(unsafe.Pointer(&contacts[0])))
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return syscall.EINVAL
+		}
+
+		return err
+	}
+	return nil
+}
+
+// CreateSyntheticPointerDevice 创建用于注入的合成指针设备，需要 Windows 10 1809 及以上
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-createsyntheticpointerdevice
+//
+//	HSYNTHETICPOINTERDEVICE CreateSyntheticPointerDevice(
+//		[in] POINTER_INPUT_TYPE    pointerType,
+//		[in] ULONG                 maxCount,
+//		[in] POINTER_FEEDBACK_MODE mode
+//	);
+func CreateSyntheticPointerDevice(pointerType, maxCount, mode uint32) (HSYNTHETICPOINTERDEVICE, error) {
+	if err := procCreateSyntheticPointerDevice.Find(); err != nil {
+		return 0, err
+	}
+
+	r1, _, err := procCreateSyntheticPointerDevice.Call(uintptr(pointerType), uintptr(maxCount), uintptr(mode))
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return 0, syscall.EINVAL
+		}
+
+		return 0, err
+	}
+	return HSYNTHETICPOINTERDEVICE(r1), nil
+}
+
+// InjectSyntheticPointerInput 通过合成指针设备注入笔的输入
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-injectsyntheticpointerinput
+//
+//	BOOL InjectSyntheticPointerInput(
+//		[in] HSYNTHETICPOINTERDEVICE device,
+//		[in] const POINTER_TYPE_INFO *pointerInfo,
+//		[in] UINT32                  count
+//	);
+func InjectSyntheticPointerInput(device HSYNTHETICPOINTERDEVICE, pointerInfo []POINTER_TYPE_INFO_PEN) error {
+	if len(pointerInfo) == 0 {
+		return nil
+	}
+
+	r1, _, err := procInjectSyntheticPointerInput.Call(uintptr(device), uintptr(unsafe.Pointer(&pointerInfo[0])), uintptr(len(pointerInfo)))
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return syscall.EINVAL
+		}
+
+		return err
+	}
+	return nil
+}
+
+// DestroySyntheticPointerDevice 销毁合成指针设备
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-destroysyntheticpointerdevice
+//
+//	void DestroySyntheticPointerDevice(
+//		[in] HSYNTHETICPOINTERDEVICE device
+//	);
+func DestroySyntheticPointerDevice(device HSYNTHETICPOINTERDEVICE) {
+	_, _, _ = procDestroySyntheticPointerDevice.Call(uintptr(device))
+}
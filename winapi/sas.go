@@ -0,0 +1,33 @@
+package winapi
+
+import "syscall"
+
+// https://learn.microsoft.com/zh-cn/windows/win32/api/sas/
+var (
+	// sas.dll 只在 Windows 7 及以上的客户端系统中存在，用到时再加载
+	sas         = syscall.NewLazyDLL("sas.dll")
+	procSendSAS = sas.NewProc("SendSAS")
+)
+
+// SendSAS 模拟安全注意序列 (Ctrl+Alt+Del)
+//
+// 调用方必须以服务运行，或者是设置了 uiAccess 的程序；组策略 SoftwareSASGeneration 也必须允许对应的调用方。
+// 函数没有返回值，只能确认 sas.dll 可用，无法知道系统是否接受了请求。
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/sas/nf-sas-sendsas
+//
+//	void SendSAS(
+//		[in] BOOL AsUser
+//	);
+func SendSAS(asUser bool) error {
+	if err := procSendSAS.Find(); err != nil {
+		return err
+	}
+
+	var user uintptr
+	if asUser {
+		user = 1
+	}
+	_, _, _ = procSendSAS.Call(user)
+	return nil
+}
@@ -19,6 +19,22 @@ var (
 	procReleaseDC        = user32.MustFindProc("ReleaseDC")
 	procSendInput        = user32.MustFindProc("SendInput")
 	procSetCursorPosProc = user32.MustFindProc("SetCursorPos")
+	procGetSystemMetrics = user32.MustFindProc("GetSystemMetrics")
+	procMapVirtualKeyW   = user32.MustFindProc("MapVirtualKeyW")
+	procGetCursorInfo    = user32.MustFindProc("GetCursorInfo")
+	procGetIconInfo      = user32.MustFindProc("GetIconInfo")
+	procLockWorkStation  = user32.MustFindProc("LockWorkStation")
+	procExitWindowsEx    = user32.MustFindProc("ExitWindowsEx")
+)
+
+// GetSystemMetrics 的参数
+const (
+	SMCXScreen        = 0  // 主显示器的屏幕宽度，以像素为单位。
+	SMCYScreen        = 1  // 主显示器的屏幕高度，以像素为单位。
+	SMXVirtualScreen  = 76 // 虚拟屏幕左侧的坐标。
+	SMYVirtualScreen  = 77 // 虚拟屏幕顶部的坐标。
+	SMCXVirtualScreen = 78 // 虚拟屏幕的宽度，以像素为单位。
+	SMCYVirtualScreen = 79 // 虚拟屏幕的高度，以像素为单位。
 )
 
 func GetDesktopWindow() HWND {
@@ -218,3 +234,156 @@ func SetCursorPos(x, y int32) bool {
 	}
 	return r1 != 0
 }
+
+// GetSystemMetrics 检索指定的系统指标或系统配置设置。
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-getsystemmetrics
+//
+//	int GetSystemMetrics(
+//		[in] int nIndex
+//	);
+func GetSystemMetrics(nIndex int32) int32 {
+	r1, _, _ := procGetSystemMetrics.Call(uintptr(nIndex))
+	return int32(r1)
+}
+
+// MapVirtualKey 的转换类型
+const (
+	MapVKVKToVSC   = 0 // 虚拟键码转换为扫描码，不区分左右键。
+	MapVKVKToVSCEx = 4 // 虚拟键码转换为扫描码，扩展键的高字节为 0xE0 或 0xE1。
+)
+
+// MapVirtualKey 在虚拟键码和扫描码之间转换，没有对应的值时返回 0。
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-mapvirtualkeyw
+//
+//	UINT MapVirtualKeyW(
+//		[in] UINT uCode,
+//		[in] UINT uMapType
+//	);
+func MapVirtualKey(code, mapType uint32) uint32 {
+	r1, _, _ := procMapVirtualKeyW.Call(uintptr(code), uintptr(mapType))
+	return uint32(r1)
+}
+
+type (
+	HICON   HANDLE
+	HCURSOR = HICON
+)
+
+type POINT struct {
+	X, Y int32
+}
+
+// CURSORINFO 的 Flags
+const (
+	CursorShowing    = 0x00000001 // 光标正在显示。
+	CursorSuppressed = 0x00000002 // 系统没有绘制光标，因为用户正在通过触摸或笔输入。
+)
+
+type CURSORINFO struct {
+	CbSize      uint32
+	Flags       uint32
+	HCursor     HCURSOR
+	PtScreenPos POINT
+}
+
+type ICONINFO struct {
+	FIcon    int32
+	XHotspot uint32
+	YHotspot uint32
+	HbmMask  HBITMAP
+	HbmColor HBITMAP
+}
+
+// GetCursorInfo 检索有关全局光标的信息，包括句柄、是否显示和屏幕坐标
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-getcursorinfo
+//
+//	BOOL GetCursorInfo(
+//		[in, out] PCURSORINFO pci
+//	);
+func GetCursorInfo() (CURSORINFO, error) {
+	info := CURSORINFO{}
+	info.CbSize = uint32(unsafe.Sizeof(info))
+
+	r1, _, err := procGetCursorInfo.Call(uintptr(unsafe.Pointer(&info)))
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return info, syscall.EINVAL
+		}
+
+		return info, err
+	}
+	return info, nil
+}
+
+// GetIconInfo 检索有关指定图标或光标的信息，包括热点和位图
+//
+// 调用方必须用 DeleteObject 删除返回的 HbmMask 和 HbmColor。
+// 单色光标没有 HbmColor，此时 HbmMask 的上半部分是 AND 掩码，下半部分是 XOR 掩码。
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-geticoninfo
+//
+//	BOOL GetIconInfo(
+//		[in]  HICON     hIcon,
+//		[out] PICONINFO piconinfo
+//	);
+func GetIconInfo(hIcon HICON) (ICONINFO, error) {
+	var info ICONINFO
+
+	r1, _, err := procGetIconInfo.Call(uintptr(hIcon), uintptr(unsafe.Pointer(&info)))
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return info, syscall.EINVAL
+		}
+
+		return info, err
+	}
+	return info, nil
+}
+
+// LockWorkStation 锁定工作站的显示，与按 Win+L 相同
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-lockworkstation
+//
+//	BOOL LockWorkStation();
+func LockWorkStation() error {
+	r1, _, err := procLockWorkStation.Call()
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return syscall.EINVAL
+		}
+
+		return err
+	}
+	return nil
+}
+
+// ExitWindowsEx 的 uFlags
+const (
+	EWXLogoff   uint32 = 0x00000000 // 关闭调用进程所在登录会话中的所有进程，然后注销用户。
+	EWXShutdown uint32 = 0x00000001 // 关闭系统，需要 SE_SHUTDOWN_NAME 特权。
+	EWXReboot   uint32 = 0x00000002 // 关闭系统后重启，需要 SE_SHUTDOWN_NAME 特权。
+	EWXForce    uint32 = 0x00000004 // 不向应用程序发送 WM_QUERYENDSESSION，可能丢失未保存的数据。
+)
+
+// ExitWindowsEx 注销交互式用户、关闭或重启系统，函数在操作开始后立即返回
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/winuser/nf-winuser-exitwindowsex
+//
+//	BOOL ExitWindowsEx(
+//		[in] UINT  uFlags,
+//		[in] DWORD dwReason
+//	);
+func ExitWindowsEx(flags, reason uint32) error {
+	r1, _, err := procExitWindowsEx.Call(uintptr(flags), uintptr(reason))
+	if r1 == 0 {
+		if err.(syscall.Errno) == 0 {
+			return syscall.EINVAL
+		}
+
+		return err
+	}
+	return nil
+}
@@ -40,7 +40,8 @@ type (
 
 // 事件
 const (
-	InputMouse uint32 = 0 // 事件是鼠标事件。 使用联合的 mi 结构。
+	InputMouse    uint32 = 0 // 事件是鼠标事件。 使用联合的 mi 结构。
+	InputKeyboard uint32 = 1 // 事件是键盘事件。 使用联合的 ki 结构。
 )
 
 type MInput struct {
@@ -69,6 +70,12 @@ const (
 
 )
 
+// MouseEventFXDown 和 MouseEventFXUp 时 mouseData 的取值
+const (
+	XButton1 uint32 = 0x0001 // 按下或释放了第一个 X 按钮。
+	XButton2 uint32 = 0x0002 // 按下或释放了第二个 X 按钮。
+)
+
 type MouseInput struct {
 	Dx          int32
 	Dy          int32
@@ -78,6 +85,29 @@ type MouseInput struct {
 	DwExtraInfo uintptr
 }
 
+// KInput 是使用 ki 结构的 INPUT，末尾的填充使它与 MInput 大小相同
+type KInput struct {
+	Type uint32
+	KI   KeyboardInput
+	_    [8]byte
+}
+
+// 键盘
+const (
+	KeyEventFExtendedKey uint32 = 0x0001 // 扫描代码前面有一个前缀字节，其值为 0xE0 (224) 。
+	KeyEventFKeyUp       uint32 = 0x0002 // 释放了键。如果未指定，则按下该键。
+	KeyEventFUnicode     uint32 = 0x0004 // wScan 是 Unicode 字符，wVk 必须为 0，系统会合成 VK_PACKET 击键。
+	KeyEventFScancode    uint32 = 0x0008 // wScan 标识键，忽略 wVk。
+)
+
+type KeyboardInput struct {
+	WVk         uint16
+	WScan       uint16
+	DwFlags     uint32
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
 // 内存分配属性
 
 const (
@@ -0,0 +1,189 @@
+package winapi
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// https://learn.microsoft.com/zh-cn/windows/win32/api/mmeapi/
+var (
+	winmm                      = syscall.MustLoadDLL("winmm.dll")
+	procWaveOutGetNumDevs      = winmm.MustFindProc("waveOutGetNumDevs")
+	procWaveOutGetDevCaps      = winmm.MustFindProc("waveOutGetDevCapsW")
+	procWaveOutOpen            = winmm.MustFindProc("waveOutOpen")
+	procWaveOutPrepareHeader   = winmm.MustFindProc("waveOutPrepareHeader")
+	procWaveOutUnprepareHeader = winmm.MustFindProc("waveOutUnprepareHeader")
+	procWaveOutWrite           = winmm.MustFindProc("waveOutWrite")
+	procWaveOutReset           = winmm.MustFindProc("waveOutReset")
+	procWaveOutClose           = winmm.MustFindProc("waveOutClose")
+)
+
+type HWAVEOUT syscall.Handle
+
+// 波形音频相关常量
+const (
+	WaveMapper      = 0xFFFFFFFF // 系统根据格式选择的默认输出设备。
+	WaveFormatPCM   = 1          // PCM 格式。
+	CallbackNull    = 0          // 不使用回调，通过轮询 WAVEHDR 的 dwFlags 判断缓冲区是否播放完毕。
+	WHDRDone        = 0x00000001 // 设备驱动已经播放完缓冲区。
+	WHDRPrepared    = 0x00000002 // 缓冲区已经通过 waveOutPrepareHeader 准备好。
+	MMSysErrNoError = 0
+)
+
+// MMRESULT 是多媒体函数的返回值，0 表示成功
+type MMRESULT uint32
+
+func (r MMRESULT) Error() string {
+	return fmt.Sprintf("mmsystem error %d", uint32(r))
+}
+
+// WAVEFORMATEX 描述波形音频的格式，PCM 格式时 CbSize 为 0
+type WAVEFORMATEX struct {
+	WFormatTag      uint16
+	NChannels       uint16
+	NSamplesPerSec  uint32
+	NAvgBytesPerSec uint32
+	NBlockAlign     uint16
+	WBitsPerSample  uint16
+	CbSize          uint16
+}
+
+// WAVEHDR 是波形音频缓冲区的头，在设备播放完之前 LpData 指向的内存不能被释放
+type WAVEHDR struct {
+	LpData          *byte
+	DwBufferLength  uint32
+	DwBytesRecorded uint32
+	DwUser          uintptr
+	DwFlags         uint32
+	DwLoops         uint32
+	LpNext          uintptr
+	Reserved        uintptr
+}
+
+type WAVEOUTCAPS struct {
+	WMid           uint16
+	WPid           uint16
+	VDriverVersion uint32
+	SzPname        [32]uint16
+	DwFormats      uint32
+	WChannels      uint16
+	WReserved1     uint16
+	DwSupport      uint32
+}
+
+func mmError(r1 uintptr) error {
+	if r1 != MMSysErrNoError {
+		return MMRESULT(r1)
+	}
+	return nil
+}
+
+// WaveOutGetNumDevs 返回波形音频输出设备的数量
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/mmeapi/nf-mmeapi-waveoutgetnumdevs
+//
+//	UINT waveOutGetNumDevs();
+func WaveOutGetNumDevs() uint32 {
+	r1, _, _ := procWaveOutGetNumDevs.Call()
+	return uint32(r1)
+}
+
+// WaveOutGetDevCaps 获取波形音频输出设备的能力，包括设备名称
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/mmeapi/nf-mmeapi-waveoutgetdevcapsw
+//
+//	MMRESULT waveOutGetDevCapsW(
+//		UINT_PTR       uDeviceID,
+//		LPWAVEOUTCAPSW pwoc,
+//		UINT           cbwoc
+//	);
+func WaveOutGetDevCaps(deviceID uint32) (WAVEOUTCAPS, error) {
+	var caps WAVEOUTCAPS
+	r1, _, _ := procWaveOutGetDevCaps.Call(uintptr(deviceID), uintptr(unsafe.Pointer(&caps)), unsafe.Sizeof(caps))
+	return caps, mmError(r1)
+}
+
+// WaveOutOpen 打开波形音频输出设备
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/mmeapi/nf-mmeapi-waveoutopen
+//
+//	MMRESULT waveOutOpen(
+//		LPHWAVEOUT      phwo,
+//		UINT            uDeviceID,
+//		LPCWAVEFORMATEX pwfx,
+//		DWORD_PTR       dwCallback,
+//		DWORD_PTR       dwInstance,
+//		DWORD           fdwOpen
+//	);
+func WaveOutOpen(deviceID uint32, format *WAVEFORMATEX) (HWAVEOUT, error) {
+	var hwo HWAVEOUT
+	r1, _, _ := procWaveOutOpen.Call(uintptr(unsafe.Pointer(&hwo)), uintptr(deviceID), uintptr(unsafe.Pointer(format)), 0, 0, CallbackNull)
+	return hwo, mmError(r1)
+}
+
+// WaveOutPrepareHeader 准备用于播放的缓冲区
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/mmeapi/nf-mmeapi-waveoutprepareheader
+//
+//	MMRESULT waveOutPrepareHeader(
+//		HWAVEOUT  hwo,
+//		LPWAVEHDR pwh,
+//		UINT      cbwh
+//	);
+func WaveOutPrepareHeader(hwo HWAVEOUT, hdr *WAVEHDR) error {
+	r1, _, _ := procWaveOutPrepareHeader.Call(uintptr(hwo), uintptr(unsafe.Pointer(hdr)), unsafe.Sizeof(*hdr))
+	return mmError(r1)
+}
+
+// WaveOutUnprepareHeader 清理 WaveOutPrepareHeader 的准备工作，必须在设备播放完缓冲区后调用
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/mmeapi/nf-mmeapi-waveoutunprepareheader
+//
+//	MMRESULT waveOutUnprepareHeader(
+//		HWAVEOUT  hwo,
+//		LPWAVEHDR pwh,
+//		UINT      cbwh
+//	);
+func WaveOutUnprepareHeader(hwo HWAVEOUT, hdr *WAVEHDR) error {
+	r1, _, _ := procWaveOutUnprepareHeader.Call(uintptr(hwo), uintptr(unsafe.Pointer(hdr)), unsafe.Sizeof(*hdr))
+	return mmError(r1)
+}
+
+// WaveOutWrite 把缓冲区发送到输出设备，播放完成后设备会在 DwFlags 中设置 WHDRDone
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/mmeapi/nf-mmeapi-waveoutwrite
+//
+//	MMRESULT waveOutWrite(
+//		HWAVEOUT  hwo,
+//		LPWAVEHDR pwh,
+//		UINT      cbwh
+//	);
+func WaveOutWrite(hwo HWAVEOUT, hdr *WAVEHDR) error {
+	r1, _, _ := procWaveOutWrite.Call(uintptr(hwo), uintptr(unsafe.Pointer(hdr)), unsafe.Sizeof(*hdr))
+	return mmError(r1)
+}
+
+// WaveOutReset 停止播放，并把所有等待中的缓冲区标记为已完成
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/mmeapi/nf-mmeapi-waveoutreset
+//
+//	MMRESULT waveOutReset(
+//		HWAVEOUT hwo
+//	);
+func WaveOutReset(hwo HWAVEOUT) error {
+	r1, _, _ := procWaveOutReset.Call(uintptr(hwo))
+	return mmError(r1)
+}
+
+// WaveOutClose 关闭波形音频输出设备
+//
+// https://learn.microsoft.com/zh-cn/windows/win32/api/mmeapi/nf-mmeapi-waveoutclose
+//
+//	MMRESULT waveOutClose(
+//		HWAVEOUT hwo
+//	);
+func WaveOutClose(hwo HWAVEOUT) error {
+	r1, _, _ := procWaveOutClose.Call(uintptr(hwo))
+	return mmError(r1)
+}